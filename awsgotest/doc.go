@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+/*
+Package awsgotest records requests made to AWS and replays them in tests.
+
+A Recorder is an http.RoundTripper. In ModeRecord it sends requests on to AWS and saves
+each request/response pair to a fixture file. In ModeReplay it answers requests from that
+file without touching the network. Requests are matched on their method, path, operation
+(the X-Amz-Target header or the Action parameter) and normalized body. Signatures, dates,
+security tokens and chunk signatures are ignored, so fixtures replay with any credentials
+at any time.
+
+Recording
+
+    rec, err := awsgotest.NewRecorder("testdata/putItem.json", awsgotest.ModeRecord)
+    // test err ...
+    defer rec.Save()
+    awsgo.SetDefaultHttpClient(rec.Client())
+
+Secret keys and session tokens in response bodies, Eg. from sts or the container credentials
+endpoint, are replaced with REDACTED before they are saved.
+
+Replaying
+
+The helper New records when AWSGO_RECORD=1 is set, and replays otherwise. Fixtures are
+kept in testdata/<name>.json.
+
+    func Test_PutItem(t *testing.T) {
+        rec := awsgotest.New(t, "putItem")
+        client := dynamo.NewClient(rec.Config("us-west-2"))
+        req := client.NewPutItemRequest()
+        // ...
+    }
+
+The http client can also be set on a single request with RequestBuilder.HttpClient.
+*/
+package awsgotest
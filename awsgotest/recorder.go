@@ -0,0 +1,394 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgotest
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+    "unicode/utf8"
+    "github.com/fromkeith/awsgo"
+)
+
+// Whether a Recorder talks to AWS, or answers from its fixture file.
+type Mode int
+
+const (
+    ModeReplay  Mode = iota
+    ModeRecord
+)
+
+// Set to 1 to have New record fixtures instead of replaying them.
+const RecordEnv = "AWSGO_RECORD"
+
+var (
+    Recorder_Error_NoMatch = errors.New("No recorded interaction matches the request")
+)
+
+// Query and form parameters that change every time a request is signed.
+var signingParams = map[string]bool{
+    "AWSAccessKeyId":       true,
+    "Expires":              true,
+    "SecurityToken":        true,
+    "Signature":            true,
+    "SignatureMethod":      true,
+    "SignatureVersion":     true,
+    "Timestamp":            true,
+    "X-Amz-Algorithm":      true,
+    "X-Amz-Credential":     true,
+    "X-Amz-Date":           true,
+    "X-Amz-Expires":        true,
+    "X-Amz-Security-Token": true,
+    "X-Amz-Signature":      true,
+    "X-Amz-SignedHeaders":  true,
+}
+
+// The parts of a request that are matched during replay.
+type RecordedRequest struct {
+    Method      string          `json:"method"`
+    Path        string          `json:"path"`
+    // The query string without any signing parameters
+    Query       string          `json:"query,omitempty"`
+    // The X-Amz-Target header, or the Action parameter
+    Operation   string          `json:"operation,omitempty"`
+    // The body with JSON keys sorted, form parameters sorted and signing parameters removed
+    Body        string          `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+    StatusCode  int             `json:"statusCode"`
+    Header      http.Header     `json:"header,omitempty"`
+    Body        string          `json:"body,omitempty"`
+    // Set instead of Body when the body isn't valid utf-8
+    BodyBase64  string          `json:"bodyBase64,omitempty"`
+}
+
+type Interaction struct {
+    Request     RecordedRequest     `json:"request"`
+    Response    RecordedResponse    `json:"response"`
+}
+
+type fixture struct {
+    Interactions    []Interaction   `json:"interactions"`
+}
+
+// An http.RoundTripper that records interactions with AWS to a fixture file, or replays them.
+type Recorder struct {
+    // The fixture file
+    Path            string
+    Mode            Mode
+    // Where requests are sent when recording. A new awsgo.NewHttpTransport is used if nil
+    Transport       http.RoundTripper
+
+    lock            sync.Mutex
+    interactions    []Interaction
+    used            []bool
+}
+
+// Creates a Recorder for the fixture at path. When replaying the fixture must already exist.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+    r := &Recorder{Path: path, Mode: mode}
+    if mode == ModeRecord {
+        return r, nil
+    }
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var f fixture
+    if err := json.Unmarshal(data, &f); err != nil {
+        return nil, fmt.Errorf("Failed to parse fixture %s: %w", path, err)
+    }
+    r.interactions = f.Interactions
+    r.used = make([]bool, len(f.Interactions))
+    return r, nil
+}
+
+// Creates a Recorder for testdata/<name>.json. It records when AWSGO_RECORD=1 is set and
+// replays otherwise. Recorded fixtures are saved when the test finishes.
+func New(t testing.TB, name string) *Recorder {
+    t.Helper()
+    mode := ModeReplay
+    if os.Getenv(RecordEnv) == "1" {
+        mode = ModeRecord
+    }
+    r, err := NewRecorder(filepath.Join("testdata", name + ".json"), mode)
+    if err != nil {
+        t.Fatalf("Failed to load fixture %s: %v", name, err)
+    }
+    t.Cleanup(func () {
+        if err := r.Save(); err != nil {
+            t.Errorf("Failed to save fixture %s: %v", name, err)
+        }
+    })
+    return r
+}
+
+// An http client that sends everything through the Recorder.
+func (r *Recorder) Client() *http.Client {
+    return &http.Client{Transport: r}
+}
+
+// A Config that uses the Recorder's client. When replaying, fake credentials are used so
+// no credentials need to be configured.
+func (r *Recorder) Config(region string) awsgo.Config {
+    cfg := awsgo.Config{
+        Region:     region,
+        HttpClient: r.Client(),
+    }
+    if r.Mode == ModeReplay {
+        cfg.CredentialsProvider = awsgo.StaticCredentialsProvider{
+            Key: awsgo.NewCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "", time.Time{}),
+        }
+    }
+    return cfg
+}
+
+// Writes the recorded interactions to Path. Does nothing when replaying.
+func (r *Recorder) Save() error {
+    if r.Mode != ModeRecord {
+        return nil
+    }
+    r.lock.Lock()
+    defer r.lock.Unlock()
+    interactions := r.interactions
+    if interactions == nil {
+        interactions = []Interaction{}
+    }
+    data, err := json.MarshalIndent(fixture{Interactions: interactions}, "", "    ")
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+        return err
+    }
+    return ioutil.WriteFile(r.Path, append(data, '\n'), 0644)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+    var body []byte
+    if req.Body != nil {
+        var err error
+        body, err = ioutil.ReadAll(req.Body)
+        req.Body.Close()
+        if err != nil {
+            return nil, err
+        }
+    }
+    recorded := recordRequest(req, body)
+    if r.Mode == ModeRecord {
+        return r.record(req, body, recorded)
+    }
+    return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, recorded RecordedRequest) (*http.Response, error) {
+    transport := r.Transport
+    if transport == nil {
+        r.lock.Lock()
+        if r.Transport == nil {
+            r.Transport = awsgo.NewHttpTransport()
+        }
+        transport = r.Transport
+        r.lock.Unlock()
+    }
+    out := req.Clone(req.Context())
+    out.Body = ioutil.NopCloser(bytes.NewReader(body))
+    resp, err := transport.RoundTrip(out)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    respBody, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+    header := resp.Header.Clone()
+    header.Del("Date")
+    recordedResp := RecordedResponse{StatusCode: resp.StatusCode, Header: header}
+    if utf8.Valid(respBody) {
+        // fixtures get committed, so keep credentials from sts and the like out of them
+        recordedResp.Body = awsgo.RedactBody(respBody)
+    } else {
+        recordedResp.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+    }
+
+    r.lock.Lock()
+    r.interactions = append(r.interactions, Interaction{Request: recorded, Response: recordedResp})
+    r.used = append(r.used, true)
+    r.lock.Unlock()
+
+    resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+    return resp, nil
+}
+
+// Answers with the first unused interaction that matches, so repeated requests replay in
+// the order they were recorded.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+    r.lock.Lock()
+    defer r.lock.Unlock()
+    for i, interaction := range r.interactions {
+        if r.used[i] || interaction.Request != recorded {
+            continue
+        }
+        r.used[i] = true
+        return interaction.Response.httpResponse(req)
+    }
+    return nil, fmt.Errorf("%w: %s %s %s", Recorder_Error_NoMatch, recorded.Method, recorded.Path, recorded.Operation)
+}
+
+func (r RecordedResponse) httpResponse(req *http.Request) (*http.Response, error) {
+    body := []byte(r.Body)
+    if r.BodyBase64 != "" {
+        var err error
+        body, err = base64.StdEncoding.DecodeString(r.BodyBase64)
+        if err != nil {
+            return nil, err
+        }
+    }
+    header := r.Header.Clone()
+    if header == nil {
+        header = http.Header{}
+    }
+    return &http.Response{
+        Status:         fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+        StatusCode:     r.StatusCode,
+        Proto:          "HTTP/1.1",
+        ProtoMajor:     1,
+        ProtoMinor:     1,
+        Header:         header,
+        Body:           ioutil.NopCloser(bytes.NewReader(body)),
+        ContentLength:  int64(len(body)),
+        Request:        req,
+    }, nil
+}
+
+func recordRequest(req *http.Request, body []byte) RecordedRequest {
+    query := req.URL.Query()
+    recorded := RecordedRequest{
+        Method:     req.Method,
+        Path:       req.URL.EscapedPath(),
+        Query:      withoutSigningParams(query).Encode(),
+        Operation:  req.Header.Get("X-Amz-Target"),
+    }
+    if recorded.Operation == "" {
+        recorded.Operation = query.Get("Action")
+    }
+    if strings.Contains(req.Header.Get("Content-Encoding"), "aws-chunked") {
+        if decoded, err := decodeChunked(body); err == nil {
+            body = decoded
+        }
+    }
+    if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+        if form, err := url.ParseQuery(string(body)); err == nil {
+            if recorded.Operation == "" {
+                recorded.Operation = form.Get("Action")
+            }
+            recorded.Body = withoutSigningParams(form).Encode()
+            return recorded
+        }
+    }
+    recorded.Body = normalizeBody(body)
+    return recorded
+}
+
+func withoutSigningParams(values url.Values) url.Values {
+    for k := range values {
+        if signingParams[k] {
+            delete(values, k)
+        }
+    }
+    return values
+}
+
+// Re-encodes JSON so key order and whitespace don't matter. Other bodies are kept as is,
+// or hashed if they aren't valid utf-8.
+func normalizeBody(body []byte) string {
+    trimmed := bytes.TrimSpace(body)
+    if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+        decoder := json.NewDecoder(bytes.NewReader(trimmed))
+        decoder.UseNumber()
+        var v interface{}
+        if err := decoder.Decode(&v); err == nil {
+            if normalized, err := json.Marshal(v); err == nil {
+                return string(normalized)
+            }
+        }
+    }
+    if !utf8.Valid(body) {
+        sum := sha256.Sum256(body)
+        return "sha256:" + hex.EncodeToString(sum[:])
+    }
+    return string(body)
+}
+
+// Strips the chunk headers, and so the chunk signatures, from an aws-chunked body.
+func decodeChunked(body []byte) ([]byte, error) {
+    var out bytes.Buffer
+    for {
+        end := bytes.Index(body, []byte("\r\n"))
+        if end < 0 {
+            return nil, io.ErrUnexpectedEOF
+        }
+        sizeHex := string(body[:end])
+        if i := strings.IndexByte(sizeHex, ';'); i >= 0 {
+            sizeHex = sizeHex[:i]
+        }
+        size, err := strconv.ParseInt(sizeHex, 16, 64)
+        if err != nil {
+            return nil, err
+        }
+        body = body[end + 2:]
+        if size == 0 {
+            return out.Bytes(), nil
+        }
+        if int64(len(body)) < size + 2 {
+            return nil, io.ErrUnexpectedEOF
+        }
+        out.Write(body[:size])
+        body = body[size + 2:]
+    }
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgotest
+
+import (
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+    "github.com/fromkeith/awsgo"
+    "github.com/fromkeith/awsgo/dynamo"
+    "github.com/fromkeith/awsgo/sts"
+)
+
+func describeTable(cfg awsgo.Config, table string) (*dynamo.DescribeTableResponse, error) {
+    req := dynamo.NewClient(cfg).NewDescribeTableRequest()
+    req.TableName = table
+    return req.Request()
+}
+
+func Test_RecordThenReplay(t *testing.T) {
+    calls := 0
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        calls ++
+        w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+        fmt.Fprintf(w, `{"Table":{"TableName":"demo","ItemCount":%d}}`, calls)
+    }))
+    defer ts.Close()
+
+    path := filepath.Join(t.TempDir(), "fixture.json")
+    rec, err := NewRecorder(path, ModeRecord)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cfg := rec.Config("us-west-2")
+    cfg.CredentialsProvider = awsgo.StaticCredentialsProvider{Key: awsgo.NewCredentials("akey", "skey", "", time.Time{})}
+    cfg.EndpointResolver = awsgo.EndpointResolverFunc(func (host awsgo.AwsHost) (awsgo.Endpoint, error) {
+        return awsgo.Endpoint{URL: ts.URL}, nil
+    })
+    for i := 0; i < 2; i ++ {
+        if _, err := describeTable(cfg, "demo"); err != nil {
+            t.Fatalf("Record failed: %v", err)
+        }
+    }
+    if err := rec.Save(); err != nil {
+        t.Fatal(err)
+    }
+    ts.Close()
+
+    // different credentials, a different host and a later date should all still match
+    replay, err := NewRecorder(path, ModeReplay)
+    if err != nil {
+        t.Fatal(err)
+    }
+    replayCfg := replay.Config("us-west-2")
+    replayCfg.RetryPolicy = awsgo.NoRetryPolicy{}
+    awsgo.SetClockSkew(time.Hour)
+    defer awsgo.SetClockSkew(0)
+    for i := 1; i <= 2; i ++ {
+        resp, err := describeTable(replayCfg, "demo")
+        if err != nil {
+            t.Fatalf("Replay failed: %v", err)
+        }
+        if resp.Table.ItemCount != float64(i) {
+            t.Errorf("Expected responses in recorded order. Got ItemCount %v for request %d", resp.Table.ItemCount, i)
+        }
+    }
+    if calls != 2 {
+        t.Errorf("Replay should not reach the server. Got %d calls", calls)
+    }
+
+    // each interaction is only replayed once
+    if _, err := describeTable(replayCfg, "demo"); !errors.Is(err, Recorder_Error_NoMatch) {
+        t.Errorf("Expected Recorder_Error_NoMatch once the fixture is used up. Got: %v", err)
+    }
+}
+
+func Test_RecordRedactsCredentials(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>liveSecretKey</SecretAccessKey>
+      <SessionToken>liveSessionToken</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`)
+    }))
+    defer ts.Close()
+
+    path := filepath.Join(t.TempDir(), "fixture.json")
+    rec, err := NewRecorder(path, ModeRecord)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cfg := rec.Config("us-west-2")
+    cfg.CredentialsProvider = awsgo.StaticCredentialsProvider{Key: awsgo.NewCredentials("akey", "skey", "", time.Time{})}
+    cfg.EndpointResolver = awsgo.EndpointResolverFunc(func (host awsgo.AwsHost) (awsgo.Endpoint, error) {
+        return awsgo.Endpoint{URL: ts.URL}, nil
+    })
+    req := sts.NewClient(cfg).NewAssumeRoleRequest()
+    req.RoleArn = "arn:aws:iam::123456789012:role/demo"
+    req.RoleSessionName = "session"
+    resp, err := req.Request()
+    if err != nil {
+        t.Fatalf("Record failed: %v", err)
+    }
+    // the caller still gets the real credentials while recording
+    if resp.AssumeRoleResult.Credentials.SecretAccessKey != "liveSecretKey" {
+        t.Errorf("Expected the live secret. Got %s", resp.AssumeRoleResult.Credentials.SecretAccessKey)
+    }
+    if err := rec.Save(); err != nil {
+        t.Fatal(err)
+    }
+    saved, err := ioutil.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if strings.Contains(string(saved), "liveSecretKey") || strings.Contains(string(saved), "liveSessionToken") {
+        t.Fatalf("The fixture has live credentials in it: %s", saved)
+    }
+    if !strings.Contains(string(saved), "ASIAEXAMPLE") {
+        t.Errorf("Only the secrets should be redacted: %s", saved)
+    }
+}
+
+func Test_ReplayFixture(t *testing.T) {
+    rec := New(t, "describeTable")
+    if rec.Mode != ModeReplay {
+        t.Skip("recording")
+    }
+    cfg := rec.Config("us-west-2")
+    cfg.RetryPolicy = awsgo.NoRetryPolicy{}
+    resp, err := describeTable(cfg, "demo")
+    if err != nil {
+        t.Fatalf("Replay failed: %v", err)
+    }
+    if resp.Table.TableStatus != "ACTIVE" || resp.Table.ItemCount != 3 {
+        t.Errorf("Unexpected table: %+v", resp.Table)
+    }
+    if _, err := describeTable(cfg, "other"); !errors.Is(err, Recorder_Error_NoMatch) {
+        t.Errorf("A different body should not match. Got: %v", err)
+    }
+}
+
+func Test_RecordRequestIgnoresSigning(t *testing.T) {
+    form := "Version=2012-11-05&Action=SendMessage&Timestamp=%s&Signature=%s&AWSAccessKeyId=%s&MessageBody=hi"
+    first, _ := http.NewRequest("POST", "https://sqs.us-west-2.amazonaws.com/123/q?X-Amz-Date=20150830T123600Z",
+        strings.NewReader(fmt.Sprintf(form, "2015-08-30T12%3A36%3A00Z", "abc", "akey")))
+    first.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    second, _ := http.NewRequest("POST", "https://sqs.us-west-2.amazonaws.com/123/q?X-Amz-Date=20260101T000000Z",
+        strings.NewReader(fmt.Sprintf(form, "2026-01-01T00%3A00%3A00Z", "def", "other")))
+    second.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+    a := recordRequest(first, readBody(first))
+    b := recordRequest(second, readBody(second))
+    if a != b {
+        t.Errorf("Expected signing parameters to be ignored.\n%+v\n%+v", a, b)
+    }
+    if a.Operation != "SendMessage" {
+        t.Errorf("Expected the Action to be the operation. Got: %s", a.Operation)
+    }
+    if v, _ := url.ParseQuery(a.Body); v.Get("MessageBody") != "hi" || v.Get("Signature") != "" {
+        t.Errorf("Unexpected body: %s", a.Body)
+    }
+}
+
+func Test_RecordRequestDecodesChunks(t *testing.T) {
+    chunked := "5;chunk-signature=aaaa\r\nhello\r\n6;chunk-signature=bbbb\r\n world\r\n0;chunk-signature=cccc\r\n\r\n"
+    req, _ := http.NewRequest("PUT", "https://bucket.s3.amazonaws.com/key", strings.NewReader(chunked))
+    req.Header.Set("Content-Encoding", "aws-chunked")
+    recorded := recordRequest(req, readBody(req))
+    if recorded.Body != "hello world" {
+        t.Errorf("Expected the chunk headers to be stripped. Got: %q", recorded.Body)
+    }
+}
+
+func readBody(req *http.Request) []byte {
+    body, _ := ioutil.ReadAll(req.Body)
+    return body
+}
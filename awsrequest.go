@@ -32,6 +32,7 @@ package awsgo
 
 import (
     "bytes"
+    "context"
     "crypto/hmac"
     "crypto/md5"
     "crypto/sha256"
@@ -44,13 +45,12 @@ import (
     "github.com/pmylund/sortutil"
     "io"
     "io/ioutil"
+    "log/slog"
     "net"
     "net/http"
     "net/url"
-    "strconv"
     "strings"
     "sync"
-    "syscall"
     "time"
 )
 
@@ -59,11 +59,14 @@ const (
     RequestSigningType_REST = 2
     RequestSigningType_AWS2 = 3
     RequestSigningType_AWS3 = 4
+    // SigV4, with the payload streamed as signed chunks. S3 only.
+    RequestSigningType_AWS4_CHUNKED = 5
 )
 
 
 var (
-    defaultRequestClient       *http.Client = http.DefaultClient
+    httpClientLock      sync.RWMutex
+    defaultRequestClient       *http.Client = &http.Client{Transport: NewHttpTransport()}
     firstRequestCreate  sync.Mutex
 )
 
@@ -77,6 +80,10 @@ func (r RequestError) Error() string {
     return fmt.Sprintf("%s: %s: %v", r.Location, r.Action, r.BaseError)
 }
 
+func (r RequestError) Unwrap() error {
+    return r.BaseError
+}
+
 
 
 // Base of a request. Used across all requests.
@@ -85,6 +92,8 @@ type RequestBuilder struct {
     Host AwsHost                    `json:"-"`
     // The Credentials to use
     Key  Credentials                `json:"-"`
+    // Where to get credentials from when Key is not set. GetSecurityKeys is used if neither are set
+    CredentialsProvider CredentialsProvider `json:"-"`
     // Any custom headers
     Headers map[string]string       `json:"-"`
     // The method we are using GET, PUT, POST, ...
@@ -93,6 +102,20 @@ type RequestBuilder struct {
     CanonicalUri string             `json:"-"`
     // The http client to use. A default one will be used if not specified
     HttpClient      *http.Client         `json:"-"`
+    // Decides when a failed request is retried. DefaultRetryPolicy is used if not specified
+    RetryPolicy     RetryPolicy          `json:"-"`
+    // Decides where the request is sent. Host.Override, SetServiceEndpoint and the default EndpointResolver are used if not specified
+    EndpointResolver EndpointResolver    `json:"-"`
+    // Where the request is logged. Nothing is logged if nil
+    Logger          *slog.Logger         `json:"-"`
+    // The stages the request goes through. The default Handlers are used if not specified
+    Handlers        *Handlers            `json:"-"`
+    // Receives metrics about the request. The default MetricsHook is used if not specified
+    MetricsHook     MetricsHook          `json:"-"`
+    // Starts a span for the request. The default Tracer is used if not specified
+    Tracer          Tracer               `json:"-"`
+    // set when Key was filled in from a provider
+    keyProvider     CredentialsProvider
 
 }
 
@@ -113,16 +136,33 @@ type AwsRequest struct {
     Date time.Time
     Headers map[string]string
     Payload string
+    // Sent as is, once. Requests with one can't be retried.
     PayloadReader io.ReadCloser
+    // Gives a fresh body for each attempt, so the request can be retried.
+    PayloadFactory BodyFactory
+    // Size of the chunks for RequestSigningType_AWS4_CHUNKED. Defaults to DefaultChunkSize.
+    ChunkSize int
     Key Credentials
     RequestMethod string
     CanonicalUri string
     RequestSigningType int
     HttpClient  *http.Client
+    RetryPolicy RetryPolicy
+    EndpointResolver EndpointResolver
+    Handlers *Handlers
+    Logger *slog.Logger
+    MetricsHook MetricsHook
+    Tracer Tracer
+    // where Key came from, if it was not given to us directly
+    keyProvider CredentialsProvider
     // generated
     signature string
     scope string
-    payloadHash []byte
+    // closed once the request is done, if the payload was a seekable io.ReadCloser
+    payloadCloser io.Closer
+    // set when the payload is sent as signed chunks
+    chunkSigner *chunkSigner
+    endpoint Endpoint
 }
 
 func (r *RequestBuilder) GetRequestBuilder() *RequestBuilder {
@@ -134,13 +174,18 @@ func verifyInput(r *RequestBuilder) (error) {
     if len(r.Host.Domain) == 0 {
         r.Host.Domain = "amazonaws.com"
     }
-    // if we haven't set the keys, try getting the default ones...
+    // if we haven't set the keys, try the provider, then the default ones...
     if r.Key.AccessKeyId == "" {
+        provider := r.CredentialsProvider
+        if provider == nil {
+            provider = getDefaultCredentialsCache()
+        }
         var err error
-        r.Key, err = GetSecurityKeys()
+        r.Key, err = provider.Retrieve()
         if err != nil {
             return err
         }
+        r.keyProvider = provider
     }
     if len(r.Key.AccessKeyId) == 0 {
         return Verification_Error_AccessKeyEmpty
@@ -160,9 +205,35 @@ func createAwsRequest(rb *RequestBuilder, marsh interface{}) (request AwsRequest
     request.RequestMethod = rb.RequestMethod
     request.CanonicalUri = rb.CanonicalUri
     request.HttpClient = rb.HttpClient
-    request.Date = time.Now()
-    if r, ok := marsh.(io.ReadCloser); ok {
-        request.PayloadReader = r
+    request.RetryPolicy = rb.RetryPolicy
+    request.EndpointResolver = rb.EndpointResolver
+    request.Handlers = rb.Handlers
+    request.Logger = rb.Logger
+    request.MetricsHook = rb.MetricsHook
+    request.Tracer = rb.Tracer
+    request.keyProvider = rb.keyProvider
+    request.Date = skewedNow()
+    if f, ok := marsh.(BodyFactory); ok {
+        request.PayloadFactory = f
+    } else if f, ok := marsh.(func() (io.ReadCloser, error)); ok {
+        request.PayloadFactory = f
+    } else if r, ok := marsh.(io.ReadSeeker); ok {
+        factory, length, err := seekerBody(r)
+        if err != nil {
+            // can't rewind it after all, so we only get the one go
+            request.PayloadReader = readCloser(r)
+            return
+        }
+        request.PayloadFactory = factory
+        request.payloadCloser, _ = r.(io.Closer)
+        if request.Headers == nil {
+            request.Headers = make(map[string]string)
+        }
+        if _, ok := request.Headers["Content-Length"]; !ok {
+            request.Headers["Content-Length"] = fmt.Sprintf("%d", length)
+        }
+    } else if r, ok := marsh.(io.Reader); ok {
+        request.PayloadReader = readCloser(r)
     } else if marsh != nil {
         pay, _ := json.Marshal(marsh)
         request.Payload = string(pay)
@@ -198,35 +269,17 @@ func NewAwsRequest(rb RequestBuilderInterface, marsh interface{}) (request AwsRe
 // Perform the actual request, calling the demarshall on the RequestBuilderInterface
 // returns the result of the Demarshall, or other errors.
 func (request AwsRequest) DoAndDemarshall(rb RequestBuilderInterface) (interface{}, error) {
-    responseIo, responseHeaders, statusCode, err := request.Do()
-    if err != nil {
-        return nil, err
-    }
-    defer responseIo.Close()
-
-    var responseContent []byte
-    buf := bytes.Buffer{}
-    if _, err = io.Copy(&buf, responseIo); err != nil {
-        // i think i'm seeing some connection reset by peer errors here, but i'm not 100% sure
-        // best i could find was the suggestion that the body was closed by the server before
-        // we could read it, and that this might happen on bad request.
-        if statusCode >= 200 && statusCode < 300 {
-            return nil, RequestError{
-                BaseError: err,
-                Location: "Aws.Request.DoAndDemarshall",
-                Action: "io.Copy",
-            }
-        }
-    }
-    responseContent = []byte(buf.String())
-
-    //fmt.Println("responseContent")
+    return request.DoAndDemarshallWithContext(context.Background(), rb)
+}
 
-    val := rb.DeMarshalResponse(responseContent, responseHeaders, statusCode)
-    if t, ok := val.(error); ok {
-        return nil, t
+// Same as DoAndDemarshall, but the request is aborted once ctx is done.
+// In that case ctx.Err() is returned.
+func (request AwsRequest) DoAndDemarshallWithContext(ctx context.Context, rb RequestBuilderInterface) (interface{}, error) {
+    op := request.run(ctx, request.handlers(), rb)
+    if op.Error != nil {
+        return nil, op.Error
     }
-    return val, nil
+    return op.Result, nil
 }
 
 // Performs the actual request
@@ -236,117 +289,87 @@ func (request AwsRequest) DoAndDemarshall(rb RequestBuilderInterface) (interface
 //      int - that status code the response
 //      error - any errors that occured
 func (req AwsRequest) Do() (io.ReadCloser, map[string]string, int, error) {
+    return req.DoWithContext(context.Background())
+}
 
-    // add the required headers
-    req.Headers["Host"] = strings.ToLower(req.Host.ToString())
-    req.Headers["user-agent"] = "go-aws-client-0.1"
-    if _, ok := req.Headers["x-amz-date"]; !ok {
-        req.Headers["x-amz-date"] = IsoDate(req.Date)
-    }
-    if req.Key.token != "" {
-        req.Headers["x-amz-security-token"] = req.Key.token
-    }
-
-    if err := signRequest(&req); err != nil {
-        return nil, nil, 0, err
-    }
-
-    // create headers for the actual request
-    reqHeaders := http.Header{}
-    for k, v := range req.Headers {
-        reqHeaders.Add(k, v)
-    }
-
-    url_, err := getUrl(req)
-    if err != nil {
-        return nil, nil, 0, err
-    }
-
-    //fmt.Println("Request url: ", url_.String())
-
-    // the base request
-    hreq := http.Request {
-        URL: url_,
-        Method: req.RequestMethod,
-        ProtoMajor: 1,
-        ProtoMinor: 1,
-        Close: true, // until this is fixed (https://code.google.com/p/go/issues/detail?id=4677) we want to Close.
-        Header: reqHeaders,
+// Performs the actual request, aborting the http call and any retry
+// backoff once ctx is done. When that happens ctx.Err() is returned.
+func (req AwsRequest) DoWithContext(ctx context.Context) (io.ReadCloser, map[string]string, int, error) {
+    op := req.run(ctx, req.handlers(), nil)
+    if op.Error != nil {
+        if op.HttpResponse != nil {
+            op.HttpResponse.Body.Close()
+        }
+        return nil, nil, 0, op.Error
     }
+    return op.HttpResponse.Body, responseHeaders(op.HttpResponse), op.HttpResponse.StatusCode, nil
+}
 
-    httpClient := req.HttpClient
-    if httpClient == nil {
-        httpClient = defaultRequestClient
+func (req AwsRequest) handlers() Handlers {
+    if req.Handlers != nil {
+        return *req.Handlers
     }
+    return getDefaultHandlers()
+}
 
-    if val, ok := req.Headers["Content-Length"]; ok {
-        hreq.ContentLength, _ = strconv.ParseInt(val, 10, 64)
-    }
-    // we can only retry if we can reset Body..
-    canRetry := true
-    if req.PayloadReader != nil {
-        // can't retry here, as we can't reset req.PayloadReader
-        hreq.Body = req.PayloadReader
-        canRetry = false
+// Sleeps for d, returning early with ctx.Err() if ctx is done first.
+func SleepWithContext(ctx context.Context, d time.Duration) error {
+    if d <= 0 {
+        return ctx.Err()
     }
-    var resp *http.Response
-    for try := 0; ; try ++ {
-        // for payloads we know are static, we can reset them for each try.
-        if req.Payload != "" {
-            hreq.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(req.Payload)))
-        }
-        resp, err = httpClient.Do(&hreq)
-        if err != nil {
-            if try > 5 {
-                return nil, nil, 0, RequestError{
-                    BaseError: err,
-                    Location: "Aws.Request.Do (Exceeded tries)",
-                    Action: "httpClient.Do",
-                }
-            }
-            // if a temporary error, and we can retry, then do.
-            if netErr, ok := err.(net.Error); ok && canRetry {
-                if netErr.Temporary() {
-                    time.Sleep(time.Duration(100 * try * try) * time.Millisecond)
-                    continue
-                }
-            }
-            // try mimic the fix in https://code.google.com/p/go/issues/detail?id=6163
-            if sysError, ok := err.(syscall.Errno); ok && canRetry {
-                if sysError == syscall.ECONNRESET || sysError == syscall.ECONNABORTED {
-                    time.Sleep(time.Duration(100 * try * try) * time.Millisecond)
-                    continue
-                }
-            }
-            return nil, nil, 0, RequestError{
-                BaseError: err,
-                Location: "Aws.Request.Do (Non net, non temp)",
-                Action: "httpClient.Do",
-            }
-        }
-        if try > 5 || !canRetry {
-            break
-        }
-        // retry on 500s, if possible
-        if resp.StatusCode == 500 {
-            resp.Body.Close()
-            time.Sleep(time.Duration(100 * try * try) * time.Millisecond)
-            continue
-        }
-        break
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <- ctx.Done():
+        return ctx.Err()
+    case <- t.C:
+        return nil
     }
+}
 
-    responseHeaders := make(map[string]string)
-    for k, v := range resp.Header {
-        responseHeaders[strings.ToLower(k)] = strings.Join(v, ";")
-    }
-    return resp.Body, responseHeaders, resp.StatusCode, nil
+// Reads the body of an error response to find the service error code.
+// The body is replaced so it can still be read by the caller.
+func peekErrorCode(resp *http.Response) string {
+    buf := bytes.Buffer{}
+    io.Copy(&buf, resp.Body)
+    resp.Body.Close()
+    resp.Body = ioutil.NopCloser(bytes.NewReader(buf.Bytes()))
+    return ExtractErrorCode(buf.Bytes())
 }
 
+// Sets the client used by requests that don't specify their own HttpClient.
 func SetDefaultHttpClient(client *http.Client) {
+    httpClientLock.Lock()
+    defer httpClientLock.Unlock()
     defaultRequestClient = client
 }
 
+// The client used by requests that don't specify their own HttpClient.
+func DefaultHttpClient() *http.Client {
+    httpClientLock.RLock()
+    defer httpClientLock.RUnlock()
+    return defaultRequestClient
+}
+
+// A Transport tuned for talking to AWS: connections are kept alive and pooled, with enough
+// idle connections per host that a busy client (eg. to DynamoDB) doesn't redo the TCP and TLS
+// handshakes on every call. HTTP/2 is used where the service supports it.
+func NewHttpTransport() *http.Transport {
+    return &http.Transport{
+        Proxy: http.ProxyFromEnvironment,
+        DialContext: (&net.Dialer{
+            Timeout: 10 * time.Second,
+            KeepAlive: 30 * time.Second,
+        }).DialContext,
+        ForceAttemptHTTP2: true,
+        MaxIdleConns: 200,
+        MaxIdleConnsPerHost: 100,
+        IdleConnTimeout: 90 * time.Second,
+        TLSHandshakeTimeout: 10 * time.Second,
+        ExpectContinueTimeout: 1 * time.Second,
+    }
+}
+
 // helper function to create a http client that accepts certain certs
 func CreateCertApprovedClient(certsToAdd []*x509.Certificate) (*http.Client) {
     // add in any custom certs they want us to use
@@ -357,38 +380,30 @@ func CreateCertApprovedClient(certsToAdd []*x509.Certificate) (*http.Client) {
             rootCA.AddCert(certsToAdd[i])
         }
     }
-    tr := &http.Transport{
-        TLSClientConfig: &tls.Config{
-            RootCAs : rootCA,
-        },
-        DisableKeepAlives: true,
+    tr := NewHttpTransport()
+    tr.TLSClientConfig = &tls.Config{
+        RootCAs : rootCA,
     }
     requestClient := &http.Client{Transport: tr}
     return requestClient
 }
 
+// The resolved endpoint, plus the path and query we are hitting.
 func getUrl(req AwsRequest) (*url.URL, error) {
-    // create the url string. Not all aws request have regions.
-    var urlStr string
-    if req.Host.Override != "" {
-        urlStr = fmt.Sprintf("https://%s%s", req.Host.Override, req.CanonicalUri)
-    } else if req.Host.Region == "" {
-        urlStr = fmt.Sprintf("https://%s.%s%s", req.Host.Service, req.Host.Domain, req.CanonicalUri)
-    } else {
-        urlStr = fmt.Sprintf("https://%s.%s.%s%s", req.Host.Service, req.Host.Region, req.Host.Domain, req.CanonicalUri)
-    }
-    return url.Parse(urlStr)
+    return url.Parse(req.endpoint.URL + req.CanonicalUri)
 }
 
 func signRequest(req *AwsRequest) error {
     if req.RequestSigningType == RequestSigningType_AWS4 {
-        req.createSignature()
+        return req.createSignature()
     } else if req.RequestSigningType == RequestSigningType_REST {
         req.createRestSignature()
     } else if req.RequestSigningType == RequestSigningType_AWS2 {
         req.createV2Signature()
     } else if req.RequestSigningType == RequestSigningType_AWS3 {
         req.createSignatureAws3()
+    } else if req.RequestSigningType == RequestSigningType_AWS4_CHUNKED {
+        return req.createChunkedSignature()
     } else {
         return errors.New("Invalid request signing type")
     }
@@ -456,7 +471,7 @@ func  (req * AwsRequest) createV2Signature() {
     } else {
         req.CanonicalUri = req.CanonicalUri + "&"
     }
-    now := time.Now()
+    now := req.Date
 
     req.CanonicalUri = fmt.Sprintf("%sAWSAccessKeyId=%s&SignatureMethod=HmacSHA256&SignatureVersion=2&Timestamp=%s",
         req.CanonicalUri,
@@ -488,7 +503,7 @@ func  (req * AwsRequest) createV2Signature() {
 
     toSign := fmt.Sprintf("%s\n%s\n%s\n%s",
         req.RequestMethod,
-        req.Host.ToString(),
+        req.Headers["Host"],
         fixedUrl,
         canonicalQueryString)
 
@@ -503,81 +518,6 @@ func  (req * AwsRequest) createV2Signature() {
 }
 
 
-// http://docs.aws.amazon.com/general/latest/gr/sigv4-create-canonical-request.html
-func (req * AwsRequest) createSignature() {
-    hasher := sha256.New()
-    hasher.Write([]byte(req.Payload)) // TODO: check return code?
-    req.payloadHash = hasher.Sum(nil)
-
-    // Stupid Amazon. Why have the service name and the url not match???
-    fixedService := req.Host.Service
-    if req.Host.Service == "email" {
-        fixedService = "ses"
-    }
-
-    req.scope = fmt.Sprintf("%s/%s/%s/aws4_request", simpleDate(req.Date), req.Host.Region, fixedService)
-
-    canonicalQueryString := ""
-    fixedUrl := req.CanonicalUri
-    if strings.Contains(req.CanonicalUri, "?") {
-        urlSplit := strings.Split(req.CanonicalUri, "?")
-        fixedUrl = urlSplit[0]
-        sp := strings.Split(urlSplit[1], "&")
-        sortutil.CiAsc(sp)
-        for i := range sp {
-            if len(canonicalQueryString) > 0 {
-                unEscaped := strings.Replace(sp[i], "+", "%20", -1)
-                canonicalQueryString = fmt.Sprintf("%s&%s", canonicalQueryString, unEscaped)
-            } else {
-                canonicalQueryString = sp[i]
-            }
-        }
-    }
-
-    req.Headers["x-amz-content-sha256"] = fmt.Sprintf("%x", req.payloadHash)
-
-    canonicalHeaders, signedHeaders := req.createCanonicalHeaders("", false)
-
-    canonicalReq := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%x",
-        req.RequestMethod, fixedUrl, canonicalQueryString, canonicalHeaders, signedHeaders, req.payloadHash)
-    hasher.Reset()
-    hasher.Write([]byte(canonicalReq)) // TODO: check return code?
-    hashedCanonicalReq := hasher.Sum(nil)
-
-
-    stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%x",
-        IsoDate(req.Date), req.scope, hashedCanonicalReq)
-
-    //fmt.Println("Canon", canonicalReq)
-    //fmt.Println("String To Sign:", stringToSign)
-
-    hasher.Reset()
-    hmacHasher := hmac.New(createHMacHasher256, []byte(fmt.Sprintf("AWS4%s", req.Key.SecretAccessKey)))
-    hmacHasher.Write([]byte(simpleDate(req.Date)))
-    hmacDate := hmacHasher.Sum(nil)
-
-    hmacHasher = hmac.New(createHMacHasher256, hmacDate)
-    hmacHasher.Write([]byte(req.Host.Region))
-    hmacRegion := hmacHasher.Sum(nil)
-
-    hmacHasher = hmac.New(createHMacHasher256, hmacRegion)
-    hmacHasher.Write([]byte(fixedService))
-    hmacService := hmacHasher.Sum(nil)
-
-    hmacHasher = hmac.New(createHMacHasher256, hmacService)
-    hmacHasher.Write([]byte("aws4_request"))
-    signingKey := hmacHasher.Sum(nil)
-
-    hmacHasher = hmac.New(createHMacHasher256, signingKey)
-    hmacHasher.Write([]byte(stringToSign))
-    req.signature = fmt.Sprintf("%x", hmacHasher.Sum(nil))
-
-    req.Headers["Authorization"] =
-        fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/%s/%s/aws4_request, SignedHeaders=%s, Signature=%s",
-            req.Key.AccessKeyId, simpleDate(req.Date), req.Host.Region, fixedService, signedHeaders, req.signature)
-}
-
-
 // http://docs.aws.amazon.com/amazonswf/latest/developerguide/HMACAuth-swf.html
 func (req * AwsRequest) createSignatureAws3() {
     req.Headers["x-amz-date"] = req.Date.Format(time.RFC1123)
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    // The payload hash of a request whose body is signed chunk by chunk.
+    StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+    // How much of the payload goes in each signed chunk. S3 wants at least 8KB.
+    DefaultChunkSize = 64 * 1024
+
+    chunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+    // the ";chunk-signature=" and the 64 hex characters of the signature
+    chunkSignatureLength = 17 + 64
+)
+
+var (
+    Verification_Error_ChunkedLengthUnknown = errors.New("Content-Length must be set to sign a payload in chunks")
+)
+
+// Everything needed to sign the chunks of a payload, taken from the seed signature of the request.
+type chunkSigner struct {
+    key         []byte
+    date        time.Time
+    scope       string
+    seed        string
+    chunkSize   int
+}
+
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
+// Signs the headers with StreamingPayload as the payload hash. The body is then sent as aws-chunked,
+// each chunk signed with the signature of the one before it, so it never has to be held in memory.
+// The length of the payload has to be known up front, in the Content-Length header.
+func (req * AwsRequest) createChunkedSignature() error {
+    decodedLength, err := strconv.ParseInt(req.Headers["Content-Length"], 10, 64)
+    if err != nil {
+        return Verification_Error_ChunkedLengthUnknown
+    }
+    chunkSize := req.ChunkSize
+    if chunkSize <= 0 {
+        chunkSize = DefaultChunkSize
+    }
+    encoding := "aws-chunked"
+    if existing := req.Headers["Content-Encoding"]; existing != "" && !strings.HasPrefix(existing, encoding) {
+        encoding += "," + existing
+    }
+    req.Headers["Content-Encoding"] = encoding
+    req.Headers["x-amz-decoded-content-length"] = strconv.FormatInt(decodedLength, 10)
+    req.Headers["Content-Length"] = strconv.FormatInt(chunkedLength(decodedLength, int64(chunkSize)), 10)
+
+    key := req.signV4(StreamingPayload)
+    req.chunkSigner = &chunkSigner{
+        key: key,
+        date: req.Date,
+        scope: req.scope,
+        seed: req.signature,
+        chunkSize: chunkSize,
+    }
+    return nil
+}
+
+// The length of a payload once it has been split into signed chunks.
+func chunkedLength(decodedLength, chunkSize int64) int64 {
+    frame := func (size int64) int64 {
+        return int64(len(strconv.FormatInt(size, 16))) + chunkSignatureLength + 2 + size + 2
+    }
+    full := decodedLength / chunkSize
+    length := full * frame(chunkSize)
+    if rest := decodedLength % chunkSize; rest > 0 {
+        length += frame(rest)
+    }
+    // the final, empty, chunk
+    return length + frame(0)
+}
+
+// Wraps body so it is read as signed chunks. The chain of signatures starts again from the seed
+// for each body, so each attempt at the request gets its own.
+func (s *chunkSigner) wrap(body io.ReadCloser) io.ReadCloser {
+    return &chunkedReader{
+        body: body,
+        signer: s,
+        previous: s.seed,
+        buf: make([]byte, s.chunkSize),
+    }
+}
+
+func (s *chunkSigner) sign(previous string, chunk []byte) string {
+    stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%x\n%x",
+        chunkAlgorithm, IsoDate(s.date), s.scope, previous,
+        sha256.Sum256(nil), sha256.Sum256(chunk))
+    return v4Signature(s.key, stringToSign)
+}
+
+// Reads a chunk at a time from body, and hands out the signed chunk.
+type chunkedReader struct {
+    body        io.ReadCloser
+    signer      *chunkSigner
+    previous    string
+    buf         []byte
+    out         bytes.Buffer
+    done        bool
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+    for r.out.Len() == 0 {
+        if r.done {
+            return 0, io.EOF
+        }
+        n, err := io.ReadFull(r.body, r.buf)
+        if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+            return 0, err
+        }
+        chunk := r.buf[:n]
+        r.previous = r.signer.sign(r.previous, chunk)
+        fmt.Fprintf(&r.out, "%x;chunk-signature=%s\r\n", n, r.previous)
+        r.out.Write(chunk)
+        r.out.WriteString("\r\n")
+        // an empty chunk marks the end
+        r.done = n == 0
+    }
+    return r.out.Read(p)
+}
+
+func (r *chunkedReader) Close() error {
+    return r.body.Close()
+}
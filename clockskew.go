@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "log/slog"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// Error codes that mean the signature was rejected because our clock is off.
+// Some of these also come back for a bad signature, so the Date of the response
+// decides if we really are skewed.
+var clockSkewErrorCodes = map[string]bool{
+    // s3
+    "RequestTimeTooSkewed": true,
+    "SignatureDoesNotMatch": true,
+    // dynamo, swf, cloudwatch logs
+    "InvalidSignatureException": true,
+    // sqs, sns, cloudwatch, ec2
+    "RequestExpired": true,
+    "AuthFailure": true,
+    "RequestInTheFuture": true,
+}
+
+// How far our clock has to be from AWS's before we correct for it. AWS allows 5 minutes,
+// but the Date header is only to the second and takes time to get to us.
+const clockSkewThreshold = time.Minute
+
+var (
+    clockSkewLock sync.RWMutex
+    clockSkew time.Duration
+)
+
+// Returns true if the service error code can mean our clock is skewed.
+func IsClockSkewCode(code string) bool {
+    return clockSkewErrorCodes[code]
+}
+
+// The correction added to the local clock when signing requests. Positive when AWS's
+// clock is ahead of ours. Found by looking at the Date of responses that failed with a
+// clock skew error.
+func ClockSkew() time.Duration {
+    clockSkewLock.RLock()
+    defer clockSkewLock.RUnlock()
+    return clockSkew
+}
+
+// Sets the clock skew correction. 0 trusts the local clock again.
+func SetClockSkew(skew time.Duration) {
+    clockSkewLock.Lock()
+    defer clockSkewLock.Unlock()
+    clockSkew = skew
+}
+
+// The current time, corrected for clock skew. Used for signing.
+func skewedNow() time.Time {
+    return time.Now().Add(ClockSkew())
+}
+
+// Works out how far our clock is from the Date of resp. Returns false if there is no
+// Date, or it is close enough to the corrected clock that skew wasn't the problem.
+func clockSkewFromResponse(resp *http.Response) (time.Duration, bool) {
+    serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+    if err != nil {
+        return 0, false
+    }
+    skew := time.Until(serverTime)
+    drift := skew - ClockSkew()
+    if drift < clockSkewThreshold && drift > -clockSkewThreshold {
+        return 0, false
+    }
+    return skew, true
+}
+
+// True if the response says our clock is skewed, and the request can be sent again.
+// Updates the process wide correction, so later requests are signed with the right time.
+func (op *Operation) clockSkewed() bool {
+    if !op.Request.canResend() {
+        return false
+    }
+    resp := op.HttpResponse
+    if resp.StatusCode != 400 && resp.StatusCode != 403 {
+        return false
+    }
+    if !IsClockSkewCode(peekErrorCode(resp)) {
+        return false
+    }
+    skew, ok := clockSkewFromResponse(resp)
+    if !ok {
+        return false
+    }
+    SetClockSkew(skew)
+    if logger, ok := op.logEnabled(slog.LevelWarn); ok {
+        logger.LogAttrs(op.Context, slog.LevelWarn, "awsgo correcting for clock skew",
+            slog.String("service", op.Request.Host.Service),
+            slog.Duration("skew", skew),
+        )
+    }
+    return true
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// A server whose clock is an hour ahead of ours, and rejects anything signed more than 5 minutes off.
+func newSkewedServer(t *testing.T, calls *int) *httptest.Server {
+    return httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        *calls ++
+        serverTime := time.Now().Add(time.Hour)
+        w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+        signed, err := time.Parse("20060102T150405Z", r.Header.Get("x-amz-date"))
+        if err != nil {
+            t.Errorf("Bad x-amz-date: %v", err)
+            return
+        }
+        if d := serverTime.Sub(signed); d > 5 * time.Minute || d < -5 * time.Minute {
+            http.Error(w, `{"__type":"com.amazon.coral.service#InvalidSignatureException","message":"Signature expired"}`, 400)
+            return
+        }
+        fmt.Fprint(w, "ok")
+    }))
+}
+
+func Test_ClockSkewIsCorrected(t *testing.T) {
+    defer SetClockSkew(0)
+    calls := 0
+    ts := newSkewedServer(t, &calls)
+    defer ts.Close()
+
+    resp, err := doEcho(t, newEchoRequest(ts.URL, nil))
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.(string) != "ok" || calls != 2 {
+        t.Fatalf("Expected the request to be resent once. Got %d calls, response %v", calls, resp)
+    }
+    if skew := ClockSkew(); skew < 59 * time.Minute || skew > 61 * time.Minute {
+        t.Fatalf("Expected about an hour of skew. Got %v", skew)
+    }
+    // later requests are signed with the corrected time straight away
+    if _, err := doEcho(t, newEchoRequest(ts.URL, nil)); err != nil || calls != 3 {
+        t.Fatalf("Expected one more call. Got %d calls, error %v", calls, err)
+    }
+}
+
+func Test_SignatureErrorWithoutSkewIsNotResent(t *testing.T) {
+    defer SetClockSkew(0)
+    calls := 0
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        calls ++
+        w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+        http.Error(w, `{"__type":"com.amazon.coral.service#InvalidSignatureException","message":"The request signature we calculated does not match"}`, 400)
+    }))
+    defer ts.Close()
+
+    if _, err := doEcho(t, newEchoRequest(ts.URL, nil)); err == nil {
+        t.Fatalf("Expected an error")
+    }
+    if calls != 1 || ClockSkew() != 0 {
+        t.Fatalf("Expected no resend and no skew. Got %d calls, skew %v", calls, ClockSkew())
+    }
+}
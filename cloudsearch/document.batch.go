@@ -33,9 +33,11 @@ package cloudsearch
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "errors"
     "fmt"
+    "github.com/fromkeith/awsgo"
     "io"
     "io/ioutil"
     "net/http"
@@ -63,6 +65,8 @@ type BatchDocumentRequest struct {
     // then set this to 'blah'
     Endpoint        string      `json:"-"`
     Region          string
+    // The http client to use. http.DefaultClient is used if not specified
+    HttpClient      *http.Client    `json:"-"`
 }
 
 type BatchDocumentMessage struct {
@@ -82,11 +86,21 @@ type BatchResponseError struct {
     Response        string
     JsonError       error
     StatusCode      int
+    RequestId       string
 }
 func (b BatchResponseError) Error() string {
     return b.Response
 }
 
+func (b BatchResponseError) Unwrap() error { return b.JsonError }
+func (b BatchResponseError) ErrorCode() string { return awsgo.ExtractErrorCode([]byte(b.Response)) }
+func (b BatchResponseError) ErrorMessage() string { return awsgo.ExtractErrorMessage([]byte(b.Response)) }
+func (b BatchResponseError) HttpStatusCode() int { return b.StatusCode }
+func (b BatchResponseError) RequestID() string { return b.RequestId }
+func (b BatchResponseError) Retryable() bool { return awsgo.IsRetryableError(b.ErrorCode(), b.StatusCode) }
+func (b BatchResponseError) Throttling() bool { return awsgo.IsThrottlingError(b.ErrorCode(), b.StatusCode) }
+func (b BatchResponseError) Is(target error) bool { return awsgo.MatchesErrorCode(b, target) }
+
 
 // Creates a new BatchDocumentRequest, populating in some defaults
 func NewBatchDocumentRequest() *BatchDocumentRequest {
@@ -107,6 +121,7 @@ func (gir BatchDocumentRequest) DeMarshalResponse(response []byte, headers http.
             Response: string(response),
             JsonError: err,
             StatusCode: statusCode,
+            RequestId: headers.Get("X-Amzn-Requestid"),
         }
     }
     resp.StatusCode = statusCode
@@ -115,6 +130,11 @@ func (gir BatchDocumentRequest) DeMarshalResponse(response []byte, headers http.
 
 
 func (gir BatchDocumentRequest) Request() (*BatchDocumentResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir BatchDocumentRequest) RequestWithContext(ctx context.Context) (*BatchDocumentResponse, error) {
     urlStr := fmt.Sprintf("https://%s.%s.cloudsearch.amazonaws.com/2013-01-01/documents/batch", gir.Endpoint, gir.Region)
     u, err := url.Parse(urlStr)
     if err != nil {
@@ -132,15 +152,23 @@ func (gir BatchDocumentRequest) Request() (*BatchDocumentResponse, error) {
             "Content-Type": []string{"application/json"},
         },
         Method: "POST",
-        Close: true,
     }
-    resp, err := http.DefaultClient.Do(&hreq)
+    httpClient := gir.HttpClient
+    if httpClient == nil {
+        httpClient = http.DefaultClient
+    }
+    resp, err := httpClient.Do(hreq.WithContext(ctx))
     if err != nil {
+        if ctx.Err() != nil {
+            return nil, ctx.Err()
+        }
         return nil, err
     }
     defer resp.Body.Close()
     buf := bytes.Buffer{}
-    io.Copy(&buf, resp.Body)
+    if _, err = io.Copy(&buf, resp.Body); err != nil && ctx.Err() != nil {
+        return nil, ctx.Err()
+    }
     return gir.DeMarshalResponse([]byte(buf.String()), resp.Header, resp.StatusCode)
 
 }
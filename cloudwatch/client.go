@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package cloudwatch
+
+import (
+    "github.com/fromkeith/awsgo"
+)
+
+// Creates CloudWatch requests that share a Config, so region, credentials and
+// the http client don't have to be set on every request.
+type Client struct {
+    Config      awsgo.Config
+}
+
+func NewClient(cfg awsgo.Config) *Client {
+    return &Client{Config: cfg}
+}
+
+func (c *Client) NewCreateLogGroupRequest() *CreateLogGroupRequest {
+    req := NewCreateLogGroupRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewCreateLogStreamRequest() *CreateLogStreamRequest {
+    req := NewCreateLogStreamRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewDescribeLogGroupsRequest() *DescribeLogGroupsRequest {
+    req := NewDescribeLogGroupsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewDescribeLogStreamsRequest() *DescribeLogStreamsRequest {
+    req := NewDescribeLogStreamsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewGetLogEventsRequest() *GetLogEventsRequest {
+    req := NewGetLogEventsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewGetMetricStatisticsRequest() *GetMetricStatisticsRequest {
+    req := NewGetMetricStatisticsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewListMetricsRequest() *ListMetricsRequest {
+    req := NewListMetricsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewPutLogEventsRequest() *PutLogEventsRequest {
+    req := NewPutLogEventsRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewPutMetricRequest() *PutMetricRequest {
+    req := NewPutMetricRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewPutRetentionPolicyRequest() *PutRetentionPolicyRequest {
+    req := NewPutRetentionPolicyRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
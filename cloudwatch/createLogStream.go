@@ -32,9 +32,9 @@ package cloudwatch
 
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
-    "fmt"
 )
 
 
@@ -79,19 +79,24 @@ func (req CreateLogStreamRequest) DeMarshalResponse(response []byte, headers map
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     return new(CreateLogStreamResponse)
 }
 
 
 func (req CreateLogStreamRequest) Request() (*CreateLogStreamResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req CreateLogStreamRequest) RequestWithContext(ctx context.Context) (*CreateLogStreamResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -32,9 +32,8 @@ package cloudwatch
 
 
 import (
+    "context"
     "encoding/json"
-    "errors"
-    "fmt"
     "github.com/fromkeith/awsgo"
 )
 
@@ -90,7 +89,7 @@ func (req DescribeLogGroupsRequest) DeMarshalResponse(response []byte, headers m
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(DescribeLogGroupsResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -101,12 +100,17 @@ func (req DescribeLogGroupsRequest) DeMarshalResponse(response []byte, headers m
 
 
 func (req DescribeLogGroupsRequest) Request() (*DescribeLogGroupsResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req DescribeLogGroupsRequest) RequestWithContext(ctx context.Context) (*DescribeLogGroupsResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -32,9 +32,8 @@ package cloudwatch
 
 
 import (
+    "context"
     "encoding/json"
-    "errors"
-    "fmt"
     "github.com/fromkeith/awsgo"
 )
 
@@ -93,7 +92,7 @@ func (req DescribeLogStreamsRequest) DeMarshalResponse(response []byte, headers
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(DescribeLogStreamsResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -104,12 +103,17 @@ func (req DescribeLogStreamsRequest) DeMarshalResponse(response []byte, headers
 
 
 func (req DescribeLogStreamsRequest) Request() (*DescribeLogStreamsResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req DescribeLogStreamsRequest) RequestWithContext(ctx context.Context) (*DescribeLogStreamsResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
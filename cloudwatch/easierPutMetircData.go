@@ -31,7 +31,6 @@
 package cloudwatch
 
 import (
-    "github.com/fromkeith/awsgo"
     "time"
     "fmt"
     "sync"
@@ -100,7 +99,6 @@ func SimpleKeyValueMetric(name string, value float64, unit string, namespace str
     putMetricRequest.Host.Domain = "amazonaws.com"
 
     if sendOnThisThread {
-        putMetricRequest.Key, _ = awsgo.GetSecurityKeys()
         _, err := putMetricRequest.Request()
         return err
     } else {
@@ -146,7 +144,6 @@ func MultiKeyValueDimensionMetrics(name []string, value []float64, unit []string
 
 
     if sendOnThisThread {
-        putMetricRequest.Key, _ = awsgo.GetSecurityKeys()
         _, err := putMetricRequest.Request()
         return err
     } else {
@@ -194,7 +191,6 @@ func CreateOffThreadSender() bool {
                     if putMetricRequest == nil {
                         return
                     }
-                    putMetricRequest.Key, _ = awsgo.GetSecurityKeys()
                     _, err := putMetricRequest.Request()
                     if err != nil {
                         fmt.Println(err)
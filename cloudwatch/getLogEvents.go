@@ -32,9 +32,9 @@ package cloudwatch
 
 
 import (
+    "context"
     "encoding/json"
     "errors"
-    "fmt"
     "github.com/fromkeith/awsgo"
     "time"
 )
@@ -106,7 +106,7 @@ func (req GetLogEventsRequest) DeMarshalResponse(response []byte, headers map[st
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(GetLogEventsResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -117,12 +117,17 @@ func (req GetLogEventsRequest) DeMarshalResponse(response []byte, headers map[st
 
 
 func (req GetLogEventsRequest) Request() (*GetLogEventsResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req GetLogEventsRequest) RequestWithContext(ctx context.Context) (*GetLogEventsResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -31,6 +31,7 @@
 package cloudwatch
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -164,12 +165,17 @@ func (gir GetMetricStatisticsRequest) DeMarshalResponse(response []byte, headers
 }
 
 func (gir GetMetricStatisticsRequest) Request() (*GetMetricStatisticsResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir GetMetricStatisticsRequest) RequestWithContext(ctx context.Context) (*GetMetricStatisticsResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -31,6 +31,7 @@
 package cloudwatch
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "fmt"
     "net/url"
@@ -118,12 +119,17 @@ func (req ListMetricsRequest) DeMarshalResponse(response []byte, headers map[str
 }
 
 func (req ListMetricsRequest) Request() (*ListMetricsResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req ListMetricsRequest) RequestWithContext(ctx context.Context) (*ListMetricsResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
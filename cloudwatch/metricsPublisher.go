@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package cloudwatch
+
+import (
+    "context"
+    "github.com/fromkeith/awsgo"
+    "math"
+    "sort"
+    "sync"
+    "time"
+)
+
+const (
+    // PutMetricData takes at most this many datums per request
+    DefaultMetricsBatchSize = 20
+    DefaultMetricsFlushInterval = time.Minute
+    // Latency histogram buckets are spaced evenly on a log scale, this many per power of 10
+    latencyBucketsPerDecade = 20
+)
+
+// An awsgo.MetricsHook that ships request metrics to CloudWatch.
+// Metrics are aggregated into StatisticSets per service and operation, and sent
+// every FlushInterval in batches of PutMetricData requests.
+// Latency is sent as a histogram of Values and Counts instead, so CloudWatch can
+// give percentiles for it.
+//
+// Published metrics, with Service and Operation dimensions:
+//      Calls, Latency (Milliseconds), Retries, Throttles, Errors, ConsumedCapacity
+// and ErrorCode, with an extra ErrorCode dimension.
+type MetricsPublisher struct {
+    Namespace       string
+    // Used for the PutMetricData requests
+    Config          awsgo.Config
+    BatchSize       int
+    FlushInterval   time.Duration
+    // Called when a batch fails to send. The batch is dropped.
+    OnError         func(err error)
+
+    lock            sync.Mutex
+    stats           map[metricKey]*StatisticSet
+    latency         map[metricKey]map[float64]float64
+    started         bool
+    stop            chan struct{}
+    done            chan struct{}
+}
+
+type metricKey struct {
+    service     string
+    operation   string
+    name        string
+    unit        string
+    errorCode   string
+}
+
+// Creates a MetricsPublisher. It starts flushing in the background with the first metrics it gets.
+// Use it with awsgo.SetDefaultMetricsHook, or on a Config. Close it to send what is left.
+func NewMetricsPublisher(cfg awsgo.Config, namespace string) *MetricsPublisher {
+    return &MetricsPublisher{
+        Namespace: namespace,
+        Config: cfg,
+        BatchSize: DefaultMetricsBatchSize,
+        FlushInterval: DefaultMetricsFlushInterval,
+    }
+}
+
+func (p *MetricsPublisher) RequestCompleted(ctx context.Context, m awsgo.RequestMetrics) {
+    p.lock.Lock()
+    defer p.lock.Unlock()
+    if p.stats == nil {
+        p.stats = make(map[metricKey]*StatisticSet)
+        p.latency = make(map[metricKey]map[float64]float64)
+    }
+    if !p.started {
+        p.started = true
+        p.stop = make(chan struct{})
+        p.done = make(chan struct{})
+        go p.flushLoop(p.FlushInterval, p.stop, p.done)
+    }
+    key := metricKey{service: m.Service, operation: m.Operation}
+    p.add(key, "Calls", UNIT_COUNT, 1)
+    p.addLatency(key, float64(m.Latency) / float64(time.Millisecond))
+    if m.Attempts > 1 {
+        p.add(key, "Retries", UNIT_COUNT, float64(m.Attempts - 1))
+    }
+    if m.Throttles > 0 {
+        p.add(key, "Throttles", UNIT_COUNT, float64(m.Throttles))
+    }
+    if m.Err != nil || m.StatusCode >= 400 {
+        p.add(key, "Errors", UNIT_COUNT, 1)
+        if m.ErrorCode != "" {
+            codeKey := key
+            codeKey.errorCode = m.ErrorCode
+            p.add(codeKey, "ErrorCode", UNIT_COUNT, 1)
+        }
+    }
+    if m.ConsumedCapacity > 0 {
+        p.add(key, "ConsumedCapacity", UNIT_COUNT, m.ConsumedCapacity)
+    }
+}
+
+func (p *MetricsPublisher) add(key metricKey, name, unit string, value float64) {
+    key.name = name
+    key.unit = unit
+    s, ok := p.stats[key]
+    if !ok {
+        p.stats[key] = &StatisticSet{Maximum: value, Minimum: value, SampleCount: 1, Sum: value}
+        return
+    }
+    if value > s.Maximum {
+        s.Maximum = value
+    }
+    if value < s.Minimum {
+        s.Minimum = value
+    }
+    s.SampleCount ++
+    s.Sum += value
+}
+
+func (p *MetricsPublisher) addLatency(key metricKey, ms float64) {
+    key.name = "Latency"
+    key.unit = UNIT_MILLISECONDS
+    h, ok := p.latency[key]
+    if !ok {
+        h = make(map[float64]float64)
+        p.latency[key] = h
+    }
+    h[latencyBucket(ms)] ++
+}
+
+// Rounds to the nearest log scale bucket, to 3 significant digits.
+func latencyBucket(ms float64) float64 {
+    if ms <= 0 {
+        return 0
+    }
+    exp := math.Round(math.Log10(ms) * latencyBucketsPerDecade) / latencyBucketsPerDecade
+    scale := math.Pow(10, math.Floor(exp) - 2)
+    return math.Round(math.Pow(10, exp) / scale) * scale
+}
+
+// Sends everything aggregated so far. Returns the first error, if any batch failed.
+func (p *MetricsPublisher) Flush() error {
+    p.lock.Lock()
+    stats, latency := p.stats, p.latency
+    p.stats = make(map[metricKey]*StatisticSet)
+    p.latency = make(map[metricKey]map[float64]float64)
+    p.lock.Unlock()
+    if len(stats) == 0 && len(latency) == 0 {
+        return nil
+    }
+
+    now := time.Now()
+    keys := make([]metricKey, 0, len(stats))
+    for k := range stats {
+        keys = append(keys, k)
+    }
+    sortMetricKeys(keys)
+    data := make([]MetricDatum, 0, len(stats) + len(latency))
+    for _, k := range keys {
+        d := newMetricDatum(k, now)
+        d.StatisticValues = stats[k]
+        data = append(data, d)
+    }
+
+    keys = keys[:0]
+    for k := range latency {
+        keys = append(keys, k)
+    }
+    sortMetricKeys(keys)
+    for _, k := range keys {
+        values := make([]float64, 0, len(latency[k]))
+        for v := range latency[k] {
+            values = append(values, v)
+        }
+        sort.Float64s(values)
+        // each datum can only hold so many buckets. CloudWatch merges them back together.
+        for start := 0; start < len(values); start += MaxHistogramValues {
+            end := start + MaxHistogramValues
+            if end > len(values) {
+                end = len(values)
+            }
+            d := newMetricDatum(k, now)
+            d.Values = values[start:end]
+            d.Counts = make([]float64, end - start)
+            for i, v := range d.Values {
+                d.Counts[i] = latency[k][v]
+            }
+            data = append(data, d)
+        }
+    }
+
+    batchSize := p.BatchSize
+    if batchSize <= 0 {
+        batchSize = DefaultMetricsBatchSize
+    }
+    var firstErr error
+    for start := 0; start < len(data); start += batchSize {
+        end := start + batchSize
+        if end > len(data) {
+            end = len(data)
+        }
+        req := NewPutMetricRequest()
+        p.Config.Apply(&req.RequestBuilder)
+        // don't report on our own reporting
+        req.MetricsHook = awsgo.MetricsHookFunc(func (ctx context.Context, m awsgo.RequestMetrics) {})
+        req.Namespace = p.Namespace
+        req.MetricData = data[start:end]
+        if _, err := req.Request(); err != nil {
+            if p.OnError != nil {
+                p.OnError(err)
+            }
+            if firstErr == nil {
+                firstErr = err
+            }
+        }
+    }
+    return firstErr
+}
+
+// keep the batches stable, which makes them easier to follow
+func sortMetricKeys(keys []metricKey) {
+    sort.Slice(keys, func (i, j int) bool {
+        a, b := keys[i], keys[j]
+        if a.service != b.service {
+            return a.service < b.service
+        }
+        if a.operation != b.operation {
+            return a.operation < b.operation
+        }
+        if a.name != b.name {
+            return a.name < b.name
+        }
+        return a.errorCode < b.errorCode
+    })
+}
+
+func newMetricDatum(k metricKey, now time.Time) MetricDatum {
+    d := MetricDatum{
+        Dimensions: []MetricDimensions{
+            {Name: "Service", Value: k.service},
+            {Name: "Operation", Value: k.operation},
+        },
+        MetricName: k.name,
+        Timestamp: &now,
+        Unit: k.unit,
+    }
+    if k.errorCode != "" {
+        d.Dimensions = append(d.Dimensions, MetricDimensions{Name: "ErrorCode", Value: k.errorCode})
+    }
+    return d
+}
+
+func (p *MetricsPublisher) flushLoop(interval time.Duration, stop, done chan struct{}) {
+    defer close(done)
+    if interval <= 0 {
+        interval = DefaultMetricsFlushInterval
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <- ticker.C:
+            p.Flush()
+        case <- stop:
+            return
+        }
+    }
+}
+
+// Stops the background flushing, and sends what is left.
+func (p *MetricsPublisher) Close() error {
+    p.lock.Lock()
+    stop, done := p.stop, p.done
+    p.started = false
+    p.stop = nil
+    p.lock.Unlock()
+    if stop != nil {
+        close(stop)
+        <- done
+    }
+    return p.Flush()
+}
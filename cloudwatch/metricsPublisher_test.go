@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package cloudwatch
+
+import (
+    "github.com/fromkeith/awsgo"
+    "net/url"
+    "testing"
+    "time"
+)
+
+func Test_LatencyBucket(t *testing.T) {
+    if b := latencyBucket(0); b != 0 {
+        t.Fatalf("Zero latency should be bucket 0. Got: %v", b)
+    }
+    if b := latencyBucket(100); b != 100 {
+        t.Fatalf("Bucket for 100 should be 100. Got: %v", b)
+    }
+    // close values share a bucket, far ones don't
+    if latencyBucket(101) != latencyBucket(102) {
+        t.Fatalf("101 and 102 should share a bucket. Got: %v %v", latencyBucket(101), latencyBucket(102))
+    }
+    if latencyBucket(100) == latencyBucket(150) {
+        t.Fatalf("100 and 150 should not share a bucket")
+    }
+}
+
+func Test_MetricsPublisherLatencyHistogram(t *testing.T) {
+    p := NewMetricsPublisher(awsgo.Config{}, "test")
+    p.stats = make(map[metricKey]*StatisticSet)
+    p.latency = make(map[metricKey]map[float64]float64)
+    key := metricKey{service: "dynamodb", operation: "GetItem"}
+    p.addLatency(key, 100)
+    p.addLatency(key, 100)
+    p.addLatency(key, 1000)
+
+    key.name = "Latency"
+    key.unit = UNIT_MILLISECONDS
+    h := p.latency[key]
+    if len(h) != 2 || h[100] != 2 || h[1000] != 1 {
+        t.Fatalf("Histogram should have 2 at 100 and 1 at 1000. Got: %v", h)
+    }
+
+    d := newMetricDatum(key, time.Now())
+    d.Values = []float64{100, 1000}
+    d.Counts = []float64{2, 1}
+    vals := url.Values{}
+    if err := addMetricDatumToUri(vals, d, 1); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if vals.Get("MetricData.member.1.Values.member.2") != "1000.000000" ||
+            vals.Get("MetricData.member.1.Counts.member.1") != "2.000000" {
+        t.Fatalf("Values and Counts not encoded. Got: %v", vals)
+    }
+    d.Counts = d.Counts[:1]
+    if err := addMetricDatumToUri(url.Values{}, d, 1); err == nil {
+        t.Fatalf("Mismatched Counts should be an error")
+    }
+}
@@ -32,9 +32,9 @@ package cloudwatch
 
 
 import (
+    "context"
     "encoding/json"
     "errors"
-    "fmt"
     "github.com/fromkeith/awsgo"
     "time"
 )
@@ -98,7 +98,7 @@ func (req PutLogEventsRequest) DeMarshalResponse(response []byte, headers map[st
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(PutLogEventsResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -110,12 +110,17 @@ func (req PutLogEventsRequest) DeMarshalResponse(response []byte, headers map[st
 // events and entire content cannot be above 32,768 bytes. If you are unsure of your
 // content size, use RequestSplit. It will use multiple requests to send your logs.
 func (req PutLogEventsRequest) Request() (*PutLogEventsResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req PutLogEventsRequest) RequestWithContext(ctx context.Context) (*PutLogEventsResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -124,6 +129,11 @@ func (req PutLogEventsRequest) Request() (*PutLogEventsResponse, error) {
 
 
 func (req PutLogEventsRequest) RequestSplit() (*PutLogEventsResponse, error) {
+    return req.RequestSplitWithContext(context.Background())
+}
+
+// Same as RequestSplit, but stops sending batches once ctx is done.
+func (req PutLogEventsRequest) RequestSplitWithContext(ctx context.Context) (*PutLogEventsResponse, error) {
     var err error
     var resp *PutLogEventsResponse
     start := 0
@@ -131,26 +141,28 @@ func (req PutLogEventsRequest) RequestSplit() (*PutLogEventsResponse, error) {
     for i := range req.LogEvents{
         newSize := calcSize + len(req.LogEvents[i].Message) + 60 // 60 is an over approx json surrounding the message + timestamp
         if newSize >= 30000 || i - start == 1000 {
-            resp, err = split(req, start, i)
+            resp, err = split(ctx, req, start, i)
             if err != nil {
                 return nil, err
             }
-            time.Sleep(100 * time.Millisecond)
+            if err = awsgo.SleepWithContext(ctx, 100 * time.Millisecond); err != nil {
+                return nil, err
+            }
             req.SequenceToken = resp.NextSequenceToken
             calcSize = newSize - calcSize
             start = i
         }
         calcSize = newSize
     }
-    return split(req, start, len(req.LogEvents))
+    return split(ctx, req, start, len(req.LogEvents))
 }
 
-func split(req PutLogEventsRequest, start, end int) (*PutLogEventsResponse, error) {
+func split(ctx context.Context, req PutLogEventsRequest, start, end int) (*PutLogEventsResponse, error) {
     newReq := NewPutLogEventsRequest()
     newReq.LogEvents = req.LogEvents[start:end]
     newReq.LogGroupName = req.LogGroupName
     newReq.LogStreamName = req.LogStreamName
     newReq.SequenceToken = req.SequenceToken
     newReq.Key = req.Key
-    return newReq.Request()
+    return newReq.RequestWithContext(ctx)
 }
\ No newline at end of file
@@ -31,6 +31,7 @@
 package cloudwatch
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -69,6 +70,9 @@ const (
     UNIT_NONE = "None"
 )
 
+// The most Values a MetricDatum can have
+const MaxHistogramValues = 150
+
 
 type StatisticSet struct {
     Maximum         float64
@@ -89,7 +93,10 @@ type MetricDatum struct {
     Timestamp       * time.Time
     Unit            string
     Value           * float64
-
+    // A histogram: each value, and how many times it was seen. At most MaxHistogramValues.
+    // Use instead of Value or StatisticValues.
+    Values          []float64
+    Counts          []float64
 }
 
 type PutMetricRequest struct {
@@ -174,6 +181,24 @@ func addMetricDatumToUri(vals url.Values, datum MetricDatum, index int) (error)
             fmt.Sprintf("%f", *datum.Value),
         )
     }
+    if len(datum.Values) > MaxHistogramValues {
+        return fmt.Errorf("MetricDatum can have at most %d Values", MaxHistogramValues)
+    }
+    if len(datum.Counts) > 0 && len(datum.Counts) != len(datum.Values) {
+        return errors.New("MetricDatum needs a Count for each of its Values")
+    }
+    for i := range datum.Values {
+        vals.Set(
+            fmt.Sprintf("MetricData.member.%d.Values.member.%d", index, i + 1),
+            fmt.Sprintf("%f", datum.Values[i]),
+        )
+    }
+    for i := range datum.Counts {
+        vals.Set(
+            fmt.Sprintf("MetricData.member.%d.Counts.member.%d", index, i + 1),
+            fmt.Sprintf("%f", datum.Counts[i]),
+        )
+    }
     return nil
 }
 
@@ -211,20 +236,25 @@ func (gir PutMetricRequest) DeMarshalResponse(response []byte, headers map[strin
     //fmt.Println("PutMetric: ", string(response))
     //fmt.Println("PutMetric.StatusCode: ", statusCode)
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     xml.Unmarshal(response, giResponse)
     //json.Unmarshal([]byte(response), giResponse)
     return giResponse
 }
 
-func (gir PutMetricRequest) Request() (*PutMetricResponse, error) {    
+func (gir PutMetricRequest) Request() (*PutMetricResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir PutMetricRequest) RequestWithContext(ctx context.Context) (*PutMetricResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
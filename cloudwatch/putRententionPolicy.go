@@ -32,6 +32,7 @@ package cloudwatch
 
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -85,19 +86,24 @@ func (req PutRetentionPolicyRequest) DeMarshalResponse(response []byte, headers
         return err
     }
     if statusCode != 200 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     return new(PutRetentionPolicyResponse)
 }
 
 
 func (req PutRetentionPolicyRequest) Request() (*PutRetentionPolicyResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req PutRetentionPolicyRequest) RequestWithContext(ctx context.Context) (*PutRetentionPolicyResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
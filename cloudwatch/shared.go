@@ -33,6 +33,7 @@ package cloudwatch
 import (
     "fmt"
     "encoding/json"
+    "github.com/fromkeith/awsgo"
     "regexp"
 )
 
@@ -73,12 +74,30 @@ type ErrorResult struct {
     Type        string  `json:"__type"`
     Message     string  `json:"message"`
     StatusCode  int
+    RequestId   string
 }
 
 func (e * ErrorResult) Error() string {
     return fmt.Sprintf("%s : %s", e.Type, e.Message)
 }
 
+func (e * ErrorResult) ErrorCode() string { return awsgo.StripErrorNamespace(e.Type) }
+func (e * ErrorResult) ErrorMessage() string { return e.Message }
+func (e * ErrorResult) HttpStatusCode() int { return e.StatusCode }
+func (e * ErrorResult) RequestID() string { return e.RequestId }
+func (e * ErrorResult) Retryable() bool { return awsgo.IsRetryableError(e.ErrorCode(), e.StatusCode) }
+func (e * ErrorResult) Throttling() bool { return awsgo.IsThrottlingError(e.ErrorCode(), e.StatusCode) }
+func (e * ErrorResult) Is(target error) bool { return awsgo.MatchesErrorCode(e, target) }
+
+func (e * ErrorResult) SetResponseInfo(statusCode int, requestId string) {
+    if e.StatusCode == 0 {
+        e.StatusCode = statusCode
+    }
+    if e.RequestId == "" {
+        e.RequestId = requestId
+    }
+}
+
 func CheckForErrorResponse(response []byte, statusCode int) error {
     errorResult := new(ErrorResult)
     err2 := json.Unmarshal([]byte(response), errorResult)
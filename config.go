@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "log/slog"
+    "net/http"
+    "os"
+)
+
+// Settings shared by every request made through a service client, eg. dynamo.NewClient(cfg).
+// Empty fields leave the request's own defaults alone.
+type Config struct {
+    // Eg. us-west-2
+    Region              string
+    // Where to get credentials from. GetSecurityKeys is used if nil
+    CredentialsProvider CredentialsProvider
+    // The http client to use. A default one will be used if nil
+    HttpClient          *http.Client
+    // Decides when a failed request is retried. The default RetryPolicy is used if nil
+    RetryPolicy         RetryPolicy
+    // Decides where requests are sent. The default EndpointResolver is used if nil
+    EndpointResolver    EndpointResolver
+    // Where requests are logged. Nothing is logged if nil
+    Logger              *slog.Logger
+    // The stages requests go through. The default Handlers are used if nil
+    Handlers            *Handlers
+    // Receives metrics about each request. The default MetricsHook is used if nil
+    MetricsHook         MetricsHook
+    // Starts a span for each request. The default Tracer is used if nil
+    Tracer              Tracer
+}
+
+// Creates a Config with the region taken from AWS_REGION, AWS_DEFAULT_REGION,
+// or the profile in ~/.aws/config.
+func NewDefaultConfig() Config {
+    return Config{
+        Region: DefaultRegion(),
+    }
+}
+
+// The region from AWS_REGION, AWS_DEFAULT_REGION, or the profile (AWS_PROFILE or 'default')
+// in ~/.aws/config. Empty if none are set.
+func DefaultRegion() string {
+    if v := os.Getenv("AWS_REGION"); v != "" {
+        return v
+    }
+    if v := os.Getenv("AWS_DEFAULT_REGION"); v != "" {
+        return v
+    }
+    if section, err := LoadSharedConfigProfile("", ""); err == nil {
+        return section["region"]
+    }
+    return ""
+}
+
+// Copies the config onto a request.
+func (c Config) Apply(rb *RequestBuilder) {
+    if c.Region != "" {
+        rb.Host.Region = c.Region
+    }
+    if c.CredentialsProvider != nil {
+        rb.CredentialsProvider = c.CredentialsProvider
+    }
+    if c.HttpClient != nil {
+        rb.HttpClient = c.HttpClient
+    }
+    if c.RetryPolicy != nil {
+        rb.RetryPolicy = c.RetryPolicy
+    }
+    if c.EndpointResolver != nil {
+        rb.EndpointResolver = c.EndpointResolver
+    }
+    if c.Logger != nil {
+        rb.Logger = c.Logger
+    }
+    if c.Handlers != nil {
+        rb.Handlers = c.Handlers
+    }
+    if c.MetricsHook != nil {
+        rb.MetricsHook = c.MetricsHook
+    }
+    if c.Tracer != nil {
+        rb.Tracer = c.Tracer
+    }
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "io/ioutil"
+    "net/http"
+    "path/filepath"
+    "testing"
+)
+
+func Test_ConfigApply(t *testing.T) {
+    client := &http.Client{}
+    provider := StaticCredentialsProvider{Key: Credentials{AccessKeyId: "a", SecretAccessKey: "b"}}
+    cfg := Config{
+        Region: "eu-west-1",
+        CredentialsProvider: provider,
+        HttpClient: client,
+        RetryPolicy: NoRetryPolicy{},
+    }
+    var rb RequestBuilder
+    rb.Host.Region = "us-east-1"
+    cfg.Apply(&rb)
+    if rb.Host.Region != "eu-west-1" || rb.HttpClient != client || rb.CredentialsProvider != provider {
+        t.Fatalf("Config was not applied: %v", rb)
+    }
+    if _, ok := rb.RetryPolicy.(NoRetryPolicy); !ok {
+        t.Fatalf("Expected NoRetryPolicy. Got %v", rb.RetryPolicy)
+    }
+    // empty fields leave the request alone
+    rb.Host.Region = "us-west-2"
+    Config{}.Apply(&rb)
+    if rb.Host.Region != "us-west-2" || rb.HttpClient != client {
+        t.Fatalf("Empty config should change nothing: %v", rb)
+    }
+}
+
+func Test_DefaultRegion(t *testing.T) {
+    filename := filepath.Join(t.TempDir(), "config")
+    ioutil.WriteFile(filename, []byte("[default]\nregion = ap-southeast-2\n"), 0600)
+    t.Setenv("AWS_CONFIG_FILE", filename)
+    t.Setenv("AWS_PROFILE", "")
+    t.Setenv("AWS_REGION", "")
+    t.Setenv("AWS_DEFAULT_REGION", "")
+    if r := DefaultRegion(); r != "ap-southeast-2" {
+        t.Fatalf("Expected the region from the config file. Got %s", r)
+    }
+    t.Setenv("AWS_REGION", "us-west-1")
+    if r := DefaultRegion(); r != "us-west-1" {
+        t.Fatalf("Expected AWS_REGION. Got %s", r)
+    }
+}
@@ -36,9 +36,12 @@ import (
     "errors"
     "fmt"
     "io"
+    "io/ioutil"
+    "net"
     "net/http"
     "net/url"
     "os"
+    "strings"
     "sync"
     "time"
 )
@@ -52,35 +55,36 @@ type Credentials struct {
     expiration time.Time
 }
 
+// Creates credentials with a session token, as handed out by STS or the metadata service.
+// A zero expiration means the credentials never expire.
+func NewCredentials(accessKeyId, secretAccessKey, token string, expiration time.Time) Credentials {
+    return Credentials{
+        AccessKeyId: accessKeyId,
+        SecretAccessKey: secretAccessKey,
+        token: token,
+        expiration: expiration,
+    }
+}
+
 func (c Credentials) GetToken() string {
     return c.token
 }
 
-var cachedCredentials Credentials
+// When these credentials expire. Zero if they never do.
+func (c Credentials) GetExpiration() time.Time {
+    return c.expiration
+}
+
+var defaultCredentialsCache = NewCredentialsCache(NewDefaultCredentialsChain())
 var credentialLock sync.Mutex
 
-func determineSecurityRole() (string, error) {
-    metaDataUrl := "http://169.254.169.254/latest/meta-data/iam/security-credentials"
-    metaDataUri, _ := url.Parse(metaDataUrl)
-    hreq := http.Request {
-        URL: metaDataUri,
-        Method: "GET",
-        ProtoMajor: 1,
-        ProtoMinor: 1,
-        Close: true,
-    }
-    resp, err := http.DefaultClient.Do(&hreq)
+func determineSecurityRole(client *MetadataClient) (string, error) {
+    role, err := client.Get("/meta-data/iam/security-credentials/")
     if err != nil {
-        return "", errors.New("Failed to create HTTP Client")
+        return "", err
     }
-    defer resp.Body.Close()
-    buf := bytes.NewBuffer(make([]byte, 0))
-    io.Copy(buf, resp.Body)
-
-    if resp.StatusCode != 200 {
-        return "", errors.New(fmt.Sprintf("Got Status code: %d", resp.StatusCode))
-    }
-    return buf.String(), nil
+    // the first line is the role attached to the instance
+    return strings.TrimSpace(strings.SplitN(role, "\n", 2)[0]), nil
 }
 
 type CredentialMetaData struct {
@@ -93,86 +97,180 @@ type CredentialMetaData struct {
     Expiration string
 }
 
-func credentialsAreLocal() bool {
-    f, err := os.Open("awskeys.json")
+// Gets credentials for the EC2 Security Role from the instance metadata service.
+type InstanceMetadataCredentialsProvider struct {
+    // The client to use. DefaultMetadataClient if nil
+    Client          *MetadataClient
+}
+
+func (p InstanceMetadataCredentialsProvider) Retrieve() (Credentials, error) {
+    client := p.Client
+    if client == nil {
+        client = DefaultMetadataClient
+    }
+    role, err := determineSecurityRole(client)
     if err != nil {
-        return false
+        return Credentials{}, err
     }
-    defer f.Close()
-    buf := bytes.NewBuffer(make([]byte, 0))
-    io.Copy(buf, f)
-    var tmp Credentials
-    if err = json.Unmarshal([]byte(buf.String()), &tmp); err != nil {
-        return false
+    resp, err := client.Get("/meta-data/iam/security-credentials/" + role)
+    if err != nil {
+        return Credentials{}, err
+    }
+
+    var credentials CredentialMetaData
+    if err = json.Unmarshal([]byte(resp), &credentials); err != nil {
+        return Credentials{}, err
+    }
+
+    if credentials.Code != "Success" {
+        return Credentials{}, errors.New("Failed to get security keys")
     }
-    if tmp.AccessKeyId == "" || tmp.SecretAccessKey == "" {
+
+    expiration, _ := time.Parse("2006-01-02T15:04:05Z", credentials.Expiration)
+    return NewCredentials(credentials.AccessKeyId, credentials.SecretAccessKey, credentials.Token, expiration), nil
+}
+
+const (
+    // Where ECS serves AWS_CONTAINER_CREDENTIALS_RELATIVE_URI from
+    DefaultContainerCredentialsEndpoint = "http://169.254.170.2"
+)
+
+var (
+    Credentials_Error_ContainerHostNotAllowed = errors.New("AWS_CONTAINER_CREDENTIALS_FULL_URI must use https, or a loopback or container metadata host")
+)
+
+// Gets credentials from the ECS / EKS container credentials endpoint.
+// The endpoint is found via AWS_CONTAINER_CREDENTIALS_RELATIVE_URI, or AWS_CONTAINER_CREDENTIALS_FULL_URI.
+// The response is the same json as CredentialMetaData.
+type ContainerCredentialsProvider struct {
+    // Defaults to AWS_CONTAINER_CREDENTIALS_RELATIVE_URI, relative to DefaultContainerCredentialsEndpoint
+    RelativeUri         string
+    // Used if there is no RelativeUri. Defaults to AWS_CONTAINER_CREDENTIALS_FULL_URI
+    FullUri             string
+    // Sent as the Authorization header. Defaults to AWS_CONTAINER_AUTHORIZATION_TOKEN,
+    // or the contents of AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE
+    AuthorizationToken  string
+    // Defaults to a client with a 5 second timeout
+    HttpClient          *http.Client
+}
+
+func (p ContainerCredentialsProvider) Retrieve() (Credentials, error) {
+    endpoint, err := p.endpoint()
+    if err != nil {
+        return Credentials{}, err
+    }
+    token, err := p.authorizationToken()
+    if err != nil {
+        return Credentials{}, err
+    }
+    hreq := http.Request {
+        URL: endpoint,
+        Method: "GET",
+        ProtoMajor: 1,
+        ProtoMinor: 1,
+        Header: http.Header{},
+    }
+    if token != "" {
+        hreq.Header.Set("Authorization", token)
+    }
+    httpClient := p.HttpClient
+    if httpClient == nil {
+        httpClient = defaultMetadataHttpClient
+    }
+    resp, err := httpClient.Do(&hreq)
+    if err != nil {
+        return Credentials{}, err
+    }
+    defer resp.Body.Close()
+    buf := bytes.Buffer{}
+    if _, err = io.Copy(&buf, resp.Body); err != nil {
+        return Credentials{}, err
+    }
+    if resp.StatusCode != 200 {
+        return Credentials{}, fmt.Errorf("Container credentials endpoint returned %d: %s", resp.StatusCode, buf.String())
+    }
+
+    var credentials CredentialMetaData
+    if err = json.Unmarshal(buf.Bytes(), &credentials); err != nil {
+        return Credentials{}, err
+    }
+    if credentials.AccessKeyId == "" || credentials.SecretAccessKey == "" {
+        return Credentials{}, errors.New("Container credentials endpoint returned no keys")
+    }
+    expiration, _ := time.Parse(time.RFC3339, credentials.Expiration)
+    return NewCredentials(credentials.AccessKeyId, credentials.SecretAccessKey, credentials.Token, expiration), nil
+}
+
+func (p ContainerCredentialsProvider) endpoint() (*url.URL, error) {
+    relative := p.RelativeUri
+    full := p.FullUri
+    if relative == "" && full == "" {
+        relative = os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+        full = os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+    }
+    if relative != "" {
+        return url.Parse(DefaultContainerCredentialsEndpoint + relative)
+    }
+    if full == "" {
+        return nil, Credentials_Error_EnvNotSet
+    }
+    u, err := url.Parse(full)
+    if err != nil {
+        return nil, err
+    }
+    // the credentials are sent in the clear over http, so it must stay on the host
+    if u.Scheme != "https" && !isContainerCredentialsHost(u.Hostname()) {
+        return nil, Credentials_Error_ContainerHostNotAllowed
+    }
+    return u, nil
+}
+
+func isContainerCredentialsHost(host string) bool {
+    if host == "localhost" {
+        return true
+    }
+    ip := net.ParseIP(host)
+    if ip == nil {
         return false
     }
-    // expire in 10 hours
-    tmp.expiration = time.Now().Add(time.Hour * 10)
-    cachedCredentials = tmp
-    return true
+    // ECS, and EKS pod identity
+    return ip.IsLoopback() || ip.Equal(net.ParseIP("169.254.170.2")) || ip.Equal(net.ParseIP("169.254.170.23")) ||
+        ip.Equal(net.ParseIP("fd00:ec2::23"))
 }
 
-/** Returns security credentials either from a JSON file 'awskeys.json' or
- * from AWS Metadata service
- * @return credentials, error
- */
-func GetSecurityKeys() (finalCred Credentials, err error)  {
-    if cachedCredentials.AccessKeyId == "" || cachedCredentials.expiration.Unix() < time.Now().Unix() {
-        credentialLock.Lock()
-        defer credentialLock.Unlock()
-        if cachedCredentials.AccessKeyId == "" || cachedCredentials.expiration.Unix() < time.Now().Unix()  {
-            if !credentialsAreLocal() {
-                var role string
-                role, err = determineSecurityRole()
-                if err != nil {
-                    return
-                }
-                credentialUrl := fmt.Sprintf("http://169.254.169.254/latest/meta-data/iam/security-credentials/%s", role)
-                credentialUri, _ := url.Parse(credentialUrl)
-                hreq := http.Request {
-                    URL: credentialUri,
-                    Method: "GET",
-                    ProtoMajor: 1,
-                    ProtoMinor: 1,
-                    Close: true,
-                }
-                resp, err2 := http.DefaultClient.Do(&hreq)
-                if err2 != nil {
-                    err = errors.New("Failed to create HTTP Client")
-                    return
-                }
-                defer resp.Body.Close()
-
-                buf := bytes.NewBuffer(make([]byte, 0))
-                io.Copy(buf, resp.Body)
-
-                if resp.StatusCode != 200 {
-                    err = errors.New(fmt.Sprintf("Got Status code: %d", resp.StatusCode))
-                    return
-                }
-
-                var credentials CredentialMetaData
-                if err = json.Unmarshal([]byte(buf.String()), &credentials); err != nil {
-                    return
-                }
-
-                if credentials.Code != "Success" {
-                    err = errors.New("Failed to get security keys")
-                    return
-                }
-
-                var tmp Credentials
-                tmp.AccessKeyId = credentials.AccessKeyId
-                tmp.SecretAccessKey = credentials.SecretAccessKey
-                tmp.expiration, _ = time.Parse("2006-01-02T15:04:05Z", credentials.Expiration)
-                tmp.token = credentials.Token
-                cachedCredentials = tmp
-            }
+func (p ContainerCredentialsProvider) authorizationToken() (string, error) {
+    if p.AuthorizationToken != "" {
+        return p.AuthorizationToken, nil
+    }
+    // the file is re-read each time, as it gets rotated
+    if filename := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); filename != "" {
+        token, err := ioutil.ReadFile(filename)
+        if err != nil {
+            return "", err
         }
+        return strings.TrimSpace(string(token)), nil
     }
-    finalCred = cachedCredentials
-    err = nil
-    return 
+    return os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"), nil
+}
+
+/** Returns security credentials from the default CredentialsProvider.
+ * Unless changed with SetDefaultCredentialsProvider, that is the chain from NewDefaultCredentialsChain:
+ * environment variables, ~/.aws/credentials, 'awskeys.json', the container credentials endpoint, then the AWS Metadata service
+ * The credentials are cached, and renewed before they expire.
+ * @return credentials, error
+ */
+func GetSecurityKeys() (Credentials, error)  {
+    return getDefaultCredentialsCache().Retrieve()
+}
+
+// Throws away the credentials cached by GetSecurityKeys.
+// Call this if a request fails with ExpiredToken, or similar.
+func InvalidateSecurityKeys() {
+    getDefaultCredentialsCache().Invalidate()
+}
+
+func getDefaultCredentialsCache() *CredentialsCache {
+    credentialLock.Lock()
+    defer credentialLock.Unlock()
+    return defaultCredentialsCache
 }
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "sync"
+    "time"
+)
+
+const (
+    DefaultCredentialsExpiryWindow = 5 * time.Minute
+)
+
+// Implemented by providers that cache, so stale credentials can be thrown away.
+type CredentialsInvalidator interface {
+    // Forget any cached credentials. The next Retrieve will fetch new ones.
+    Invalidate()
+}
+
+// Caches the credentials from another provider, renewing them before they expire.
+//
+// Once the credentials are within ExpiryWindow of expiring, Retrieve keeps returning them
+// while new ones are fetched in the background. If they get within a fifth of ExpiryWindow
+// of expiring (or have already expired) Retrieve blocks until new ones are fetched.
+// Safe for concurrent use.
+type CredentialsCache struct {
+    Provider        CredentialsProvider
+    // How long before expiry to start renewing. Defaults to 5 minutes.
+    ExpiryWindow    time.Duration
+
+    lock            sync.Mutex
+    creds           Credentials
+    refreshing      bool
+    // bumped by Invalidate, so a background refresh started before it is thrown away
+    generation      uint64
+}
+
+// Creates a cache around the given provider, with the default expiry window.
+func NewCredentialsCache(provider CredentialsProvider) *CredentialsCache {
+    return &CredentialsCache{
+        Provider: provider,
+        ExpiryWindow: DefaultCredentialsExpiryWindow,
+    }
+}
+
+func (c *CredentialsCache) Retrieve() (Credentials, error) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    window := c.ExpiryWindow
+    if window <= 0 {
+        window = DefaultCredentialsExpiryWindow
+    }
+    creds := c.creds
+    now := time.Now()
+    if creds.AccessKeyId != "" {
+        if creds.expiration.IsZero() || now.Add(window).Before(creds.expiration) {
+            return creds, nil
+        }
+        if now.Add(window / 5).Before(creds.expiration) {
+            if !c.refreshing {
+                c.refreshing = true
+                go c.refreshInBackground(c.generation)
+            }
+            return creds, nil
+        }
+    }
+    // nothing usable; hold the lock so everyone waits on this one fetch
+    creds, err := c.Provider.Retrieve()
+    if err != nil {
+        return Credentials{}, err
+    }
+    c.creds = creds
+    return creds, nil
+}
+
+func (c *CredentialsCache) refreshInBackground(generation uint64) {
+    creds, err := c.Provider.Retrieve()
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    if generation != c.generation {
+        // invalidated while we were fetching; these may be the credentials that were rejected
+        return
+    }
+    c.refreshing = false
+    // on error keep what we have. The next Retrieve will try again, or block once it is too late.
+    if err == nil {
+        c.creds = creds
+    }
+}
+
+// Throws away the cached credentials, and invalidates the underlying provider if it caches too.
+func (c *CredentialsCache) Invalidate() {
+    c.lock.Lock()
+    c.creds = Credentials{}
+    c.refreshing = false
+    c.generation ++
+    c.lock.Unlock()
+    if inv, ok := c.Provider.(CredentialsInvalidator); ok {
+        inv.Invalidate()
+    }
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// hands out credentials numbered by how many times it has been called
+type countingProvider struct {
+    calls       int
+    lifetime    time.Duration
+}
+
+func (p *countingProvider) Retrieve() (Credentials, error) {
+    p.calls ++
+    var expiration time.Time
+    if p.lifetime != 0 {
+        expiration = time.Now().Add(p.lifetime)
+    }
+    return NewCredentials(fmt.Sprintf("akey%d", p.calls), "skey", fmt.Sprintf("token%d", p.calls), expiration), nil
+}
+
+func Test_CredentialsCacheReusesCredentials(t *testing.T) {
+    provider := &countingProvider{lifetime: time.Hour}
+    cache := NewCredentialsCache(provider)
+    for i := 0; i < 3; i++ {
+        creds, err := cache.Retrieve()
+        if err != nil {
+            t.Fatalf("Error should be nil. Got: %v", err)
+        }
+        if creds.AccessKeyId != "akey1" {
+            t.Fatalf("Expected akey1. Got %s", creds.AccessKeyId)
+        }
+    }
+    if provider.calls != 1 {
+        t.Fatalf("Expected 1 call to the provider. Got %d", provider.calls)
+    }
+}
+
+func Test_CredentialsCacheRefreshesBeforeExpiry(t *testing.T) {
+    // always inside the window, and too close to expiry to refresh in the background
+    provider := &countingProvider{lifetime: 30 * time.Second}
+    cache := NewCredentialsCache(provider)
+    cache.Retrieve()
+    creds, err := cache.Retrieve()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if creds.AccessKeyId != "akey2" {
+        t.Fatalf("Expected akey2. Got %s", creds.AccessKeyId)
+    }
+}
+
+func Test_CredentialsCacheInvalidate(t *testing.T) {
+    provider := &countingProvider{}
+    cache := NewCredentialsCache(provider)
+    cache.Retrieve()
+    cache.Invalidate()
+    creds, _ := cache.Retrieve()
+    if creds.AccessKeyId != "akey2" {
+        t.Fatalf("Expected akey2. Got %s", creds.AccessKeyId)
+    }
+}
+
+func Test_CredentialsCacheInvalidateDropsBackgroundRefresh(t *testing.T) {
+    provider := &countingProvider{lifetime: time.Hour}
+    cache := NewCredentialsCache(provider)
+    cache.Retrieve()
+
+    // a refresh that started before Invalidate finishes after it
+    generation := cache.generation
+    cache.Invalidate()
+    cache.refreshInBackground(generation)
+    if cache.creds.AccessKeyId != "" {
+        t.Fatalf("A stale refresh restored credentials: %s", cache.creds.AccessKeyId)
+    }
+    creds, _ := cache.Retrieve()
+    if creds.AccessKeyId != "akey3" {
+        t.Fatalf("Expected akey3. Got %s", creds.AccessKeyId)
+    }
+
+    // a refresh from the current generation is kept
+    cache.refreshing = true
+    cache.refreshInBackground(cache.generation)
+    if cache.creds.AccessKeyId != "akey4" || cache.refreshing {
+        t.Fatalf("Expected akey4 with refreshing cleared. Got %s, %v", cache.creds.AccessKeyId, cache.refreshing)
+    }
+}
+
+type failingProvider struct {}
+
+func (p failingProvider) Retrieve() (Credentials, error) {
+    return Credentials{}, errors.New("nope")
+}
+
+func Test_CredentialsChainFallsThrough(t *testing.T) {
+    chain := CredentialsChain{failingProvider{}, StaticCredentialsProvider{Key: NewCredentials("akey", "skey", "", time.Time{})}}
+    creds, err := chain.Retrieve()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if creds.AccessKeyId != "akey" {
+        t.Fatalf("Expected akey. Got %s", creds.AccessKeyId)
+    }
+    if _, err = (CredentialsChain{failingProvider{}}).Retrieve(); err == nil {
+        t.Fatalf("Expected an error when every provider fails")
+    }
+}
+
+type echoRequest struct {
+    RequestBuilder
+}
+
+func (r *echoRequest) VerifyInput() error {
+    return nil
+}
+
+func (r echoRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
+    if statusCode != 200 {
+        return fmt.Errorf("%d: %s", statusCode, string(response))
+    }
+    return string(response)
+}
+
+func Test_ExpiredTokenRetriedWithNewCredentials(t *testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("x-amz-security-token") != "token2" {
+            http.Error(w, `{"__type":"com.amazon.coral.service#ExpiredTokenException","message":"The security token included in the request is expired"}`, 400)
+            return
+        }
+        fmt.Fprint(w, "ok")
+    })
+    ts := httptest.NewTLSServer(handler)
+    defer ts.Close()
+    certAsx509, _ := x509.ParseCertificate(ts.TLS.Certificates[0].Certificate[0])
+
+    provider := &countingProvider{lifetime: time.Hour}
+    req := new(echoRequest)
+    req.Host.Service = "dynamodb"
+    req.Host.Region = "us-east-1"
+    req.Host.Override = strings.TrimPrefix(ts.URL, "https://")
+    req.Headers = make(map[string]string)
+    req.RequestMethod = "POST"
+    req.CanonicalUri = "/"
+    req.HttpClient = CreateCertApprovedClient([]*x509.Certificate{certAsx509})
+    req.CredentialsProvider = NewCredentialsCache(provider)
+
+    request, err := NewAwsRequest(req, map[string]string{})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    request.RequestSigningType = RequestSigningType_AWS4
+    resp, err := request.DoAndDemarshall(req)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.(string) != "ok" {
+        t.Fatalf("Expected ok. Got %v", resp)
+    }
+    if provider.calls != 2 {
+        t.Fatalf("Expected 2 calls to the provider. Got %d", provider.calls)
+    }
+}
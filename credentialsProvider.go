@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+var (
+    Credentials_Error_EnvNotSet = errors.New("AWS_ACCESS_KEY_ID or AWS_SECRET_ACCESS_KEY not set")
+    Credentials_Error_NoHomeDir = errors.New("Could not find the home directory for the shared credentials file")
+)
+
+// Something that can supply credentials for a request.
+type CredentialsProvider interface {
+    // Get the credentials. Called each time credentials are needed, so implementations may want to cache.
+    Retrieve() (Credentials, error)
+}
+
+// Sets the CredentialsProvider used by GetSecurityKeys, and so by every request
+// that doesn't specify its own Key or CredentialsProvider.
+// The provider is wrapped in a CredentialsCache, unless it already is one.
+func SetDefaultCredentialsProvider(provider CredentialsProvider) {
+    cache, ok := provider.(*CredentialsCache)
+    if !ok {
+        cache = NewCredentialsCache(provider)
+    }
+    credentialLock.Lock()
+    defer credentialLock.Unlock()
+    defaultCredentialsCache = cache
+}
+
+// Creates the default chain:
+//      1. AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables
+//      2. ~/.aws/credentials, using the profile in AWS_PROFILE or 'default'
+//      3. 'awskeys.json' in the working directory
+//      4. The container credentials endpoint, if AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or _FULL_URI is set
+//      5. The EC2 Security Role from the metadata service
+func NewDefaultCredentialsChain() CredentialsChain {
+    return CredentialsChain{
+        EnvCredentialsProvider{},
+        SharedCredentialsProvider{},
+        FileCredentialsProvider{},
+        ContainerCredentialsProvider{},
+        InstanceMetadataCredentialsProvider{},
+    }
+}
+
+// Always returns the same credentials.
+type StaticCredentialsProvider struct {
+    Key         Credentials
+}
+
+func (p StaticCredentialsProvider) Retrieve() (Credentials, error) {
+    if p.Key.AccessKeyId == "" {
+        return Credentials{}, Verification_Error_AccessKeyEmpty
+    }
+    if p.Key.SecretAccessKey == "" {
+        return Credentials{}, Verification_Error_SecretAccessKeyEmpty
+    }
+    return p.Key, nil
+}
+
+// Returned when no provider in a chain could supply credentials.
+// Holds the error from each provider, in order.
+type CredentialsChainError struct {
+    Errors      []error
+}
+
+func (e CredentialsChainError) Error() string {
+    msgs := make([]string, len(e.Errors))
+    for i := range e.Errors {
+        msgs[i] = e.Errors[i].Error()
+    }
+    return fmt.Sprintf("No credentials found: [%s]", strings.Join(msgs, "; "))
+}
+
+// Tries each provider in order, returning the first credentials found.
+type CredentialsChain []CredentialsProvider
+
+func (c CredentialsChain) Retrieve() (Credentials, error) {
+    errs := make([]error, 0, len(c))
+    for i := range c {
+        creds, err := c[i].Retrieve()
+        if err == nil {
+            return creds, nil
+        }
+        errs = append(errs, err)
+    }
+    return Credentials{}, CredentialsChainError{Errors: errs}
+}
+
+// Reads credentials from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables. AWS_ACCESS_KEY and AWS_SECRET_KEY are also accepted.
+type EnvCredentialsProvider struct {}
+
+func (p EnvCredentialsProvider) Retrieve() (Credentials, error) {
+    id := os.Getenv("AWS_ACCESS_KEY_ID")
+    if id == "" {
+        id = os.Getenv("AWS_ACCESS_KEY")
+    }
+    secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
+    if secret == "" {
+        secret = os.Getenv("AWS_SECRET_KEY")
+    }
+    if id == "" || secret == "" {
+        return Credentials{}, Credentials_Error_EnvNotSet
+    }
+    return NewCredentials(id, secret, os.Getenv("AWS_SESSION_TOKEN"), time.Time{}), nil
+}
+
+// Reads credentials from the shared credentials file used by the AWS cli.
+type SharedCredentialsProvider struct {
+    // Path to the file. Defaults to AWS_SHARED_CREDENTIALS_FILE, or ~/.aws/credentials
+    Filename        string
+    // Which profile to use. Defaults to AWS_PROFILE, or 'default'
+    Profile         string
+}
+
+func (p SharedCredentialsProvider) Retrieve() (Credentials, error) {
+    filename, err := sharedFilename(p.Filename, "AWS_SHARED_CREDENTIALS_FILE", "credentials")
+    if err != nil {
+        return Credentials{}, err
+    }
+    profile := profileName(p.Profile)
+    sections, err := parseIniFile(filename)
+    if err != nil {
+        return Credentials{}, err
+    }
+    section, ok := sections[profile]
+    if !ok {
+        return Credentials{}, fmt.Errorf("Profile '%s' not found in %s", profile, filename)
+    }
+    if section["aws_access_key_id"] == "" || section["aws_secret_access_key"] == "" {
+        return Credentials{}, fmt.Errorf("Profile '%s' in %s is missing aws_access_key_id or aws_secret_access_key", profile, filename)
+    }
+    return NewCredentials(section["aws_access_key_id"], section["aws_secret_access_key"], section["aws_session_token"], time.Time{}), nil
+}
+
+// Reads credentials from a json file that marshalls to awsgo.Credentials.
+// The credentials are re-read every 10 hours.
+type FileCredentialsProvider struct {
+    // Defaults to 'awskeys.json' in the working directory
+    Filename        string
+}
+
+func (p FileCredentialsProvider) Retrieve() (Credentials, error) {
+    filename := p.Filename
+    if filename == "" {
+        filename = "awskeys.json"
+    }
+    f, err := os.Open(filename)
+    if err != nil {
+        return Credentials{}, err
+    }
+    defer f.Close()
+    buf := bytes.NewBuffer(make([]byte, 0))
+    io.Copy(buf, f)
+    var tmp Credentials
+    if err = json.Unmarshal([]byte(buf.String()), &tmp); err != nil {
+        return Credentials{}, err
+    }
+    if tmp.AccessKeyId == "" {
+        return Credentials{}, Verification_Error_AccessKeyEmpty
+    }
+    if tmp.SecretAccessKey == "" {
+        return Credentials{}, Verification_Error_SecretAccessKeyEmpty
+    }
+    // expire in 10 hours
+    tmp.expiration = time.Now().Add(time.Hour * 10)
+    return tmp, nil
+}
+
+// Reads a profile from the shared config file used by the AWS cli.
+// filename defaults to AWS_CONFIG_FILE, or ~/.aws/config. profile defaults to AWS_PROFILE, or 'default'.
+// Profiles other than 'default' are looked up under '[profile name]', then '[name]'.
+func LoadSharedConfigProfile(filename, profile string) (map[string]string, error) {
+    filename, err := sharedFilename(filename, "AWS_CONFIG_FILE", "config")
+    if err != nil {
+        return nil, err
+    }
+    profile = profileName(profile)
+    sections, err := parseIniFile(filename)
+    if err != nil {
+        return nil, err
+    }
+    if section, ok := sections["profile " + profile]; ok {
+        return section, nil
+    }
+    if section, ok := sections[profile]; ok {
+        return section, nil
+    }
+    return nil, fmt.Errorf("Profile '%s' not found in %s", profile, filename)
+}
+
+// Works out the path of one of the files in ~/.aws
+func sharedFilename(filename, envName, defaultName string) (string, error) {
+    if filename != "" {
+        return filename, nil
+    }
+    if v := os.Getenv(envName); v != "" {
+        return v, nil
+    }
+    home, err := os.UserHomeDir()
+    if err != nil || home == "" {
+        return "", Credentials_Error_NoHomeDir
+    }
+    return filepath.Join(home, ".aws", defaultName), nil
+}
+
+func profileName(profile string) string {
+    if profile != "" {
+        return profile
+    }
+    if v := os.Getenv("AWS_PROFILE"); v != "" {
+        return v
+    }
+    return "default"
+}
+
+// Parses the ini style files in ~/.aws into section -> key -> value.
+// Keys are lower cased, values are trimmed.
+func parseIniFile(filename string) (map[string]map[string]string, error) {
+    f, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    sections := make(map[string]map[string]string)
+    var current map[string]string
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+            continue
+        }
+        if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+            name := strings.TrimSpace(line[1:len(line) - 1])
+            current = sections[name]
+            if current == nil {
+                current = make(map[string]string)
+                sections[name] = current
+            }
+            continue
+        }
+        eq := strings.Index(line, "=")
+        if eq < 0 || current == nil {
+            continue
+        }
+        key := strings.ToLower(strings.TrimSpace(line[:eq]))
+        current[key] = strings.TrimSpace(line[eq + 1:])
+    }
+    if err = scanner.Err(); err != nil {
+        return nil, err
+    }
+    return sections, nil
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// clears every variable the env and shared file providers look at
+func clearCredentialsEnv(t *testing.T) {
+    for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY",
+            "AWS_SECRET_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE"} {
+        t.Setenv(name, "")
+    }
+}
+
+const testCredentialsFile = `
+# the default profile
+[default]
+aws_access_key_id = defaultKey
+aws_secret_access_key=defaultSecret
+
+  ; indented comment
+  [ work ]
+    AWS_ACCESS_KEY_ID   =   workKey  
+    aws_secret_access_key = work/Secret=with=equals
+    aws_session_token = workToken
+
+[broken]
+aws_access_key_id = brokenKey
+`
+
+func writeCredentialsFile(t *testing.T) string {
+    filename := filepath.Join(t.TempDir(), "credentials")
+    if err := os.WriteFile(filename, []byte(testCredentialsFile), 0600); err != nil {
+        t.Fatal(err)
+    }
+    return filename
+}
+
+func Test_EnvCredentialsProvider(t *testing.T) {
+    tests := []struct {
+        name        string
+        env         map[string]string
+        key         string
+        secret      string
+        token       string
+        err         error
+    }{
+        {"standard names", map[string]string{"AWS_ACCESS_KEY_ID": "akey", "AWS_SECRET_ACCESS_KEY": "skey"}, "akey", "skey", "", nil},
+        {"session token", map[string]string{"AWS_ACCESS_KEY_ID": "akey", "AWS_SECRET_ACCESS_KEY": "skey", "AWS_SESSION_TOKEN": "tok"}, "akey", "skey", "tok", nil},
+        {"old names", map[string]string{"AWS_ACCESS_KEY": "akey", "AWS_SECRET_KEY": "skey"}, "akey", "skey", "", nil},
+        {"standard names win", map[string]string{"AWS_ACCESS_KEY_ID": "akey", "AWS_ACCESS_KEY": "old", "AWS_SECRET_ACCESS_KEY": "skey"}, "akey", "skey", "", nil},
+        {"missing secret", map[string]string{"AWS_ACCESS_KEY_ID": "akey"}, "", "", "", Credentials_Error_EnvNotSet},
+        {"nothing set", nil, "", "", "", Credentials_Error_EnvNotSet},
+    }
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            clearCredentialsEnv(t)
+            for k, v := range test.env {
+                t.Setenv(k, v)
+            }
+            creds, err := EnvCredentialsProvider{}.Retrieve()
+            if err != test.err {
+                t.Fatalf("Expected error %v. Got %v", test.err, err)
+            }
+            if creds.AccessKeyId != test.key || creds.SecretAccessKey != test.secret || creds.token != test.token {
+                t.Fatalf("Unexpected credentials: %+v", creds)
+            }
+        })
+    }
+}
+
+func Test_SharedCredentialsProvider(t *testing.T) {
+    filename := writeCredentialsFile(t)
+    tests := []struct {
+        name        string
+        provider    SharedCredentialsProvider
+        env         map[string]string
+        key         string
+        secret      string
+        token       string
+        errContains string
+    }{
+        {"default profile", SharedCredentialsProvider{Filename: filename}, nil, "defaultKey", "defaultSecret", "", ""},
+        {"AWS_PROFILE", SharedCredentialsProvider{Filename: filename}, map[string]string{"AWS_PROFILE": "work"}, "workKey", "work/Secret=with=equals", "workToken", ""},
+        {"Profile beats AWS_PROFILE", SharedCredentialsProvider{Filename: filename, Profile: "default"}, map[string]string{"AWS_PROFILE": "work"}, "defaultKey", "defaultSecret", "", ""},
+        {"AWS_SHARED_CREDENTIALS_FILE", SharedCredentialsProvider{Profile: "work"}, map[string]string{"AWS_SHARED_CREDENTIALS_FILE": filename}, "workKey", "work/Secret=with=equals", "workToken", ""},
+        {"missing profile", SharedCredentialsProvider{Filename: filename, Profile: "nope"}, nil, "", "", "", "Profile 'nope' not found"},
+        {"missing secret", SharedCredentialsProvider{Filename: filename, Profile: "broken"}, nil, "", "", "", "missing aws_access_key_id or aws_secret_access_key"},
+    }
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            clearCredentialsEnv(t)
+            for k, v := range test.env {
+                t.Setenv(k, v)
+            }
+            creds, err := test.provider.Retrieve()
+            if test.errContains != "" {
+                if err == nil || !strings.Contains(err.Error(), test.errContains) {
+                    t.Fatalf("Expected error containing '%s'. Got %v", test.errContains, err)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Error should be nil. Got: %v", err)
+            }
+            if creds.AccessKeyId != test.key || creds.SecretAccessKey != test.secret || creds.token != test.token {
+                t.Fatalf("Unexpected credentials: %+v", creds)
+            }
+        })
+    }
+
+    clearCredentialsEnv(t)
+    _, err := SharedCredentialsProvider{Filename: filepath.Join(t.TempDir(), "missing")}.Retrieve()
+    if !errors.Is(err, os.ErrNotExist) {
+        t.Fatalf("Expected a not exist error for a missing file. Got %v", err)
+    }
+}
+
+func Test_ParseIniFile(t *testing.T) {
+    sections, err := parseIniFile(writeCredentialsFile(t))
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if len(sections) != 3 {
+        t.Fatalf("Expected 3 sections. Got %v", sections)
+    }
+    work := sections["work"]
+    if work["aws_access_key_id"] != "workKey" {
+        t.Errorf("Key should be lower cased and value trimmed. Got %v", work)
+    }
+    if work["aws_secret_access_key"] != "work/Secret=with=equals" {
+        t.Errorf("Only the first = should split. Got %v", work)
+    }
+    for name, section := range sections {
+        for k := range section {
+            if strings.HasPrefix(k, "#") || strings.HasPrefix(k, ";") {
+                t.Errorf("Comment parsed as a key in [%s]: %s", name, k)
+            }
+        }
+    }
+}
+
+func Test_CredentialsChainOrder(t *testing.T) {
+    filename := writeCredentialsFile(t)
+    clearCredentialsEnv(t)
+    t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filename)
+
+    chain := NewDefaultCredentialsChain()
+    if _, ok := chain[0].(EnvCredentialsProvider); !ok {
+        t.Fatalf("The environment should be checked first. Got %T", chain[0])
+    }
+    if _, ok := chain[1].(SharedCredentialsProvider); !ok {
+        t.Fatalf("The shared credentials file should be checked second. Got %T", chain[1])
+    }
+
+    // only the file is set, so it is used
+    creds, err := chain.Retrieve()
+    if err != nil || creds.AccessKeyId != "defaultKey" {
+        t.Fatalf("Expected the shared file credentials. Got %+v, %v", creds, err)
+    }
+    // the environment wins over the file
+    t.Setenv("AWS_ACCESS_KEY_ID", "envKey")
+    t.Setenv("AWS_SECRET_ACCESS_KEY", "envSecret")
+    creds, err = chain.Retrieve()
+    if err != nil || creds.AccessKeyId != "envKey" {
+        t.Fatalf("Expected the environment credentials. Got %+v, %v", creds, err)
+    }
+
+    // every error is kept, in order
+    clearCredentialsEnv(t)
+    _, err = CredentialsChain{EnvCredentialsProvider{}, SharedCredentialsProvider{Filename: filename, Profile: "nope"}}.Retrieve()
+    var chainErr CredentialsChainError
+    if !errors.As(err, &chainErr) || len(chainErr.Errors) != 2 {
+        t.Fatalf("Expected a CredentialsChainError with 2 errors. Got %v", err)
+    }
+    if chainErr.Errors[0] != Credentials_Error_EnvNotSet || !strings.Contains(chainErr.Errors[1].Error(), "nope") {
+        t.Fatalf("Errors are out of order: %v", chainErr.Errors)
+    }
+}
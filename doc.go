@@ -11,17 +11,174 @@ you will only be using a few methods, and now the AwsRequest object directly.
 
 Credentials
 
-Awsgo supports two 'automatic' methods for getting your security keys.
+Awsgo supports several 'automatic' methods for getting your security keys. They are tried in order
+by the chain from NewDefaultCredentialsChain.
 
-The first is via a json file.
+The first is via the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and (optionally) AWS_SESSION_TOKEN
+environment variables.
+
+The second is via the shared credentials file used by the AWS cli, ~/.aws/credentials. The profile
+is taken from AWS_PROFILE, or 'default' if not set.
+
+The third is via a json file.
 THis is useful when testing locally. It expects a file named 'awskeys.json' to exist in your
 working directory. This json should marshall to awsgo.Credentials struct.
 
-The second is via the EC2 Security Role. This is done via a request to
-http://169.254.169.254/latest/meta-data/iam/security-credentials. The EC2 Security Role
-is only used if 'awskeys.json' does not exist.
+The fourth is via the container credentials endpoint used by ECS and EKS. It is only tried when
+AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or AWS_CONTAINER_CREDENTIALS_FULL_URI is set, and sends
+AWS_CONTAINER_AUTHORIZATION_TOKEN (or the contents of AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE)
+as the Authorization header.
+
+The last is via the EC2 Security Role. This is done via a request to
+http://169.254.169.254/latest/meta-data/iam/security-credentials.
+Metadata requests go through DefaultMetadataClient, which uses IMDSv2 session tokens. It falls
+back to unauthenticated IMDSv1 requests if a token can't be had; set AllowV1Fallback to false to
+forbid that.
 
 In order to use these 'automatic' methods of Key population, you should call awsgo.GetSecurityKeys()
+or leave Key empty on your request. To use something else, either set CredentialsProvider on the
+request, or replace the default chain with awsgo.SetDefaultCredentialsProvider.
+
+Credentials from GetSecurityKeys are held in a CredentialsCache, which renews them in the background
+before they expire. Wrap your own providers with NewCredentialsCache to get the same behaviour.
+If a request fails with ExpiredToken or InvalidClientTokenId, and its Key came from a provider, the
+provider is invalidated and the request is sent once more with fresh credentials.
+
+
+Config
+
+A Config holds the settings shared by many requests: region, CredentialsProvider, http client,
+RetryPolicy, EndpointResolver and logger. Each service package has a Client built from a Config,
+whose NewXxxRequest methods return requests with those settings already applied.
+
+    cfg := awsgo.NewDefaultConfig() // region from AWS_REGION, or ~/.aws/config
+    cfg.RetryPolicy = awsgo.DefaultRetryPolicy{MaxAttempts: 3}
+    db := dynamo.NewClient(cfg)
+    req := db.NewGetItemRequest()
+
+Requests created directly, eg. with dynamo.NewGetItemRequest(), work as before.
+
+
+Cancellation
+
+Every request type has a RequestWithContext(ctx) next to its Request() method. Once ctx is done
+the http call is aborted, any retry backoff stops, and ctx.Err() is returned.
+
+
+Retries
+
+Failed requests are retried according to the RetryPolicy on the RequestBuilder. If none is set,
+DefaultRetryPolicy is used, which retries throttling errors (Eg. ProvisionedThroughputExceededException,
+ThrottlingException, SlowDown), 5xx responses and temporary network errors using full jitter exponential
+backoff. Use awsgo.SetDefaultRetryPolicy to change the policy for every request.
+
+Streamed payloads can only be retried if they can be sent again: an io.ReadSeeker (Eg. an *os.File)
+is rewound for each attempt, and a BodyFactory is asked for a fresh body. Any other io.Reader is sent
+once, and not retried. For SigV4 the payload hash is worked out by streaming the body, not buffering it.
+
+Large s3 uploads can be signed chunk by chunk instead (RequestSigningType_AWS4_CHUNKED), so they are
+streamed with constant memory; set Chunked on a s3.PutObjectRequest.
+
+If a request fails because our clock is too far from AWS's (Eg. RequestTimeTooSkewed), the offset is
+worked out from the Date of the response and used when signing from then on, and the request is sent
+once more. See ClockSkew.
+
+
+Presigning
+
+Presign turns any request into a url with a SigV4 signature in the query string, that anyone can use
+until it expires. Eg. to hand a browser a link to a s3 object:
+
+    getObject := s3.NewGetObjectRequest()
+    getObject.Host.Region = "eu-central-1"
+    getObject.Path = "examplebucket/test.txt"
+    link, err := awsgo.Presign(getObject, 15 * time.Minute)
+
+
+Errors
+
+Errors sent back by a service implement APIError, whatever their wire format, giving the error code,
+message, status code, request id, and whether it is retryable or throttling. Use errors.As to get at
+one, or errors.Is with an ErrorCode:
+
+    var apiErr awsgo.APIError
+    if errors.As(err, &apiErr) {
+        log.Println(apiErr.ErrorCode(), apiErr.RequestID())
+    }
+    if errors.Is(err, awsgo.ErrorCode("ResourceNotFoundException")) {
+        // create it
+    }
+
+
+Handlers
+
+Each request goes through a stack of Handlers in stages: Build, Sign, Send, Unmarshal and Complete.
+NewHandlers returns the default stack; add your own handlers to it for tagging, custom headers,
+audit logging, response validation or fault injection. Handlers see the request as an Operation,
+and fail it by setting op.Error.
+
+    h := awsgo.NewHandlers()
+    h.Build.PushBack(awsgo.Handler{"myapp.Tag", func (op *awsgo.Operation) {
+        op.Request.Headers["x-amz-meta-team"] = "search"
+    }})
+    awsgo.SetDefaultHandlers(h) // or set it on a Config, or a single request
+
+
+Logging
+
+Set a *slog.Logger on a Config or request, or everywhere with SetDefaultLogger. Each request is logged
+at Info (Warn if it failed) with its operation, status, latency, attempts and request id. Retries are
+logged at Debug. At LogLevelWire the signed request and the response are dumped, with the
+Authorization header, security tokens and secret keys redacted.
+
+    handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: awsgo.LogLevelWire})
+    awsgo.SetDefaultLogger(slog.New(handler))
+
+
+Metrics
+
+A MetricsHook gets a RequestMetrics for every request: service, operation, status, error code, latency,
+attempts, throttles and, for DynamoDB, consumed capacity. Set one on a Config or request, or everywhere
+with SetDefaultMetricsHook. cloudwatch.NewMetricsPublisher is a MetricsHook that aggregates them and
+sends them to CloudWatch in batches.
+
+    publisher := cloudwatch.NewMetricsPublisher(awsgo.Config{Region: "us-west-2"}, "MyApp/AWS")
+    defer publisher.Close()
+    awsgo.SetDefaultMetricsHook(publisher)
+
+
+Tracing
+
+A Tracer starts a span for every request, as a child of any span in the context passed to
+DoWithContext. Spans are named service.operation, eg. dynamodb.GetItem, and carry the region,
+status code, request id and retries; DynamoDB requests add their table names and SQS requests their
+queue url. awsgo doesn't depend on a tracing SDK, so wrap yours in a Tracer, then set it on a Config
+or request, or everywhere with SetDefaultTracer. RecordingTracer keeps spans in memory for tests.
+
+    tracer := new(awsgo.RecordingTracer)
+    awsgo.SetDefaultTracer(tracer)
+
+
+Endpoints
+
+Where a request goes is decided by an EndpointResolver. DefaultEndpointResolver knows the China,
+GovCloud and ISO partitions, global services like IAM, S3's us-east-1 endpoint, and FIPS and
+dual-stack hostnames. Host.Override still wins for a single request.
+
+To point a service at a local emulator without touching each request:
+
+    awsgo.SetServiceEndpoint("dynamodb", "http://localhost:8000")
+
+or set AWS_ENDPOINT_URL_DYNAMODB (or AWS_ENDPOINT_URL, for every service).
+
+Testing
+
+The awsgotest package has an http.RoundTripper that records requests to fixture files, and
+replays them later without touching AWS. Use it with SetDefaultHttpClient, Config.HttpClient
+or RequestBuilder.HttpClient.
+
+    rec := awsgotest.New(t, "putItem") // records when AWSGO_RECORD=1
+    awsgo.SetDefaultHttpClient(rec.Client())
 
 
 */
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -164,12 +165,17 @@ func (gir BatchGetItemRequest) CoDoAndDemarshall(request awsgo.AwsRequest, futur
 }*/
 
 func (gir BatchGetItemRequest) Request() (*BatchGetItemResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir BatchGetItemRequest) RequestWithContext(ctx context.Context) (*BatchGetItemResponse, error) {
     request, err := awsgo.NewAwsRequest(&gir, gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -178,6 +184,11 @@ func (gir BatchGetItemRequest) Request() (*BatchGetItemResponse, error) {
 
 
 func (resp *BatchGetItemResponse) Next(lastRequest *BatchGetItemRequest) (*BatchGetItemResponse, error) {
+    return resp.NextWithContext(context.Background(), lastRequest)
+}
+
+// Same as Next, but the call is aborted once ctx is done.
+func (resp *BatchGetItemResponse) NextWithContext(ctx context.Context, lastRequest *BatchGetItemRequest) (*BatchGetItemResponse, error) {
     if len(resp.UnprocessedKeys) == 0 {
         return nil, nil
     }
@@ -188,5 +199,5 @@ func (resp *BatchGetItemResponse) Next(lastRequest *BatchGetItemRequest) (*Batch
     req.HttpClient = lastRequest.HttpClient
     req.Host.Region = lastRequest.Host.Region
     req.Key = lastRequest.Key
-    return req.Request()
+    return req.RequestWithContext(ctx)
 }
\ No newline at end of file
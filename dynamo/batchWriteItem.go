@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -202,12 +203,17 @@ func (gir * BatchWriteItemRequest) VerifyInput() (error) {
 }
 
 func (gir BatchWriteItemRequest) Request() (*BatchWriteItemResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir BatchWriteItemRequest) RequestWithContext(ctx context.Context) (*BatchWriteItemResponse, error) {
     request, err := awsgo.NewAwsRequest(&gir, gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -216,31 +222,40 @@ func (gir BatchWriteItemRequest) Request() (*BatchWriteItemResponse, error) {
 
 // makes the request, and tries to include the unprocessed request items in subsequent requests
 func (gir BatchWriteItemRequest) RequestIncludingUnprocessed(sleep time.Duration) (*BatchWriteItemResponse, error) {
+    return gir.RequestIncludingUnprocessedWithContext(context.Background(), sleep)
+}
+
+// Same as RequestIncludingUnprocessed, but stops retrying once ctx is done.
+func (gir BatchWriteItemRequest) RequestIncludingUnprocessedWithContext(ctx context.Context, sleep time.Duration) (*BatchWriteItemResponse, error) {
     headerCopy := make(map[string]string)
     for k, v := range gir.Headers {
         headerCopy[k] = v
     }
 
-    resp, err := gir.Request()
+    resp, err := gir.RequestWithContext(ctx)
     if err != nil {
         return resp, err
     }
     lastSize := len(resp.UnprocessedItems)
     for backOff := 0; len(resp.UnprocessedItems) > 0; {
         if lastSize == len(resp.UnprocessedItems) {
-            time.Sleep(sleep)
+            if err = awsgo.SleepWithContext(ctx, sleep); err != nil {
+                return resp, err
+            }
             backOff ++
         }
         lastSize = len(resp.UnprocessedItems)
         if backOff > 5 {
             return resp, BACKOFF_EXCEEDED
         }
-        time.Sleep(time.Duration(backOff * 100) * time.Millisecond)
+        if err = awsgo.SleepWithContext(ctx, time.Duration(backOff * 100) * time.Millisecond); err != nil {
+            return resp, err
+        }
 
         retryRequest := NewBatchWriteItemRequest()
         retryRequest.RequestBuilder = gir.deepCopyRequestBuilder(headerCopy)
         retryRequest.RequestItems = resp.UnprocessedItems
-        resp, err = retryRequest.Request()
+        resp, err = retryRequest.RequestWithContext(ctx)
         if err != nil {
             return resp, err
         }
@@ -255,6 +270,13 @@ func (gir BatchWriteItemRequest) deepCopyRequestBuilder(headerCopy map[string]st
     theCopy.Host.Domain = gir.Host.Domain
     theCopy.Host.Override = gir.Host.Override
     theCopy.HttpClient = gir.HttpClient
+    theCopy.CredentialsProvider = gir.CredentialsProvider
+    theCopy.RetryPolicy = gir.RetryPolicy
+    theCopy.EndpointResolver = gir.EndpointResolver
+    theCopy.Logger = gir.Logger
+    theCopy.Handlers = gir.Handlers
+    theCopy.MetricsHook = gir.MetricsHook
+    theCopy.Tracer = gir.Tracer
 
     theCopy.Key = gir.Key
     theCopy.Headers = make(map[string]string)
@@ -271,6 +293,11 @@ func (gir BatchWriteItemRequest) deepCopyRequestBuilder(headerCopy map[string]st
 // returns on the first error.
 // @param sleep - the amount of time to sleep between requests. 0 implies no added sleeping
 func (gir BatchWriteItemRequest) RequestSplit(sleep time.Duration) ([]*BatchWriteItemResponse, error) {
+    return gir.RequestSplitWithContext(context.Background(), sleep)
+}
+
+// Same as RequestSplit, but stops sending sub requests once ctx is done.
+func (gir BatchWriteItemRequest) RequestSplitWithContext(ctx context.Context, sleep time.Duration) ([]*BatchWriteItemResponse, error) {
 
     responses := make([]*BatchWriteItemResponse, 0, 10)
 
@@ -297,12 +324,14 @@ func (gir BatchWriteItemRequest) RequestSplit(sleep time.Duration) ([]*BatchWrit
             }
             itemsInSet ++
             if itemsInSet >= 25 {
-                resp, err := curSubRequest.RequestIncludingUnprocessed(sleep)
+                resp, err := curSubRequest.RequestIncludingUnprocessedWithContext(ctx, sleep)
                 if err != nil {
                     return responses, err
                 }
                 if sleep != 0 {
-                    time.Sleep(sleep)
+                    if err = awsgo.SleepWithContext(ctx, sleep); err != nil {
+                        return responses, err
+                    }
                 }
                 if len(responses) == cap(responses) {
                     newItems := make([]*BatchWriteItemResponse, len(responses) * 2)
@@ -319,7 +348,7 @@ func (gir BatchWriteItemRequest) RequestSplit(sleep time.Duration) ([]*BatchWrit
         }
     }
     if curSubRequest != nil {
-        resp, err := curSubRequest.RequestIncludingUnprocessed(sleep)
+        resp, err := curSubRequest.RequestIncludingUnprocessedWithContext(ctx, sleep)
         if err != nil {
             return responses, err
         }
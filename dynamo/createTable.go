@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "github.com/fromkeith/awsgo"
+)
+
+var (
+    Verification_Error_KeySchemaEmpty = errors.New("KeySchema cannot be empty")
+    Verification_Error_AttributeDefinitionsEmpty = errors.New("AttributeDefinitions cannot be empty")
+    Verification_Error_ProvisionedThroughputEmpty = errors.New("ProvisionedThroughput must be set unless BillingMode is PAY_PER_REQUEST")
+    Verification_Error_IndexProvisionedThroughputEmpty = errors.New("GlobalSecondaryIndexes need a ProvisionedThroughput unless BillingMode is PAY_PER_REQUEST")
+)
+
+const (
+    KeyType_HASH = "HASH"
+    KeyType_RANGE = "RANGE"
+
+    AttributeType_S = "S"
+    AttributeType_N = "N"
+    AttributeType_B = "B"
+
+    ProjectionType_ALL = "ALL"
+    ProjectionType_KEYS_ONLY = "KEYS_ONLY"
+    ProjectionType_INCLUDE = "INCLUDE"
+
+    BillingMode_PROVISIONED = "PROVISIONED"
+    BillingMode_PAY_PER_REQUEST = "PAY_PER_REQUEST"
+
+    StreamViewType_KEYS_ONLY = "KEYS_ONLY"
+    StreamViewType_NEW_IMAGE = "NEW_IMAGE"
+    StreamViewType_OLD_IMAGE = "OLD_IMAGE"
+    StreamViewType_NEW_AND_OLD_IMAGES = "NEW_AND_OLD_IMAGES"
+
+    TableStatus_CREATING = "CREATING"
+    TableStatus_UPDATING = "UPDATING"
+    TableStatus_DELETING = "DELETING"
+    TableStatus_ACTIVE = "ACTIVE"
+)
+
+type KeySchemaElement struct {
+    AttributeName           string
+    // KeyType_HASH or KeyType_RANGE
+    KeyType                 string
+}
+
+type AttributeDefinition struct {
+    AttributeName           string
+    // AttributeType_S, AttributeType_N or AttributeType_B
+    AttributeType           string
+}
+
+type Projection struct {
+    // Only used with ProjectionType_INCLUDE
+    NonKeyAttributes        []string    `json:",omitempty"`
+    ProjectionType          string
+}
+
+type GlobalSecondaryIndex struct {
+    IndexName               string
+    KeySchema               []KeySchemaElement
+    Projection              Projection
+    // Not used when BillingMode is PAY_PER_REQUEST
+    ProvisionedThroughput   *SetProvisionedThroughput   `json:",omitempty"`
+}
+
+type LocalSecondaryIndex struct {
+    IndexName               string
+    KeySchema               []KeySchemaElement
+    Projection              Projection
+}
+
+type StreamSpecification struct {
+    StreamEnabled           bool
+    // One of the StreamViewType_ constants. Required when StreamEnabled
+    StreamViewType          string      `json:",omitempty"`
+}
+
+// http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_CreateTable.html
+type CreateTableRequest struct {
+    awsgo.RequestBuilder
+
+    AttributeDefinitions        []AttributeDefinition
+    BillingMode                 string                      `json:",omitempty"`
+    GlobalSecondaryIndexes      []GlobalSecondaryIndex      `json:",omitempty"`
+    KeySchema                   []KeySchemaElement
+    LocalSecondaryIndexes       []LocalSecondaryIndex       `json:",omitempty"`
+    ProvisionedThroughput       *SetProvisionedThroughput   `json:",omitempty"`
+    StreamSpecification         *StreamSpecification        `json:",omitempty"`
+    TableName                   string
+}
+
+type CreateTableResponse struct {
+    TableDescription            TableDescription
+}
+
+// Creates a new CreateTableRequest, populating in some defaults
+func NewCreateTableRequest() *CreateTableRequest {
+    req := new(CreateTableRequest)
+    req.Host.Service = "dynamodb"
+    req.Host.Region = ""
+    req.Host.Domain = "amazonaws.com"
+    req.Key.AccessKeyId = ""
+    req.Key.SecretAccessKey = ""
+    req.Headers = make(map[string]string)
+    req.Headers["X-Amz-Target"] = CreateTableTarget
+    req.RequestMethod = "POST"
+    req.CanonicalUri = "/"
+    return req
+}
+
+// Adds a key to the table's KeySchema, and its type to AttributeDefinitions.
+// keyType is KeyType_HASH or KeyType_RANGE
+func (req *CreateTableRequest) AddKey(name, keyType, attributeType string) {
+    req.KeySchema = append(req.KeySchema, KeySchemaElement{name, keyType})
+    req.AddAttributeDefinition(name, attributeType)
+}
+
+// Adds the type of an attribute used by a key or index, unless it is already defined.
+func (req *CreateTableRequest) AddAttributeDefinition(name, attributeType string) {
+    for _, def := range req.AttributeDefinitions {
+        if def.AttributeName == name {
+            return
+        }
+    }
+    req.AttributeDefinitions = append(req.AttributeDefinitions, AttributeDefinition{name, attributeType})
+}
+
+func (req * CreateTableRequest) VerifyInput() (error) {
+    if len(req.Host.Service) == 0 {
+        return Verification_Error_ServiceEmpty
+    }
+    if len(req.TableName) == 0 {
+        return Verification_Error_TableNameEmpty
+    }
+    if len(req.Host.Region) == 0 {
+        return Verification_Error_RegionEmpty
+    }
+    if len(req.KeySchema) == 0 {
+        return Verification_Error_KeySchemaEmpty
+    }
+    if len(req.AttributeDefinitions) == 0 {
+        return Verification_Error_AttributeDefinitionsEmpty
+    }
+    if req.BillingMode != BillingMode_PAY_PER_REQUEST {
+        if req.ProvisionedThroughput == nil {
+            return Verification_Error_ProvisionedThroughputEmpty
+        }
+        for i := range req.GlobalSecondaryIndexes {
+            if req.GlobalSecondaryIndexes[i].ProvisionedThroughput == nil {
+                return Verification_Error_IndexProvisionedThroughputEmpty
+            }
+        }
+    }
+    return nil
+}
+
+
+func (req CreateTableRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) (interface{}) {
+    if err := CheckForErrorResponse(response, statusCode); err != nil {
+        return err
+    }
+    resp := new(CreateTableResponse)
+    err := json.Unmarshal(response, resp)
+    if err != nil {
+        newErr := &awsgo.UnmarhsallingError {
+            ActualContent : string(response),
+            MarshallError : err,
+        }
+        return newErr
+    }
+    return resp
+}
+
+func (gir CreateTableRequest) Request() (*CreateTableResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir CreateTableRequest) RequestWithContext(ctx context.Context) (*CreateTableResponse, error) {
+    request, err := awsgo.NewAwsRequest(&gir, gir)
+    if err != nil {
+        return nil, err
+    }
+    request.RequestSigningType = awsgo.RequestSigningType_AWS4
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
+    if resp == nil {
+        return nil, err
+    }
+    return resp.(*CreateTableResponse), err
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+
+import (
+    "testing"
+    "net/http"
+    "net/http/httptest"
+    "fmt"
+    "io/ioutil"
+    "strings"
+    "crypto/x509"
+    "github.com/fromkeith/awsgo"
+    "encoding/json"
+    "bytes"
+    "context"
+    "time"
+)
+
+
+func tableTestServer(builder *awsgo.RequestBuilder, handler http.HandlerFunc) *httptest.Server {
+    ts := httptest.NewTLSServer(handler)
+    certAsx509, _ := x509.ParseCertificate(ts.TLS.Certificates[0].Certificate[0])
+
+    builder.Host.Override = strings.TrimPrefix(ts.URL, "https://")
+    builder.Host.Region = "us-west-2"
+    builder.Key.AccessKeyId = "akey"
+    builder.Key.SecretAccessKey = "skey"
+    builder.HttpClient = awsgo.CreateCertApprovedClient([]*x509.Certificate{certAsx509})
+    return ts
+}
+
+func checkTableRequestBody(t *testing.T, r *http.Request, target, expectedRequestBody string) {
+    expectedCompactBuf := bytes.Buffer{}
+    json.Compact(&expectedCompactBuf, []byte(expectedRequestBody))
+
+    defer r.Body.Close()
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        t.Fatalf("couldn't read content! error: %v", err)
+    }
+    if r.Header.Get("X-Amz-Target") != target {
+        t.Errorf("Wrong target. Expected: %s. Got %s", target, r.Header.Get("X-Amz-Target"))
+    }
+    if expectedCompactBuf.String() != string(body) {
+        t.Errorf("Bodies don't match. Expected: %s. Got %s", expectedCompactBuf.String(), string(body))
+    }
+}
+
+
+func Test_CreateTable(t * testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        checkTableRequestBody(t, r, CreateTableTarget, `
+        {
+            "AttributeDefinitions" : [
+                {"AttributeName" : "user", "AttributeType" : "S"},
+                {"AttributeName" : "at", "AttributeType" : "N"},
+                {"AttributeName" : "email", "AttributeType" : "S"}
+            ],
+            "BillingMode" : "PAY_PER_REQUEST",
+            "GlobalSecondaryIndexes" : [
+                {
+                    "IndexName" : "byEmail",
+                    "KeySchema" : [{"AttributeName" : "email", "KeyType" : "HASH"}],
+                    "Projection" : {"ProjectionType" : "KEYS_ONLY"}
+                }
+            ],
+            "KeySchema" : [
+                {"AttributeName" : "user", "KeyType" : "HASH"},
+                {"AttributeName" : "at", "KeyType" : "RANGE"}
+            ],
+            "StreamSpecification" : {"StreamEnabled" : true, "StreamViewType" : "NEW_IMAGE"},
+            "TableName" : "events"
+        }
+        `)
+        fmt.Fprintf(w, `{"TableDescription":{"TableName":"events","TableStatus":"CREATING","TableArn":"arn:aws:dynamodb:us-west-2:1:table/events","BillingModeSummary":{"BillingMode":"PAY_PER_REQUEST"}}}`)
+    })
+
+    req := NewCreateTableRequest()
+    req.TableName = "events"
+    req.BillingMode = BillingMode_PAY_PER_REQUEST
+    req.AddKey("user", KeyType_HASH, AttributeType_S)
+    req.AddKey("at", KeyType_RANGE, AttributeType_N)
+    req.AddAttributeDefinition("email", AttributeType_S)
+    req.AddAttributeDefinition("user", AttributeType_S)
+    req.GlobalSecondaryIndexes = []GlobalSecondaryIndex{{
+        IndexName: "byEmail",
+        KeySchema: []KeySchemaElement{{"email", KeyType_HASH}},
+        Projection: Projection{ProjectionType: ProjectionType_KEYS_ONLY},
+    }}
+    req.StreamSpecification = &StreamSpecification{true, StreamViewType_NEW_IMAGE}
+
+    ts := tableTestServer(&req.RequestBuilder, handler)
+    defer ts.Close()
+    resp, err := req.Request()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.TableDescription.TableStatus != TableStatus_CREATING {
+        t.Errorf("Expected CREATING status. Got %s", resp.TableDescription.TableStatus)
+    }
+    if resp.TableDescription.BillingModeSummary == nil || resp.TableDescription.BillingModeSummary.BillingMode != BillingMode_PAY_PER_REQUEST {
+        t.Errorf("Expected PAY_PER_REQUEST billing summary. Got %v", resp.TableDescription.BillingModeSummary)
+    }
+}
+
+func Test_CreateTable_Verify(t * testing.T) {
+    req := NewCreateTableRequest()
+    req.Host.Region = "us-west-2"
+    req.TableName = "events"
+    if err := req.VerifyInput(); err != Verification_Error_KeySchemaEmpty {
+        t.Errorf("Expected KeySchemaEmpty. Got %v", err)
+    }
+    req.AddKey("user", KeyType_HASH, AttributeType_S)
+    if err := req.VerifyInput(); err != Verification_Error_ProvisionedThroughputEmpty {
+        t.Errorf("Expected ProvisionedThroughputEmpty. Got %v", err)
+    }
+    req.ProvisionedThroughput = &SetProvisionedThroughput{5, 5}
+    if err := req.VerifyInput(); err != nil {
+        t.Errorf("Expected no error. Got %v", err)
+    }
+    req.GlobalSecondaryIndexes = []GlobalSecondaryIndex{{
+        IndexName: "byType",
+        KeySchema: []KeySchemaElement{{"type", KeyType_HASH}},
+        Projection: Projection{ProjectionType: ProjectionType_KEYS_ONLY},
+    }}
+    if err := req.VerifyInput(); err != Verification_Error_IndexProvisionedThroughputEmpty {
+        t.Errorf("Expected IndexProvisionedThroughputEmpty. Got %v", err)
+    }
+    req.GlobalSecondaryIndexes[0].ProvisionedThroughput = &SetProvisionedThroughput{1, 1}
+    if err := req.VerifyInput(); err != nil {
+        t.Errorf("Expected no error. Got %v", err)
+    }
+}
+
+func Test_DeleteTable(t * testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        checkTableRequestBody(t, r, DeleteTableTarget, `{"TableName" : "events"}`)
+        fmt.Fprintf(w, `{"TableDescription":{"TableName":"events","TableStatus":"DELETING"}}`)
+    })
+
+    req := NewDeleteTableRequest()
+    req.TableName = "events"
+    ts := tableTestServer(&req.RequestBuilder, handler)
+    defer ts.Close()
+    resp, err := req.Request()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.TableDescription.TableStatus != TableStatus_DELETING {
+        t.Errorf("Expected DELETING status. Got %s", resp.TableDescription.TableStatus)
+    }
+}
+
+func Test_ListTables_Paging(t * testing.T) {
+    call := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        call ++
+        if call == 1 {
+            checkTableRequestBody(t, r, ListTablesTarget, `{"Limit" : 2}`)
+            fmt.Fprintf(w, `{"LastEvaluatedTableName":"b","TableNames":["a","b"]}`)
+            return
+        }
+        checkTableRequestBody(t, r, ListTablesTarget, `{"ExclusiveStartTableName" : "b", "Limit" : 2}`)
+        fmt.Fprintf(w, `{"TableNames":["c"]}`)
+    })
+
+    req := NewListTablesRequest()
+    req.Limit = 2
+    ts := tableTestServer(&req.RequestBuilder, handler)
+    defer ts.Close()
+
+    var names []string
+    resp, err := req.Request()
+    for resp != nil && err == nil {
+        names = append(names, resp.TableNames...)
+        resp, err = resp.Next(req)
+    }
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if strings.Join(names, ",") != "a,b,c" {
+        t.Errorf("Expected a,b,c. Got %v", names)
+    }
+    // later pages have their own headers, rather than writing to the caller's
+    if req.Headers["Content-Length"] != "11" {
+        t.Errorf("The first page's headers were changed by a later page. Content-Length: %s", req.Headers["Content-Length"])
+    }
+}
+
+func Test_WaitUntilTableActive(t * testing.T) {
+    call := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        call ++
+        switch call {
+        case 1:
+            w.WriteHeader(400)
+            fmt.Fprintf(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"not found"}`)
+        case 2:
+            fmt.Fprintf(w, `{"Table":{"TableName":"events","TableStatus":"CREATING"}}`)
+        case 3:
+            fmt.Fprintf(w, `{"Table":{"TableName":"events","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"byEmail","IndexStatus":"CREATING"}]}}`)
+        default:
+            fmt.Fprintf(w, `{"Table":{"TableName":"events","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"byEmail","IndexStatus":"ACTIVE"}]}}`)
+        }
+    })
+
+    req := NewDescribeTableRequest()
+    req.TableName = "events"
+    ts := tableTestServer(&req.RequestBuilder, handler)
+    defer ts.Close()
+
+    resp, err := WaitUntilTableActive(context.Background(), req, time.Millisecond)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if call != 4 {
+        t.Errorf("Expected 4 calls. Got %d", call)
+    }
+    if resp.Table.TableStatus != TableStatus_ACTIVE {
+        t.Errorf("Expected ACTIVE status. Got %s", resp.Table.TableStatus)
+    }
+}
+
+func Test_WaitUntilTableDeleted(t * testing.T) {
+    call := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        call ++
+        if call < 3 {
+            fmt.Fprintf(w, `{"Table":{"TableName":"events","TableStatus":"DELETING"}}`)
+            return
+        }
+        w.WriteHeader(400)
+        fmt.Fprintf(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"not found"}`)
+    })
+
+    req := NewDescribeTableRequest()
+    req.TableName = "events"
+    ts := tableTestServer(&req.RequestBuilder, handler)
+    defer ts.Close()
+
+    if err := WaitUntilTableDeleted(context.Background(), req, time.Millisecond); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if call != 3 {
+        t.Errorf("Expected 3 calls. Got %d", call)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+    defer cancel()
+    handler2 := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        fmt.Fprintf(w, `{"Table":{"TableName":"events","TableStatus":"DELETING"}}`)
+    })
+    ts2 := tableTestServer(&req.RequestBuilder, handler2)
+    defer ts2.Close()
+    if err := WaitUntilTableDeleted(ctx, req, time.Millisecond); err == nil {
+        t.Errorf("Expected the context deadline to stop the waiter")
+    }
+}
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -52,6 +53,10 @@ type DeleteItemRequest struct {
     ReturnItemCollectionMetrics    string
     ReturnValues             string
     TableName                string
+    // Use SetExpression to fill these in from an expression.Builder
+    ConditionExpression      string  `json:",omitempty"`
+    ExpressionAttributeNames     map[string]string   `json:",omitempty"`
+    ExpressionAttributeValues    map[string]interface{}  `json:",omitempty"`
 }
 
 type DeleteItemResponse struct {
@@ -103,6 +108,11 @@ func (gir * DeleteItemRequest) VerifyInput() (error) {
             gir.Expected[k] = ExpectedItem{v.Exists, awsgo.ConvertToAwsItem(v.Value)}
         }
     }
+    values, err := convertExpressionValues(gir.ExpressionAttributeValues)
+    if err != nil {
+        return err
+    }
+    gir.ExpressionAttributeValues = values
     return nil
 }
 
@@ -130,12 +140,17 @@ func (gir DeleteItemRequest) DeMarshalResponse(response []byte, headers map[stri
 }
 
 func (gir DeleteItemRequest) Request() (*DeleteItemResponse, error) {
-    request, err := awsgo.NewAwsRequest(&gir, gir)
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir DeleteItemRequest) RequestWithContext(ctx context.Context) (*DeleteItemResponse, error) {
+    request, err := awsgo.NewAwsRequest(&gir, &gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -32,6 +32,7 @@ package dynamo
 
 
 import (
+    "context"
     "encoding/json"
     "github.com/fromkeith/awsgo"
 )
@@ -45,10 +46,6 @@ type DescribeTableRequest struct {
 
 
 
-type keySchema struct {
-    AttributeName           string
-    KeyType                 string
-}
 type projection struct {
     NonKeyAttributes        []string
     ProjectionType          string
@@ -67,31 +64,38 @@ type secondaryIndex struct {
     IndexSizeBytes          float64
     IndexStatus             string
     ItemCount               float64
-    KeySchema               []keySchema
+    KeySchema               []KeySchemaElement
     Projection              projection
     ProvisionedThroughput   *provisionedThroughput      `json:",omitempty"`
 }
 
-type attributeDefinitions struct {
-    AttributeName           string
-    AttributeType           string
+// How a table is billed, Eg. BillingMode_PAY_PER_REQUEST
+type BillingModeSummary struct {
+    BillingMode                 string
+    LastUpdateToPayPerRequestDateTime   float64
 }
 
-type desrcibleTableTable struct {
-    AttributeDefinitions        []attributeDefinitions
+// A table, as returned by DescribeTable, CreateTable, UpdateTable and DeleteTable
+type TableDescription struct {
+    AttributeDefinitions        []AttributeDefinition
+    BillingModeSummary          *BillingModeSummary     `json:",omitempty"`
     CreationDateTime            float64
     GlobalSecondaryIndexes      []secondaryIndex
     ItemCount                   float64
-    KeySchema                   []keySchema
+    KeySchema                   []KeySchemaElement
     LocalSecondaryIndexes       []secondaryIndex
     ProvisionedThroughput       provisionedThroughput
+    StreamSpecification         *StreamSpecification    `json:",omitempty"`
+    LatestStreamArn             string
+    LatestStreamLabel           string
+    TableArn                    string
     TableName                   string
     TableSizeBytes              float64
     TableStatus                 string
 }
 
 type DescribeTableResponse struct {
-    Table           desrcibleTableTable
+    Table           TableDescription
 }
 
 // Creates a new DescribeTableRequest, populating in some defaults
@@ -141,12 +145,17 @@ func (req DescribeTableRequest) DeMarshalResponse(response []byte, headers map[s
 }
 
 func (gir DescribeTableRequest) Request() (*DescribeTableResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir DescribeTableRequest) RequestWithContext(ctx context.Context) (*DescribeTableResponse, error) {
     request, err := awsgo.NewAwsRequest(&gir, gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -4,6 +4,16 @@ Dynamo
 
 This package contains objects needed to do request to DynamoDB.
 
+Client
+
+A Client creates requests that already have the region, credentials and http client from a Config.
+
+    client := dynamo.NewClient(awsgo.Config{Region: "us-west-2"})
+    getItemRequest := client.NewGetItemRequest()
+    getItemRequest.Search["MyKey"] = "Hello"
+    getItemRequest.TableName = "my.table.name"
+    resp, err := getItemRequest.Request()
+
 GetItem
 
 As defined: http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_GetItem.html.
@@ -87,6 +97,71 @@ As defined: http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_De
     }
     assert(resp.Attributes["MyKey"].(string) == "Asd")
 
+Errors
+
+Errors from DynamoDB are *ErrorResult, which implements awsgo.APIError. There are helpers for the
+common ones, Eg. IsConditionalCheckFailed, and sentinels like ErrConditionalCheckFailed for errors.Is.
+
+    _, err := putItem.Request()
+    if dynamo.IsConditionalCheckFailed(err) {
+        // someone else got there first
+    }
+
+Marshal
+
+MarshalItem turns a struct into an item for PutItem, and Unmarshal turns an item back into a struct.
+Strings, numbers, bools, []byte, sets, lists, maps, nested and embedded structs, pointers and
+time.Time are all mapped to their DynamoDB types. Structs that older versions stored as json
+strings still unmarshal.
+
+    type Event struct {
+        Id          string      `dynamo:"id"`
+        At          time.Time
+        Tags        []string
+        Details     map[string]interface{}
+    }
+    dynamo.SetDefaultTimeFormat(dynamo.TimeFormat_RFC3339)
+    putItem.Item, err = dynamo.MarshalItem(event)
+
+Options follow the name in the tag: omitempty, set or list for slices, string for numbers stored
+as strings, unixtime for times stored as epoch seconds, and hashkey and rangekey to mark the key.
+MarshalKey then builds the key from the same struct.
+
+    type Message struct {
+        Thread      string      `dynamo:"thread,hashkey"`
+        At          time.Time   `dynamo:"at,rangekey,unixtime"`
+        Views       int         `dynamo:"views,omitempty"`
+    }
+    getItem.Search, err = dynamo.MarshalKey(message)
+
+Types can encode themselves by implementing AttributeMarshaler and AttributeUnmarshaler, at
+any depth. Types implementing encoding.TextMarshaler and encoding.TextUnmarshaler are stored
+as strings.
+
+    func (m Money) MarshalDynamo() (interface{}, error) {
+        return m.String(), nil
+    }
+    func (m *Money) UnmarshalDynamo(value map[string]interface{}) error {
+        return m.Parse(awsgo.FromRawValue(value).(string))
+    }
+
+Expressions
+
+The expression package builds ConditionExpression, UpdateExpression, ProjectionExpression,
+KeyConditionExpression and FilterExpression, with the attribute names and values filled in.
+SetExpression copies them onto PutItem, UpdateItem, DeleteItem, GetItem, Query and Scan requests.
+
+    update := expression.Set(expression.Name("count"), expression.Name("count").Plus(1))
+    expr, err := expression.NewBuilder().
+        WithCondition(expression.Name("owner").Equal("bob")).
+        WithUpdate(update).
+        Build()
+    // test err ...
+    updateItem := dynamo.NewUpdateItemRequest()
+    updateItem.TableName = "some.table"
+    updateItem.UpdateKey["MyKey"] = "Asd"
+    updateItem.SetExpression(expr)
+
 BatchWriteItem
 
 As defined: http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_BatchWriteItem.html
@@ -116,6 +191,27 @@ As defined: http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_Ba
         // For the next batchWrite2 set RequestItems = UnprocessedItems
     }
 
+Tables
+
+CreateTable, DeleteTable and ListTables manage tables. AddKey adds to both the KeySchema and
+AttributeDefinitions. Tables take a while to become usable, so WaitUntilTableActive and
+WaitUntilTableDeleted poll DescribeTable until they are ready, or the context is done.
+
+    create := client.NewCreateTableRequest()
+    create.TableName = "events"
+    create.BillingMode = dynamo.BillingMode_PAY_PER_REQUEST
+    create.AddKey("user", dynamo.KeyType_HASH, dynamo.AttributeType_S)
+    create.AddKey("at", dynamo.KeyType_RANGE, dynamo.AttributeType_N)
+    if _, err := create.Request(); err != nil {
+        return err
+    }
+    describe := client.NewDescribeTableRequest()
+    describe.TableName = "events"
+    ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Minute)
+    defer cancel()
+    // 0 uses dynamo.DefaultWaiterDelay between polls
+    _, err = dynamo.WaitUntilTableActive(ctx, describe, 0)
+
 
 */
 package dynamo
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+import (
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "github.com/fromkeith/awsgo/dynamo/expression"
+)
+
+// Converts expression values to AWS items, like Item and Key are.
+// The values are copied into a new map, as they often come from a shared expression.Expression.
+func convertExpressionValues(values map[string]interface{}) (map[string]interface{}, error) {
+    if values == nil {
+        return nil, nil
+    }
+    converted := make(map[string]interface{}, len(values))
+    for k, v := range values {
+        item, err := awsgo.TryConvertToAwsItem(v)
+        if err != nil {
+            return nil, fmt.Errorf("ExpressionAttributeValues %s: %v", k, err)
+        }
+        converted[k] = item
+    }
+    return converted, nil
+}
+
+// Copies the condition, names and values from expr.
+func (pir *PutItemRequest) SetExpression(expr expression.Expression) {
+    pir.ConditionExpression = expr.Condition
+    pir.ExpressionAttributeNames = expr.Names
+    pir.ExpressionAttributeValues = expr.Values
+}
+
+// Copies the condition, names and values from expr.
+func (gir *DeleteItemRequest) SetExpression(expr expression.Expression) {
+    gir.ConditionExpression = expr.Condition
+    gir.ExpressionAttributeNames = expr.Names
+    gir.ExpressionAttributeValues = expr.Values
+}
+
+// Copies the update, condition, names and values from expr.
+func (pir *UpdateItemRequest) SetExpression(expr expression.Expression) {
+    pir.UpdateExpression = expr.Update
+    pir.ConditionExpression = expr.Condition
+    pir.ExpressionAttributeNames = expr.Names
+    pir.ExpressionAttributeValues = expr.Values
+}
+
+// Copies the projection and names from expr. GetItem takes no values, so expr should only
+// have a projection.
+func (gir *GetItemRequest) SetExpression(expr expression.Expression) {
+    gir.ProjectionExpression = expr.Projection
+    gir.ExpressionAttributeNames = expr.Names
+}
+
+// Copies the key condition, filter, projection, names and values from expr.
+func (gir *QueryRequest) SetExpression(expr expression.Expression) {
+    gir.KeyConditionExpression = expr.KeyCondition
+    gir.FilterExpression = expr.Filter
+    gir.ProjectionExpression = expr.Projection
+    gir.ExpressionAttributeNames = expr.Names
+    gir.ExpressionAttributeValues = expr.Values
+}
+
+// Copies the filter, projection, names and values from expr.
+func (gir *ScanRequest) SetExpression(expr expression.Expression) {
+    gir.FilterExpression = expr.Filter
+    gir.ProjectionExpression = expr.Projection
+    gir.ExpressionAttributeNames = expr.Names
+    gir.ExpressionAttributeValues = expr.Values
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "fmt"
+    "strings"
+)
+
+// The types accepted by AttributeType
+const (
+    AttributeType_String = "S"
+    AttributeType_StringSet = "SS"
+    AttributeType_Number = "N"
+    AttributeType_NumberSet = "NS"
+    AttributeType_Binary = "B"
+    AttributeType_BinarySet = "BS"
+    AttributeType_Boolean = "BOOL"
+    AttributeType_Null = "NULL"
+    AttributeType_List = "L"
+    AttributeType_Map = "M"
+)
+
+// A condition or filter expression. Combine them with And, Or and Not.
+type ConditionBuilder struct {
+    build       func(a *aliases) (string, error)
+}
+
+func (c ConditionBuilder) buildCondition(a *aliases) (string, error) {
+    if c.build == nil {
+        return "", Expression_Error_EmptyCondition
+    }
+    return c.build(a)
+}
+
+// Builds each operand in turn, then fills them into format.
+func formatCondition(format string, operands ...Operand) ConditionBuilder {
+    return ConditionBuilder{func (a *aliases) (string, error) {
+        built, err := buildOperands(a, operands)
+        if err != nil {
+            return "", err
+        }
+        return fmt.Sprintf(format, built...), nil
+    }}
+}
+
+// Joins conditions with the logical operator op.
+func joinConditions(op string, conditions []ConditionBuilder) ConditionBuilder {
+    return ConditionBuilder{func (a *aliases) (string, error) {
+        built := make([]string, len(conditions))
+        for i, c := range conditions {
+            str, err := c.buildCondition(a)
+            if err != nil {
+                return "", err
+            }
+            built[i] = "(" + str + ")"
+        }
+        return strings.Join(built, " " + op + " "), nil
+    }}
+}
+
+func Equal(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s = %s", operand(left), operand(right))
+}
+
+func NotEqual(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s <> %s", operand(left), operand(right))
+}
+
+func LessThan(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s < %s", operand(left), operand(right))
+}
+
+func LessThanEqual(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s <= %s", operand(left), operand(right))
+}
+
+func GreaterThan(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s > %s", operand(left), operand(right))
+}
+
+func GreaterThanEqual(left, right interface{}) ConditionBuilder {
+    return formatCondition("%s >= %s", operand(left), operand(right))
+}
+
+// lower <= op <= upper
+func Between(op, lower, upper interface{}) ConditionBuilder {
+    return formatCondition("%s BETWEEN %s AND %s", operand(op), operand(lower), operand(upper))
+}
+
+// op equals any of values
+func In(op interface{}, first interface{}, rest ...interface{}) ConditionBuilder {
+    operands := []Operand{operand(op), operand(first)}
+    for _, v := range rest {
+        operands = append(operands, operand(v))
+    }
+    return ConditionBuilder{func (a *aliases) (string, error) {
+        built, err := buildOperands(a, operands)
+        if err != nil {
+            return "", err
+        }
+        list := make([]string, len(built) - 1)
+        for i := range list {
+            list[i] = built[i + 1].(string)
+        }
+        return fmt.Sprintf("%s IN (%s)", built[0], strings.Join(list, ", ")), nil
+    }}
+}
+
+func AttributeExists(name NameBuilder) ConditionBuilder {
+    return formatCondition("attribute_exists (%s)", name)
+}
+
+func AttributeNotExists(name NameBuilder) ConditionBuilder {
+    return formatCondition("attribute_not_exists (%s)", name)
+}
+
+// attributeType is one of the AttributeType_ constants
+func AttributeType(name NameBuilder, attributeType string) ConditionBuilder {
+    return formatCondition("attribute_type (%s, %s)", name, Value(attributeType))
+}
+
+func BeginsWith(name NameBuilder, prefix string) ConditionBuilder {
+    return formatCondition("begins_with (%s, %s)", name, Value(prefix))
+}
+
+// True if name is a string containing value, or a set or list with value in it.
+func Contains(name NameBuilder, value interface{}) ConditionBuilder {
+    return formatCondition("contains (%s, %s)", name, operand(value))
+}
+
+// All of the conditions must hold.
+func And(left, right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+    return joinConditions("AND", append([]ConditionBuilder{left, right}, others...))
+}
+
+// Any of the conditions must hold.
+func Or(left, right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+    return joinConditions("OR", append([]ConditionBuilder{left, right}, others...))
+}
+
+func Not(cond ConditionBuilder) ConditionBuilder {
+    return ConditionBuilder{func (a *aliases) (string, error) {
+        str, err := cond.buildCondition(a)
+        if err != nil {
+            return "", err
+        }
+        return "NOT (" + str + ")", nil
+    }}
+}
+
+func (c ConditionBuilder) And(right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+    return And(c, right, others...)
+}
+
+func (c ConditionBuilder) Or(right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+    return Or(c, right, others...)
+}
+
+func (c ConditionBuilder) Not() ConditionBuilder {
+    return Not(c)
+}
+
+func (n NameBuilder) Equal(right interface{}) ConditionBuilder { return Equal(n, right) }
+func (n NameBuilder) NotEqual(right interface{}) ConditionBuilder { return NotEqual(n, right) }
+func (n NameBuilder) LessThan(right interface{}) ConditionBuilder { return LessThan(n, right) }
+func (n NameBuilder) LessThanEqual(right interface{}) ConditionBuilder { return LessThanEqual(n, right) }
+func (n NameBuilder) GreaterThan(right interface{}) ConditionBuilder { return GreaterThan(n, right) }
+func (n NameBuilder) GreaterThanEqual(right interface{}) ConditionBuilder { return GreaterThanEqual(n, right) }
+func (n NameBuilder) Between(lower, upper interface{}) ConditionBuilder { return Between(n, lower, upper) }
+func (n NameBuilder) In(first interface{}, rest ...interface{}) ConditionBuilder { return In(n, first, rest...) }
+func (n NameBuilder) AttributeExists() ConditionBuilder { return AttributeExists(n) }
+func (n NameBuilder) AttributeNotExists() ConditionBuilder { return AttributeNotExists(n) }
+func (n NameBuilder) AttributeType(attributeType string) ConditionBuilder { return AttributeType(n, attributeType) }
+func (n NameBuilder) BeginsWith(prefix string) ConditionBuilder { return BeginsWith(n, prefix) }
+func (n NameBuilder) Contains(value interface{}) ConditionBuilder { return Contains(n, value) }
+
+func (s SizeBuilder) Equal(right interface{}) ConditionBuilder { return Equal(s, right) }
+func (s SizeBuilder) NotEqual(right interface{}) ConditionBuilder { return NotEqual(s, right) }
+func (s SizeBuilder) LessThan(right interface{}) ConditionBuilder { return LessThan(s, right) }
+func (s SizeBuilder) LessThanEqual(right interface{}) ConditionBuilder { return LessThanEqual(s, right) }
+func (s SizeBuilder) GreaterThan(right interface{}) ConditionBuilder { return GreaterThan(s, right) }
+func (s SizeBuilder) GreaterThanEqual(right interface{}) ConditionBuilder { return GreaterThanEqual(s, right) }
+func (s SizeBuilder) Between(lower, upper interface{}) ConditionBuilder { return Between(s, lower, upper) }
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+/*
+Package expression builds DynamoDB condition, update, projection, key condition and filter
+expressions. Attribute names and values are replaced with placeholders (#n0, :v0, ...) and
+returned in Names and Values, so reserved words, nested paths and values never have to be
+escaped by hand.
+
+Conditions
+
+    cond := expression.Or(
+        expression.Name("version").Equal(3),
+        expression.Name("version").AttributeNotExists())
+    expr, err := expression.NewBuilder().WithCondition(cond).Build()
+    // test err ...
+    req := dynamo.NewPutItemRequest()
+    req.SetExpression(expr)
+
+Updates
+
+    update := expression.Set(expression.Name("count"), expression.Name("count").Plus(1)).
+        Set(expression.Name("tags"), expression.Name("tags").ListAppend([]interface{}{"new"})).
+        Set(expression.Name("created"), expression.Name("created").IfNotExists(now)).
+        Remove(expression.Name("profile.address[0]"))
+    expr, err := expression.NewBuilder().WithUpdate(update).Build()
+
+Queries
+
+    key := expression.Key("user").Equal("bob").And(expression.Key("at").GreaterThan(1000))
+    expr, err := expression.NewBuilder().
+        WithKeyCondition(key).
+        WithFilter(expression.Name("deleted").AttributeNotExists()).
+        WithProjection(expression.NamesList(expression.Name("at"), expression.Name("body"))).
+        Build()
+
+Names are split on '.' and '[n]', so Name("a.b[1].c") refers to a nested attribute. Use
+Key for key attributes, which are never split.
+
+Values are converted with awsgo.ConvertToAwsItem when the request is sent.
+*/
+package expression
@@ -0,0 +1,308 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+var (
+    Expression_Error_Empty = errors.New("Builder has no expressions to build")
+    Expression_Error_EmptyName = errors.New("Attribute names cannot be empty")
+    Expression_Error_InvalidName = errors.New("Attribute name is not a valid path")
+    Expression_Error_EmptyCondition = errors.New("Condition has not been set")
+    Expression_Error_EmptyUpdate = errors.New("Update has no actions")
+    Expression_Error_EmptyProjection = errors.New("Projection has no names")
+)
+
+// The built expressions, ready to be copied onto a request.
+type Expression struct {
+    Condition       string
+    Filter          string
+    KeyCondition    string
+    Projection      string
+    Update          string
+    // Placeholder to attribute name. Eg. #n0 -> name
+    Names           map[string]string
+    // Placeholder to value. Eg. :v0 -> 5
+    Values          map[string]interface{}
+}
+
+// Collects the expressions for one request. Each With... returns a new Builder.
+type Builder struct {
+    condition       *ConditionBuilder
+    filter          *ConditionBuilder
+    keyCondition    *KeyConditionBuilder
+    projection      *ProjectionBuilder
+    update          *UpdateBuilder
+}
+
+func NewBuilder() Builder {
+    return Builder{}
+}
+
+func (b Builder) WithCondition(cond ConditionBuilder) Builder {
+    b.condition = &cond
+    return b
+}
+
+func (b Builder) WithFilter(cond ConditionBuilder) Builder {
+    b.filter = &cond
+    return b
+}
+
+func (b Builder) WithKeyCondition(cond KeyConditionBuilder) Builder {
+    b.keyCondition = &cond
+    return b
+}
+
+func (b Builder) WithProjection(proj ProjectionBuilder) Builder {
+    b.projection = &proj
+    return b
+}
+
+func (b Builder) WithUpdate(update UpdateBuilder) Builder {
+    b.update = &update
+    return b
+}
+
+// Builds the expressions, sharing one set of placeholders between them.
+func (b Builder) Build() (Expression, error) {
+    var expr Expression
+    if b.keyCondition == nil && b.condition == nil && b.filter == nil && b.projection == nil && b.update == nil {
+        return expr, Expression_Error_Empty
+    }
+    a := new(aliases)
+    var err error
+    if b.keyCondition != nil {
+        if expr.KeyCondition, err = b.keyCondition.buildKeyCondition(a); err != nil {
+            return Expression{}, err
+        }
+    }
+    if b.condition != nil {
+        if expr.Condition, err = b.condition.buildCondition(a); err != nil {
+            return Expression{}, err
+        }
+    }
+    if b.filter != nil {
+        if expr.Filter, err = b.filter.buildCondition(a); err != nil {
+            return Expression{}, err
+        }
+    }
+    if b.projection != nil {
+        if expr.Projection, err = b.projection.build(a); err != nil {
+            return Expression{}, err
+        }
+    }
+    if b.update != nil {
+        if expr.Update, err = b.update.build(a); err != nil {
+            return Expression{}, err
+        }
+    }
+    expr.Names = a.names
+    expr.Values = a.values
+    return expr, nil
+}
+
+// Hands out placeholders. The same name always gets the same placeholder, every value gets
+// its own.
+type aliases struct {
+    names       map[string]string
+    byName      map[string]string
+    values      map[string]interface{}
+}
+
+func (a *aliases) name(name string) string {
+    if alias, ok := a.byName[name]; ok {
+        return alias
+    }
+    if a.names == nil {
+        a.names = make(map[string]string)
+        a.byName = make(map[string]string)
+    }
+    alias := "#n" + strconv.Itoa(len(a.names))
+    a.names[alias] = name
+    a.byName[name] = alias
+    return alias
+}
+
+func (a *aliases) value(v interface{}) string {
+    if a.values == nil {
+        a.values = make(map[string]interface{})
+    }
+    alias := ":v" + strconv.Itoa(len(a.values))
+    a.values[alias] = v
+    return alias
+}
+
+// Something that can appear in an expression: a name, a value, or a function of them.
+type Operand interface {
+    buildOperand(a *aliases) (string, error)
+}
+
+// Wraps v with Value, unless it already is an Operand.
+func operand(v interface{}) Operand {
+    if op, ok := v.(Operand); ok {
+        return op
+    }
+    return Value(v)
+}
+
+// An attribute path, eg. "a.b[0].c".
+type NameBuilder struct {
+    name        string
+}
+
+func Name(name string) NameBuilder {
+    return NameBuilder{name}
+}
+
+func (n NameBuilder) buildOperand(a *aliases) (string, error) {
+    if n.name == "" {
+        return "", Expression_Error_EmptyName
+    }
+    parts := strings.Split(n.name, ".")
+    for i, part := range parts {
+        field := part
+        index := ""
+        if at := strings.IndexByte(part, '['); at >= 0 {
+            field, index = part[:at], part[at:]
+            if err := validIndexes(index); err != nil {
+                return "", err
+            }
+        }
+        if field == "" {
+            return "", Expression_Error_InvalidName
+        }
+        parts[i] = a.name(field) + index
+    }
+    return strings.Join(parts, "."), nil
+}
+
+// Checks a run of list indexes, eg. [0][12]
+func validIndexes(index string) error {
+    for index != "" {
+        end := strings.IndexByte(index, ']')
+        if index[0] != '[' || end < 2 {
+            return Expression_Error_InvalidName
+        }
+        if _, err := strconv.Atoi(index[1:end]); err != nil {
+            return Expression_Error_InvalidName
+        }
+        index = index[end + 1:]
+    }
+    return nil
+}
+
+// A value, converted with awsgo.ConvertToAwsItem when the request is sent.
+type ValueBuilder struct {
+    value       interface{}
+}
+
+func Value(v interface{}) ValueBuilder {
+    return ValueBuilder{v}
+}
+
+func (v ValueBuilder) buildOperand(a *aliases) (string, error) {
+    return a.value(v.value), nil
+}
+
+// The size of an attribute, for use in conditions.
+type SizeBuilder struct {
+    name        NameBuilder
+}
+
+func (n NameBuilder) Size() SizeBuilder {
+    return SizeBuilder{n}
+}
+
+func (s SizeBuilder) buildOperand(a *aliases) (string, error) {
+    name, err := s.name.buildOperand(a)
+    if err != nil {
+        return "", err
+    }
+    return "size (" + name + ")", nil
+}
+
+// Values computed by an update: arithmetic, list_append and if_not_exists.
+type SetValueBuilder struct {
+    format      string
+    operands    []Operand
+}
+
+func (s SetValueBuilder) buildOperand(a *aliases) (string, error) {
+    built, err := buildOperands(a, s.operands)
+    if err != nil {
+        return "", err
+    }
+    return fmt.Sprintf(s.format, built...), nil
+}
+
+// Builds operands in order, so placeholders are numbered left to right.
+func buildOperands(a *aliases, operands []Operand) ([]interface{}, error) {
+    built := make([]interface{}, len(operands))
+    for i, op := range operands {
+        str, err := op.buildOperand(a)
+        if err != nil {
+            return nil, err
+        }
+        built[i] = str
+    }
+    return built, nil
+}
+
+// left + right
+func Plus(left, right interface{}) SetValueBuilder {
+    return SetValueBuilder{"%s + %s", []Operand{operand(left), operand(right)}}
+}
+
+// left - right
+func Minus(left, right interface{}) SetValueBuilder {
+    return SetValueBuilder{"%s - %s", []Operand{operand(left), operand(right)}}
+}
+
+// list_append(first, second). Lists of values should be []interface{}
+func ListAppend(first, second interface{}) SetValueBuilder {
+    return SetValueBuilder{"list_append(%s, %s)", []Operand{operand(first), operand(second)}}
+}
+
+// if_not_exists(name, value)
+func IfNotExists(name NameBuilder, value interface{}) SetValueBuilder {
+    return SetValueBuilder{"if_not_exists(%s, %s)", []Operand{name, operand(value)}}
+}
+
+func (n NameBuilder) Plus(right interface{}) SetValueBuilder { return Plus(n, right) }
+func (n NameBuilder) Minus(right interface{}) SetValueBuilder { return Minus(n, right) }
+func (n NameBuilder) ListAppend(list interface{}) SetValueBuilder { return ListAppend(n, list) }
+func (n NameBuilder) IfNotExists(value interface{}) SetValueBuilder { return IfNotExists(n, value) }
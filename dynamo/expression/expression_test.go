@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "reflect"
+    "testing"
+)
+
+func Test_ConditionExpression(t *testing.T) {
+    cond := Or(
+        Name("version").Equal(3),
+        Name("version").AttributeNotExists(),
+        Not(Name("tags").Contains("locked")))
+    expr, err := NewBuilder().WithCondition(cond).Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+    expected := "(#n0 = :v0) OR (attribute_not_exists (#n0)) OR (NOT (contains (#n1, :v1)))"
+    if expr.Condition != expected {
+        t.Errorf("Expected condition %s. Got: %s", expected, expr.Condition)
+    }
+    if !reflect.DeepEqual(expr.Names, map[string]string{"#n0": "version", "#n1": "tags"}) {
+        t.Errorf("Unexpected names: %v", expr.Names)
+    }
+    if !reflect.DeepEqual(expr.Values, map[string]interface{}{":v0": 3, ":v1": "locked"}) {
+        t.Errorf("Unexpected values: %v", expr.Values)
+    }
+}
+
+func Test_ConditionFunctions(t *testing.T) {
+    cases := []struct {
+        cond        ConditionBuilder
+        expected    string
+    } {
+        {Name("a").NotEqual(Name("b")), "#n0 <> #n1"},
+        {Name("a").Between(1, 5), "#n0 BETWEEN :v0 AND :v1"},
+        {Name("a").In("x", "y", "z"), "#n0 IN (:v0, :v1, :v2)"},
+        {Name("a").BeginsWith("pre"), "begins_with (#n0, :v0)"},
+        {Name("a").AttributeType(AttributeType_Map), "attribute_type (#n0, :v0)"},
+        {Name("a").Size().GreaterThanEqual(10), "size (#n0) >= :v0"},
+        {Name("a.b[2].c").LessThan(1).And(Name("a.d").AttributeExists()), "(#n0.#n1[2].#n2 < :v0) AND (attribute_exists (#n0.#n3))"},
+    }
+    for _, c := range cases {
+        expr, err := NewBuilder().WithCondition(c.cond).Build()
+        if err != nil {
+            t.Fatalf("Build failed: %v", err)
+        }
+        if expr.Condition != c.expected {
+            t.Errorf("Expected %s. Got: %s", c.expected, expr.Condition)
+        }
+    }
+}
+
+func Test_UpdateExpression(t *testing.T) {
+    update := Set(Name("count"), Name("count").Plus(1)).
+        Set(Name("tags"), Name("tags").ListAppend([]interface{}{"new"})).
+        Set(Name("created"), Name("created").IfNotExists(100)).
+        Remove(Name("profile.address[0]")).
+        Add(Name("visits"), 1).
+        Delete(Name("colours"), []string{"red"})
+    expr, err := NewBuilder().WithUpdate(update).Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+    expected := "SET #n0 = #n0 + :v0, #n1 = list_append(#n1, :v1), #n2 = if_not_exists(#n2, :v2) " +
+        "REMOVE #n3.#n4[0] ADD #n5 :v3 DELETE #n6 :v4"
+    if expr.Update != expected {
+        t.Errorf("Expected update %s. Got: %s", expected, expr.Update)
+    }
+    if len(expr.Names) != 7 || len(expr.Values) != 5 {
+        t.Errorf("Expected 7 names and 5 values. Got: %v %v", expr.Names, expr.Values)
+    }
+    if expr.Names["#n4"] != "address" {
+        t.Errorf("Expected #n4 to be address. Got: %s", expr.Names["#n4"])
+    }
+}
+
+func Test_UpdateBuildersDontShareActions(t *testing.T) {
+    base := Set(Name("a"), 1).Set(Name("b"), 2)
+    first := base.Set(Name("c"), 3)
+    second := base.Set(Name("d"), 4)
+    expr, err := NewBuilder().WithUpdate(first).Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+    if expr.Names["#n2"] != "c" {
+        t.Errorf("Expected the first builder to keep its own action. Got: %v", expr.Names)
+    }
+    expr, _ = NewBuilder().WithUpdate(second).Build()
+    if expr.Names["#n2"] != "d" {
+        t.Errorf("Expected the second builder to keep its own action. Got: %v", expr.Names)
+    }
+}
+
+func Test_QueryExpressions(t *testing.T) {
+    key := Key("user").Equal("bob").And(Key("at").Between(10, 20))
+    expr, err := NewBuilder().
+        WithKeyCondition(key).
+        WithFilter(Name("deleted").AttributeNotExists()).
+        WithProjection(NamesList(Name("at"), Name("body.text")).AddNames(Name("user"))).
+        Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+    if expr.KeyCondition != "(#n0 = :v0) AND (#n1 BETWEEN :v1 AND :v2)" {
+        t.Errorf("Unexpected key condition: %s", expr.KeyCondition)
+    }
+    if expr.Filter != "attribute_not_exists (#n2)" {
+        t.Errorf("Unexpected filter: %s", expr.Filter)
+    }
+    // names are shared between the expressions
+    if expr.Projection != "#n1, #n3.#n4, #n0" {
+        t.Errorf("Unexpected projection: %s", expr.Projection)
+    }
+    if expr.Condition != "" || expr.Update != "" {
+        t.Errorf("Expected no condition or update. Got: %s %s", expr.Condition, expr.Update)
+    }
+}
+
+func Test_KeyIsNotSplit(t *testing.T) {
+    expr, err := NewBuilder().WithKeyCondition(Key("a.b").Equal(1)).Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+    if expr.KeyCondition != "#n0 = :v0" || expr.Names["#n0"] != "a.b" {
+        t.Errorf("Expected the key to be one name. Got: %s %v", expr.KeyCondition, expr.Names)
+    }
+}
+
+func Test_BuildErrors(t *testing.T) {
+    cases := []struct {
+        builder     Builder
+        expected    error
+    } {
+        {NewBuilder(), Expression_Error_Empty},
+        {NewBuilder().WithCondition(ConditionBuilder{}), Expression_Error_EmptyCondition},
+        {NewBuilder().WithKeyCondition(KeyConditionBuilder{}), Expression_Error_EmptyKeyCondition},
+        {NewBuilder().WithUpdate(UpdateBuilder{}), Expression_Error_EmptyUpdate},
+        {NewBuilder().WithProjection(ProjectionBuilder{}), Expression_Error_EmptyProjection},
+        {NewBuilder().WithCondition(Name("").Equal(1)), Expression_Error_EmptyName},
+        {NewBuilder().WithCondition(Name("a..b").Equal(1)), Expression_Error_InvalidName},
+        {NewBuilder().WithCondition(Name("a[x]").Equal(1)), Expression_Error_InvalidName},
+        {NewBuilder().WithCondition(And(Name("a").Equal(1), ConditionBuilder{})), Expression_Error_EmptyCondition},
+    }
+    for i, c := range cases {
+        if _, err := c.builder.Build(); err != c.expected {
+            t.Errorf("Case %d: expected %v. Got: %v", i, c.expected, err)
+        }
+    }
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "errors"
+    "fmt"
+)
+
+var (
+    Expression_Error_EmptyKeyCondition = errors.New("Key condition has not been set")
+)
+
+// A key attribute. Unlike Name, the name is never split into a path.
+type KeyBuilder struct {
+    key         string
+}
+
+func Key(key string) KeyBuilder {
+    return KeyBuilder{key}
+}
+
+func (k KeyBuilder) buildOperand(a *aliases) (string, error) {
+    if k.key == "" {
+        return "", Expression_Error_EmptyName
+    }
+    return a.name(k.key), nil
+}
+
+// A key condition expression: an Equal on the hash key, optionally And a condition on the
+// range key.
+type KeyConditionBuilder struct {
+    build       func(a *aliases) (string, error)
+}
+
+func (c KeyConditionBuilder) buildKeyCondition(a *aliases) (string, error) {
+    if c.build == nil {
+        return "", Expression_Error_EmptyKeyCondition
+    }
+    return c.build(a)
+}
+
+func formatKeyCondition(format string, operands ...Operand) KeyConditionBuilder {
+    return KeyConditionBuilder{formatCondition(format, operands...).build}
+}
+
+func (k KeyBuilder) Equal(value interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s = %s", k, operand(value))
+}
+
+func (k KeyBuilder) LessThan(value interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s < %s", k, operand(value))
+}
+
+func (k KeyBuilder) LessThanEqual(value interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s <= %s", k, operand(value))
+}
+
+func (k KeyBuilder) GreaterThan(value interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s > %s", k, operand(value))
+}
+
+func (k KeyBuilder) GreaterThanEqual(value interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s >= %s", k, operand(value))
+}
+
+func (k KeyBuilder) Between(lower, upper interface{}) KeyConditionBuilder {
+    return formatKeyCondition("%s BETWEEN %s AND %s", k, operand(lower), operand(upper))
+}
+
+func (k KeyBuilder) BeginsWith(prefix string) KeyConditionBuilder {
+    return formatKeyCondition("begins_with (%s, %s)", k, Value(prefix))
+}
+
+// Combines the hash key condition with the range key condition.
+func KeyAnd(left, right KeyConditionBuilder) KeyConditionBuilder {
+    return KeyConditionBuilder{func (a *aliases) (string, error) {
+        l, err := left.buildKeyCondition(a)
+        if err != nil {
+            return "", err
+        }
+        r, err := right.buildKeyCondition(a)
+        if err != nil {
+            return "", err
+        }
+        return fmt.Sprintf("(%s) AND (%s)", l, r), nil
+    }}
+}
+
+func (c KeyConditionBuilder) And(right KeyConditionBuilder) KeyConditionBuilder {
+    return KeyAnd(c, right)
+}
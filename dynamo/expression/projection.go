@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "strings"
+)
+
+// The attributes to return.
+type ProjectionBuilder struct {
+    names       []NameBuilder
+}
+
+func NamesList(name NameBuilder, names ...NameBuilder) ProjectionBuilder {
+    return ProjectionBuilder{append([]NameBuilder{name}, names...)}
+}
+
+// Adds more attributes to the projection.
+func (p ProjectionBuilder) AddNames(names ...NameBuilder) ProjectionBuilder {
+    p.names = append(p.names[:len(p.names):len(p.names)], names...)
+    return p
+}
+
+func (p ProjectionBuilder) build(a *aliases) (string, error) {
+    if len(p.names) == 0 {
+        return "", Expression_Error_EmptyProjection
+    }
+    built := make([]string, len(p.names))
+    for i, name := range p.names {
+        str, err := name.buildOperand(a)
+        if err != nil {
+            return "", err
+        }
+        built[i] = str
+    }
+    return strings.Join(built, ", "), nil
+}
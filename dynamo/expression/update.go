@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package expression
+
+import (
+    "strings"
+)
+
+type updateAction struct {
+    name        NameBuilder
+    value       Operand
+}
+
+// An update expression. Each call adds an action, and returns a new UpdateBuilder.
+type UpdateBuilder struct {
+    sets        []updateAction
+    removes     []updateAction
+    adds        []updateAction
+    deletes     []updateAction
+}
+
+// Sets name to value. value can be a plain value, another Name, or one of Plus, Minus,
+// ListAppend and IfNotExists.
+func Set(name NameBuilder, value interface{}) UpdateBuilder {
+    return UpdateBuilder{}.Set(name, value)
+}
+
+// Removes name from the item.
+func Remove(name NameBuilder) UpdateBuilder {
+    return UpdateBuilder{}.Remove(name)
+}
+
+// Adds value to a number, or adds the members of value to a set.
+func Add(name NameBuilder, value interface{}) UpdateBuilder {
+    return UpdateBuilder{}.Add(name, value)
+}
+
+// Deletes the members of value from a set.
+func Delete(name NameBuilder, value interface{}) UpdateBuilder {
+    return UpdateBuilder{}.Delete(name, value)
+}
+
+// Appends without sharing the backing array, so builders can be branched safely.
+func appendAction(actions []updateAction, action updateAction) []updateAction {
+    return append(actions[:len(actions):len(actions)], action)
+}
+
+func (u UpdateBuilder) Set(name NameBuilder, value interface{}) UpdateBuilder {
+    u.sets = appendAction(u.sets, updateAction{name, operand(value)})
+    return u
+}
+
+func (u UpdateBuilder) Remove(name NameBuilder) UpdateBuilder {
+    u.removes = appendAction(u.removes, updateAction{name: name})
+    return u
+}
+
+func (u UpdateBuilder) Add(name NameBuilder, value interface{}) UpdateBuilder {
+    u.adds = appendAction(u.adds, updateAction{name, operand(value)})
+    return u
+}
+
+func (u UpdateBuilder) Delete(name NameBuilder, value interface{}) UpdateBuilder {
+    u.deletes = appendAction(u.deletes, updateAction{name, operand(value)})
+    return u
+}
+
+func (u UpdateBuilder) build(a *aliases) (string, error) {
+    clauses := make([]string, 0, 4)
+    for _, section := range []struct {
+        keyword     string
+        separator   string
+        actions     []updateAction
+    } {
+        {"SET", " = ", u.sets},
+        {"REMOVE", "", u.removes},
+        {"ADD", " ", u.adds},
+        {"DELETE", " ", u.deletes},
+    } {
+        if len(section.actions) == 0 {
+            continue
+        }
+        built := make([]string, len(section.actions))
+        for i, action := range section.actions {
+            name, err := action.name.buildOperand(a)
+            if err != nil {
+                return "", err
+            }
+            built[i] = name
+            if action.value == nil {
+                continue
+            }
+            value, err := action.value.buildOperand(a)
+            if err != nil {
+                return "", err
+            }
+            built[i] += section.separator + value
+        }
+        clauses = append(clauses, section.keyword + " " + strings.Join(built, ", "))
+    }
+    if len(clauses) == 0 {
+        return "", Expression_Error_EmptyUpdate
+    }
+    return strings.Join(clauses, " "), nil
+}
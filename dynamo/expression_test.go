@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+import (
+    "bytes"
+    "crypto/x509"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "github.com/fromkeith/awsgo"
+    "github.com/fromkeith/awsgo/dynamo/expression"
+)
+
+func Test_UpdateItemWithExpression(t *testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        expectedRequestBody := `
+        {
+            "Key": {"id": {"S": "abc"}},
+            "TableName": "asd",
+            "ReturnConsumedCapacity": "NONE",
+            "ReturnItemCollection": "NONE",
+            "ReturnValues": "NONE",
+            "ConditionExpression": "attribute_exists (#n0)",
+            "UpdateExpression": "SET #n1 = #n1 + :v0, #n2 = list_append(#n2, :v1) REMOVE #n3",
+            "ExpressionAttributeNames": {"#n0": "id", "#n1": "count", "#n2": "tags", "#n3": "old"},
+            "ExpressionAttributeValues": {
+                ":v0": {"N": "1.000000"},
+                ":v1": {"L": [{"S": "new"}, {"BOOL": true}]}
+            }
+        }
+        `
+        expectedCompactBuf := bytes.Buffer{}
+        json.Compact(&expectedCompactBuf, []byte(expectedRequestBody))
+
+        defer r.Body.Close()
+        body, err := ioutil.ReadAll(r.Body)
+        if err != nil {
+            t.Errorf("couldn't read content! error: %v", err)
+            return
+        }
+        if expectedCompactBuf.String() != string(body) {
+            t.Errorf("Bodies don't match. Expected: %s. Got %s", expectedCompactBuf.String(), string(body))
+        }
+        fmt.Fprintf(w, "{}")
+    })
+    ts := httptest.NewTLSServer(handler)
+    defer ts.Close()
+    certAsx509, _ := x509.ParseCertificate(ts.TLS.Certificates[0].Certificate[0])
+
+    expr, err := expression.NewBuilder().
+        WithCondition(expression.Name("id").AttributeExists()).
+        WithUpdate(expression.Set(expression.Name("count"), expression.Name("count").Plus(1)).
+            Set(expression.Name("tags"), expression.Name("tags").ListAppend([]interface{}{"new", true})).
+            Remove(expression.Name("old"))).
+        Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+
+    req := NewUpdateItemRequest()
+    req.TableName = "asd"
+    req.UpdateKey["id"] = "abc"
+    req.SetExpression(expr)
+    req.Host.Override = strings.TrimPrefix(ts.URL, "https://")
+    req.Host.Region = "us-west-2"
+    req.Key.AccessKeyId = "akey"
+    req.Key.SecretAccessKey = "skey"
+    req.HttpClient = awsgo.CreateCertApprovedClient([]*x509.Certificate{certAsx509})
+    if _, err := req.Request(); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+}
+
+func Test_ExpressionValuesAreNotChanged(t *testing.T) {
+    expr, err := expression.NewBuilder().
+        WithCondition(expression.Name("count").LessThan(int16(5))).
+        Build()
+    if err != nil {
+        t.Fatalf("Build failed: %v", err)
+    }
+
+    req := NewPutItemRequest()
+    req.TableName = "asd"
+    req.Item["id"] = "abc"
+    req.Host.Region = "us-west-2"
+    req.SetExpression(expr)
+    if err := req.VerifyInput(); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    for k, v := range expr.Values {
+        if _, ok := v.(int16); !ok {
+            t.Fatalf("Expression value %s should still be an int16. Got: %T", k, v)
+        }
+        if _, ok := req.ExpressionAttributeValues[k].(awsgo.AwsNumberItem); !ok {
+            t.Fatalf("Request value %s should be a number. Got: %T", k, req.ExpressionAttributeValues[k])
+        }
+    }
+}
+
+func Test_ExpressionValuesUnknownType(t *testing.T) {
+    req := NewPutItemRequest()
+    req.TableName = "asd"
+    req.Item["id"] = "abc"
+    req.Host.Region = "us-west-2"
+    req.ExpressionAttributeValues = map[string]interface{}{":v0": struct{}{}}
+    if err := req.VerifyInput(); err == nil {
+        t.Fatalf("Unknown value types should be an error")
+    }
+}
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -53,6 +54,9 @@ type GetItemRequest struct {
     Search                 map[string]interface{}  `json:"Key"`
     TableName              string
     ReturnConsumedCapacity string
+    // Use SetExpression to fill these in from an expression.Builder
+    ProjectionExpression   string  `json:",omitempty"`
+    ExpressionAttributeNames   map[string]string   `json:",omitempty"`
 }
 
 type GetItemResponse struct {
@@ -161,12 +165,17 @@ func (gir GetItemRequest) CoDoAndDemarshall(request awsgo.AwsRequest, future * G
 }
 
 func (gir GetItemRequest) Request() (*GetItemResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir GetItemRequest) RequestWithContext(ctx context.Context) (*GetItemResponse, error) {
     request, err := awsgo.NewAwsRequest(&gir, gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
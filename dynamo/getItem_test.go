@@ -39,6 +39,7 @@ import (
     "strings"
     "crypto/x509"
     "github.com/fromkeith/awsgo"
+    "time"
 )
 
 func easyFloatCompare(a, b float64) bool {
@@ -600,6 +601,63 @@ func Test_ServerError(t * testing.T) {
     }
 }
 
+func Test_ThrottledIsRetried(t * testing.T) {
+    attempts := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        verifySimpleRequestBody(t, r)
+        attempts ++
+        if attempts < 3 {
+            http.Error(w, `
+                {"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"The level of configured provisioned throughput for the table was exceeded."}
+            `, 400)
+            return
+        }
+        fmt.Fprintf(w, `{"Item":{"blah":{"S":"asdf"}}}`)
+    })
+
+    itemReq := NewGetItemRequest()
+    itemReq.TableName = "asd"
+    itemReq.Search["blah"] = "asdf"
+    itemReq.RetryPolicy = awsgo.DefaultRetryPolicy{ThrottleBaseDelay: time.Millisecond}
+
+    resp, err := doGetItemTest(itemReq, handler)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if attempts != 3 {
+        t.Fatalf("Expected 3 attempts. Got %d", attempts)
+    }
+    if v, ok := resp.Item["blah"]; !ok || v != "asdf" {
+        t.Fatalf("Expected blah to be asdf. Got %v", resp.Item["blah"])
+    }
+}
+
+func Test_ThrottledGivesUp(t * testing.T) {
+    attempts := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        verifySimpleRequestBody(t, r)
+        attempts ++
+        http.Error(w, `
+            {"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"The level of configured provisioned throughput for the table was exceeded."}
+        `, 400)
+    })
+
+    itemReq := NewGetItemRequest()
+    itemReq.TableName = "asd"
+    itemReq.Search["blah"] = "asdf"
+    itemReq.RetryPolicy = awsgo.DefaultRetryPolicy{MaxAttempts: 2, ThrottleBaseDelay: time.Millisecond}
+
+    _, err := doGetItemTest(itemReq, handler)
+    if attempts != 2 {
+        t.Fatalf("Expected 2 attempts. Got %d", attempts)
+    }
+    if errorResult, ok := err.(*ErrorResult); !ok {
+        t.Fatalf("Got wrong error. Expected: ErrorResult. got: %T : %v", err, err)
+    } else if errorResult.Type != ThroughputException {
+        t.Fatalf("Got wrong error. Expected: %v. got: %v", ThroughputException, err)
+    }
+}
+
 func Test_AccessDeniedToTable(t * testing.T) {
     handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
         verifySimpleRequestBody(t, r)
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+
+import (
+    "context"
+    "encoding/json"
+    "github.com/fromkeith/awsgo"
+)
+
+// http://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_ListTables.html
+type ListTablesRequest struct {
+    awsgo.RequestBuilder
+
+    ExclusiveStartTableName     string      `json:",omitempty"`
+    // At most 100
+    Limit                       float64     `json:",omitempty"`
+}
+
+type ListTablesResponse struct {
+    // Set when there are more tables. Use Next to get them
+    LastEvaluatedTableName      string
+    TableNames                  []string
+}
+
+// Creates a new ListTablesRequest, populating in some defaults
+func NewListTablesRequest() *ListTablesRequest {
+    req := new(ListTablesRequest)
+    req.Host.Service = "dynamodb"
+    req.Host.Region = ""
+    req.Host.Domain = "amazonaws.com"
+    req.Key.AccessKeyId = ""
+    req.Key.SecretAccessKey = ""
+    req.Headers = make(map[string]string)
+    req.Headers["X-Amz-Target"] = ListTablesTarget
+    req.RequestMethod = "POST"
+    req.CanonicalUri = "/"
+    return req
+}
+
+func (req * ListTablesRequest) VerifyInput() (error) {
+    if len(req.Host.Service) == 0 {
+        return Verification_Error_ServiceEmpty
+    }
+    if len(req.Host.Region) == 0 {
+        return Verification_Error_RegionEmpty
+    }
+    return nil
+}
+
+
+func (req ListTablesRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) (interface{}) {
+    if err := CheckForErrorResponse(response, statusCode); err != nil {
+        return err
+    }
+    resp := new(ListTablesResponse)
+    err := json.Unmarshal(response, resp)
+    if err != nil {
+        newErr := &awsgo.UnmarhsallingError {
+            ActualContent : string(response),
+            MarshallError : err,
+        }
+        return newErr
+    }
+    return resp
+}
+
+func (gir ListTablesRequest) Request() (*ListTablesResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir ListTablesRequest) RequestWithContext(ctx context.Context) (*ListTablesResponse, error) {
+    request, err := awsgo.NewAwsRequest(&gir, gir)
+    if err != nil {
+        return nil, err
+    }
+    request.RequestSigningType = awsgo.RequestSigningType_AWS4
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
+    if resp == nil {
+        return nil, err
+    }
+    return resp.(*ListTablesResponse), err
+}
+
+// gets the next page of tables, or nil if this was the last page
+func (q * ListTablesResponse) Next(lastRequest *ListTablesRequest) (*ListTablesResponse, error) {
+    return q.NextWithContext(context.Background(), lastRequest)
+}
+
+// Same as Next, but the call is aborted once ctx is done.
+func (q * ListTablesResponse) NextWithContext(ctx context.Context, lastRequest *ListTablesRequest) (*ListTablesResponse, error) {
+    if q.LastEvaluatedTableName == "" {
+        return nil, nil
+    }
+    req := *lastRequest
+    req.RequestBuilder = copyRequestBuilder(lastRequest.RequestBuilder)
+    req.ExclusiveStartTableName = q.LastEvaluatedTableName
+    return req.RequestWithContext(ctx)
+}
@@ -0,0 +1,928 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+import (
+    "encoding"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "github.com/fromkeith/awsgo"
+)
+
+// How Marshal writes time.Time values. Unmarshal reads all of them.
+type TimeFormat int
+
+const (
+    // time.Time.String(), without the monotonic clock reading. Eg. 2006-01-02 15:04:05.999999999 -0700 MST
+    TimeFormat_String TimeFormat = iota
+    // RFC 3339 with nanoseconds, as a string
+    TimeFormat_RFC3339
+    // Seconds since the epoch, as a number. Fractions of a second are kept
+    TimeFormat_UnixTime
+)
+
+const timeStringLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+var (
+    timeFormatLock sync.RWMutex
+    defaultTimeFormat = TimeFormat_String
+)
+
+// Sets how Marshal encodes time.Time. TimeFormat_String is used by default, so existing
+// items keep their format.
+func SetDefaultTimeFormat(format TimeFormat) {
+    timeFormatLock.Lock()
+    defer timeFormatLock.Unlock()
+    defaultTimeFormat = format
+}
+
+func timeFormat() TimeFormat {
+    timeFormatLock.RLock()
+    defer timeFormatLock.RUnlock()
+    return defaultTimeFormat
+}
+
+var (
+    timeType = reflect.TypeOf(time.Time{})
+    bytesType = reflect.TypeOf([]byte(nil))
+    attributeMarshalerType = reflect.TypeOf((*AttributeMarshaler)(nil)).Elem()
+    attributeUnmarshalerType = reflect.TypeOf((*AttributeUnmarshaler)(nil)).Elem()
+    textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+    textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Implemented by types that encode themselves, at any depth of a struct, slice or map.
+// The result can be an awsgo item, eg. awsgo.AwsStringItem, or anything
+// awsgo.ConvertToAwsItem understands, eg. a string or float64.
+type AttributeMarshaler interface {
+    MarshalDynamo() (interface{}, error)
+}
+
+// Implemented by types that decode themselves. value is the raw attribute value,
+// eg. {"S": "abc"}. awsgo.FromRawValue converts it to a plain type.
+type AttributeUnmarshaler interface {
+    UnmarshalDynamo(value map[string]interface{}) error
+}
+
+// Finds the method set that implements iface: v's own, or its address's.
+func implementer(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+    if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(iface) {
+        return v.Addr().Interface(), true
+    }
+    if v.Type().Implements(iface) && v.CanInterface() {
+        if v.Kind() == reflect.Ptr && v.IsNil() {
+            return nil, false
+        }
+        return v.Interface(), true
+    }
+    return nil, false
+}
+
+var (
+    Marshal_Error_NoHashKey = errors.New("No field is tagged hashkey")
+    Marshal_Error_KeyEmpty = errors.New("Key field is empty")
+)
+
+// Options from a dynamo struct tag, eg. `dynamo:"name,omitempty,string"`
+type tagOptions struct {
+    // leave out zero values, not just empty strings and slices
+    omitEmpty   bool
+    // encode string, number and binary slices as SS, NS or BS. The default
+    set         bool
+    // encode string, number and binary slices as L
+    list        bool
+    // encode numbers as S
+    asString    bool
+    // encode time.Time as seconds since the epoch, whatever SetDefaultTimeFormat says
+    unixTime    bool
+    hashKey     bool
+    rangeKey    bool
+}
+
+func parseTag(tag string) (string, tagOptions) {
+    parts := strings.Split(tag, ",")
+    var opts tagOptions
+    for _, opt := range parts[1:] {
+        switch strings.TrimSpace(opt) {
+        case "omitempty":
+            opts.omitEmpty = true
+        case "set":
+            opts.set = true
+        case "list":
+            opts.list = true
+        case "string":
+            opts.asString = true
+        case "unixtime":
+            opts.unixTime = true
+        case "hashkey":
+            opts.hashKey = true
+        case "rangekey":
+            opts.rangeKey = true
+        }
+    }
+    return parts[0], opts
+}
+
+// The options that also apply to each entry of a slice.
+func (opts tagOptions) forEntries() tagOptions {
+    return tagOptions{asString: opts.asString, unixTime: opts.unixTime}
+}
+
+// A field of a struct, including those promoted from embedded structs.
+type structField struct {
+    name        string
+    index       []int
+    opts        tagOptions
+}
+
+// Lists the fields Marshal and Unmarshal use. Fields of embedded structs are promoted,
+// unless the embedded struct has a dynamo tag, or a field of the same name is less deeply
+// embedded.
+func structFields(t reflect.Type) []structField {
+    var fields []structField
+    seen := make(map[string]bool)
+    type level struct {
+        t       reflect.Type
+        index   []int
+    }
+    current := []level{{t, nil}}
+    for len(current) > 0 {
+        var next []level
+        names := make(map[string]bool)
+        for _, l := range current {
+            for i := 0; i < l.t.NumField(); i++ {
+                f := l.t.Field(i)
+                tag := f.Tag.Get("dynamo")
+                if tag == "-" {
+                    continue
+                }
+                name, opts := parseTag(tag)
+                index := append(append([]int{}, l.index...), i)
+                ft := f.Type
+                if ft.Kind() == reflect.Ptr {
+                    ft = ft.Elem()
+                }
+                if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+                    next = append(next, level{ft, index})
+                    continue
+                }
+                if f.PkgPath != "" {
+                    continue // unexported
+                }
+                if name == "" {
+                    name = f.Name
+                }
+                if seen[name] {
+                    continue
+                }
+                names[name] = true
+                fields = append(fields, structField{name, index, opts})
+            }
+        }
+        for name := range names {
+            seen[name] = true
+        }
+        current = next
+    }
+    return fields
+}
+
+// Gets the field at index, returning false if it is inside a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+    for i, x := range index {
+        if i > 0 && v.Kind() == reflect.Ptr {
+            if v.IsNil() {
+                return reflect.Value{}, false
+            }
+            v = v.Elem()
+        }
+        v = v.Field(x)
+    }
+    return v, true
+}
+
+// Gets the field at index, allocating any nil embedded pointers on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+    for i, x := range index {
+        if i > 0 && v.Kind() == reflect.Ptr {
+            if v.IsNil() {
+                if !v.CanSet() {
+                    return reflect.Value{}, errors.New("Cannot set embedded pointer to unexported struct: " + v.Type().String())
+                }
+                v.Set(reflect.New(v.Type().Elem()))
+            }
+            v = v.Elem()
+        }
+        v = v.Field(x)
+    }
+    return v, nil
+}
+
+// Same as MarshalItem, but returns nil instead of the error.
+//
+// Deprecated: errors are silently dropped. Use MarshalItem.
+func Marshal(v interface{}) map[string]interface{} {
+    result, _ := MarshalItem(v)
+    return result
+}
+
+// takes an struct and returns a map that can be used write or put item with
+//      you can rename a field via: `dynamo:"rename"` tag
+//      fields can be omitted via: `dynamo:"-"` tag
+//      options follow the name, eg. `dynamo:"rename,omitempty"` or `dynamo:",string"`
+//          omitempty: zero values are not marshalled
+//          set: string, number and binary slices are marshalled as SS, NS or BS. The default
+//          list: string, number and binary slices are marshalled as L
+//          string: numbers are marshalled as S
+//          unixtime: time.Time is marshalled as seconds since the epoch
+//          hashkey, rangekey: the field is part of the key returned by MarshalKey
+//      empty strings are not marshalled, same for empty arrays, empty maps and nil pointers
+//      bool is marshalled as BOOL, []byte as B and [][]byte as BS
+//      []string and numeric slices are marshalled as SS and NS, other slices as L
+//      structs and maps with string keys are marshalled as M
+//      fields of embedded structs are marshalled as if they were fields of the outer struct
+//      time.Time is marshalled as set by SetDefaultTimeFormat
+//      types implementing AttributeMarshaler marshal themselves, then those implementing
+//          encoding.TextMarshaler are marshalled as S
+// Returns the first error from an AttributeMarshaler, or from json for types without a
+// DynamoDB type.
+func MarshalItem(v interface{}) (map[string]interface{}, error) {
+    reflectVal, ok := addressableStruct(v)
+    if !ok {
+        return nil, errors.New("Can only marshal a struct")
+    }
+    return encodeStruct(reflectVal)
+}
+
+// Dereferences v down to a struct, copying it if needed so methods with pointer receivers
+// can be found.
+func addressableStruct(v interface{}) (reflect.Value, bool) {
+    reflectVal := reflect.ValueOf(v)
+    for reflectVal.Kind() == reflect.Ptr && !reflectVal.IsNil() {
+        reflectVal = reflectVal.Elem()
+    }
+    if !reflectVal.IsValid() || reflectVal.Kind() != reflect.Struct {
+        return reflect.Value{}, false
+    }
+    if !reflectVal.CanAddr() {
+        copied := reflect.New(reflectVal.Type()).Elem()
+        copied.Set(reflectVal)
+        reflectVal = copied
+    }
+    return reflectVal, true
+}
+
+func encodeStruct(v reflect.Value) (map[string]interface{}, error) {
+    result := make(map[string]interface{})
+    for _, f := range structFields(v.Type()) {
+        field, ok := fieldByIndex(v, f.index)
+        if !ok {
+            continue
+        }
+        if f.opts.omitEmpty && isEmptyValue(field) {
+            continue
+        }
+        item, ok, err := encodeValue(field, f.opts)
+        if err != nil {
+            return nil, fmt.Errorf("Cannot encode field: %s: %w", f.name, err)
+        }
+        if ok {
+            result[f.name] = item
+        }
+    }
+    return result, nil
+}
+
+// Calls MarshalDynamo, turning the result into an awsgo item.
+func encodeMarshaler(m AttributeMarshaler) (item interface{}, ok bool, err error) {
+    val, err := m.MarshalDynamo()
+    if err != nil {
+        return nil, false, err
+    }
+    if val == nil {
+        return nil, false, nil
+    }
+    // ConvertToAwsItem panics on types it doesn't know
+    defer func () {
+        if r := recover(); r != nil {
+            item, ok, err = nil, false, fmt.Errorf("MarshalDynamo returned an unknown type: %T", val)
+        }
+    }()
+    return awsgo.ConvertToAwsItem(val), true, nil
+}
+
+// Encodes a single value. Returns false if the value is empty and should be left out.
+func encodeValue(v reflect.Value, opts tagOptions) (interface{}, bool, error) {
+    if !v.IsValid() {
+        return nil, false, nil
+    }
+    if m, ok := implementer(v, attributeMarshalerType); ok {
+        return encodeMarshaler(m.(AttributeMarshaler))
+    }
+    if v.Type() == timeType {
+        format := timeFormat()
+        if opts.unixTime {
+            format = TimeFormat_UnixTime
+        }
+        return encodeTime(v.Interface().(time.Time), format), true, nil
+    }
+    if m, ok := implementer(v, textMarshalerType); ok {
+        text, err := m.(encoding.TextMarshaler).MarshalText()
+        if err != nil || len(text) == 0 {
+            return nil, false, err
+        }
+        return awsgo.AwsStringItem{Value: string(text)}, true, nil
+    }
+    switch v.Kind() {
+    case reflect.Ptr, reflect.Interface:
+        if v.IsNil() {
+            return nil, false, nil
+        }
+        return encodeValue(v.Elem(), opts)
+    case reflect.String:
+        val := v.String()
+        if val == "" {
+            return nil, false, nil
+        }
+        return awsgo.AwsStringItem{
+            Value: val,
+        }, true, nil
+    case reflect.Bool:
+        return awsgo.AwsBoolItem{Value: v.Bool()}, true, nil
+    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8,
+            reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8,
+            reflect.Float32, reflect.Float64:
+        val, str := numberValue(v)
+        if opts.asString {
+            return awsgo.AwsStringItem{Value: str}, true, nil
+        }
+        return awsgo.AwsNumberItem{
+            Value: val, // backwards compat
+            ValueStr: str,
+        }, true, nil
+    case reflect.Array, reflect.Slice:
+        if v.Kind() == reflect.Slice && v.IsNil() {
+            return nil, false, nil
+        }
+        return encodeArray(v, opts)
+    case reflect.Map:
+        if v.IsNil() || v.Len() == 0 {
+            return nil, false, nil
+        }
+        if !isStringKey(v.Type().Key()) {
+            return encodeJson(v)
+        }
+        m := make(map[string]interface{}, v.Len())
+        iter := v.MapRange()
+        for iter.Next() {
+            key, err := mapKeyString(iter.Key())
+            if err != nil {
+                return nil, false, err
+            }
+            item, ok, err := encodeValue(iter.Value(), tagOptions{})
+            if err != nil {
+                return nil, false, err
+            }
+            if ok {
+                m[key] = item
+            }
+        }
+        return awsgo.AwsMapItem{Values: m}, true, nil
+    case reflect.Struct:
+        // copy so methods with pointer receivers on the fields can be found
+        if !v.CanAddr() {
+            copied := reflect.New(v.Type()).Elem()
+            copied.Set(v)
+            v = copied
+        }
+        m, err := encodeStruct(v)
+        if err != nil {
+            return nil, false, err
+        }
+        return awsgo.AwsMapItem{Values: m}, true, nil
+    }
+    return encodeJson(v)
+}
+
+// A number as a float, and as the string sent to DynamoDB.
+func numberValue(v reflect.Value) (float64, string) {
+    switch v.Kind() {
+    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
+        return float64(v.Int()), strconv.FormatInt(v.Int(), 10)
+    case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
+        return float64(v.Uint()), strconv.FormatUint(v.Uint(), 10)
+    }
+    return v.Float(), fmt.Sprintf("%f", v.Float())
+}
+
+// The zero values left out by omitempty.
+func isEmptyValue(v reflect.Value) bool {
+    switch v.Kind() {
+    case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+        return v.Len() == 0
+    case reflect.Ptr, reflect.Interface:
+        return v.IsNil()
+    }
+    return v.IsZero()
+}
+
+// Map keys can be strings, or implement encoding.TextMarshaler.
+func isStringKey(t reflect.Type) bool {
+    return t.Kind() == reflect.String || t.Implements(textMarshalerType)
+}
+
+func mapKeyString(key reflect.Value) (string, error) {
+    if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+        text, err := m.MarshalText()
+        return string(text), err
+    }
+    return key.String(), nil
+}
+
+// The old encoding of anything without a dynamo type: a json string.
+func encodeJson(v reflect.Value) (interface{}, bool, error) {
+    enc, err := json.Marshal(v.Interface())
+    if err != nil {
+        return nil, false, err
+    }
+    return awsgo.AwsStringItem{
+        Value: string(enc),
+    }, true, nil
+}
+
+func encodeTime(t time.Time, format TimeFormat) interface{} {
+    switch format {
+    case TimeFormat_RFC3339:
+        return awsgo.AwsStringItem{Value: t.Format(time.RFC3339Nano)}
+    case TimeFormat_UnixTime:
+        return awsgo.AwsNumberItem{
+            Value: float64(t.UnixNano()) / 1e9, // backwards compat
+            ValueStr: unixTimeString(t),
+        }
+    }
+    return awsgo.AwsStringItem{Value: t.Format(timeStringLayout)}
+}
+
+// Seconds since the epoch, with any fraction written out exactly.
+func unixTimeString(t time.Time) string {
+    str := strconv.FormatInt(t.Unix(), 10)
+    if nanos := t.Nanosecond(); nanos != 0 {
+        str += strings.TrimRight(fmt.Sprintf(".%09d", nanos), "0")
+    }
+    return str
+}
+
+func encodeArray(v reflect.Value, opts tagOptions) (interface{}, bool, error) {
+    if v.Len() == 0 {
+        return nil, false, nil
+    }
+    if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 && !encodesItself(v.Type().Elem()) {
+        return awsgo.AwsBinaryItem{Value: v.Bytes()}, true, nil
+    }
+    if !opts.list {
+        if set, ok := encodeSet(v, opts); ok {
+            return set, true, nil
+        }
+    }
+    list := make([]interface{}, v.Len())
+    for i := range list {
+        item, ok, err := encodeValue(v.Index(i), opts.forEntries())
+        if err != nil {
+            return nil, false, err
+        }
+        if !ok {
+            // keep the position of empty entries
+            item = awsgo.AwsNullItem{Null: true}
+        }
+        list[i] = item
+    }
+    return awsgo.AwsListItem{Values: list}, true, nil
+}
+
+// True if values of t, or pointers to them, have their own encoding.
+func encodesItself(t reflect.Type) bool {
+    pt := reflect.PtrTo(t)
+    return t.Implements(attributeMarshalerType) || pt.Implements(attributeMarshalerType) ||
+        t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// Encodes string, number and binary slices as SS, NS or BS. Returns false for other slices,
+// including those whose entries encode themselves.
+func encodeSet(v reflect.Value, opts tagOptions) (interface{}, bool) {
+    elemType := v.Type().Elem()
+    if encodesItself(elemType) {
+        return nil, false
+    }
+    if elemType == bytesType {
+        values := make([][]byte, v.Len())
+        for i := range values {
+            values[i] = v.Index(i).Bytes()
+        }
+        return awsgo.AwsBinaryItem{Values: values}, true
+    }
+    switch elemType.Kind() {
+    case reflect.String:
+        strArray := make([]string, v.Len())
+        for i := range strArray {
+            strArray[i] = v.Index(i).String()
+        }
+        return awsgo.AwsStringItem{
+            Values: strArray,
+        }, true
+    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8,
+            reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8,
+            reflect.Float32, reflect.Float64:
+        strArray := make([]string, v.Len())
+        for i := range strArray {
+            _, strArray[i] = numberValue(v.Index(i))
+        }
+        if opts.asString {
+            return awsgo.AwsStringItem{
+                Values: strArray,
+            }, true
+        }
+        return awsgo.AwsNumberItem{
+            ValuesStr: strArray,
+        }, true
+    }
+    return nil, false
+}
+
+
+// Unmarshalls a JSON response from AWS.
+// Understands everything Marshal writes, as well as structs, slices and maps that older
+// versions stored as json strings.
+func Unmarshal(in map[string]map[string]interface{}, out interface{}) error {
+    reflectVal := reflect.ValueOf(out)
+    if !reflectVal.IsValid() {
+        return errors.New("Out is not valid")
+    }
+    reflectType := reflectVal.Type()
+    if reflectType.Kind() != reflect.Ptr {
+        return errors.New("Out is not valid pointer")
+    }
+    reflectVal = reflectVal.Elem()
+    reflectType = reflectVal.Type()
+    if reflectType.Kind() != reflect.Struct {
+        return errors.New("Out is not valid pointer to a struct")
+    }
+    return decodeStruct(in, reflectVal)
+}
+
+func decodeStruct(in map[string]map[string]interface{}, v reflect.Value) error {
+    for _, f := range structFields(v.Type()) {
+        item, ok := in[f.name]
+        if !ok {
+            continue
+        }
+        field, err := fieldByIndexAlloc(v, f.index)
+        if err != nil {
+            return err
+        }
+        if err := decodeValue(item, field); err != nil {
+            return fmt.Errorf("Cannot decode field: %s: %w", f.name, err)
+        }
+    }
+    return nil
+}
+
+// The raw attribute values in a M or L
+func rawMap(in interface{}) map[string]map[string]interface{} {
+    m, _ := in.(map[string]interface{})
+    result := make(map[string]map[string]interface{}, len(m))
+    for k, v := range m {
+        result[k], _ = v.(map[string]interface{})
+    }
+    return result
+}
+
+// Decodes one attribute value into v.
+func decodeValue(in map[string]interface{}, v reflect.Value) error {
+    if in == nil {
+        return nil
+    }
+    if _, ok := in["NULL"]; ok {
+        v.Set(reflect.Zero(v.Type()))
+        return nil
+    }
+    if v.Kind() != reflect.Ptr {
+        if u, ok := implementer(v, attributeUnmarshalerType); ok {
+            return u.(AttributeUnmarshaler).UnmarshalDynamo(in)
+        }
+    }
+    if v.Type() == timeType {
+        return decodeTime(in, v)
+    }
+    if asStr, ok := in["S"].(string); ok && v.Kind() != reflect.Ptr {
+        if u, ok := implementer(v, textUnmarshalerType); ok {
+            if err := u.(encoding.TextUnmarshaler).UnmarshalText([]byte(asStr)); err != nil {
+                // older versions stored these as json strings
+                if jerr := decodeJson(in, v); jerr != nil {
+                    return err
+                }
+            }
+            return nil
+        }
+    }
+    switch v.Kind() {
+    case reflect.Ptr:
+        if v.IsNil() {
+            v.Set(reflect.New(v.Type().Elem()))
+        }
+        return decodeValue(in, v.Elem())
+    case reflect.Interface:
+        if v.NumMethod() != 0 {
+            break
+        }
+        if val := awsgo.FromRawValue(in); val != nil {
+            v.Set(reflect.ValueOf(val))
+        }
+        return nil
+    case reflect.String:
+        if asStr, ok := in["S"].(string); ok {
+            v.SetString(asStr)
+        }
+        return nil
+    case reflect.Bool:
+        if asBool, ok := in["BOOL"].(bool); ok {
+            v.SetBool(asBool)
+        } else if asStr, ok := in["S"].(string); ok {
+            // older versions stored these as json strings, "true" or "false"
+            asBool, err := strconv.ParseBool(asStr)
+            if err != nil {
+                return err
+            }
+            v.SetBool(asBool)
+        }
+        return nil
+    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
+        if asNum, ok := numberString(in); ok {
+            asInt, err := parseInt(asNum)
+            if err != nil {
+                return err
+            }
+            v.SetInt(asInt)
+        }
+        return nil
+    case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
+        if asNum, ok := numberString(in); ok {
+            asInt, err := parseUint(asNum)
+            if err != nil {
+                return err
+            }
+            v.SetUint(asInt)
+        }
+        return nil
+    case reflect.Float32, reflect.Float64:
+        if asNum, ok := numberString(in); ok {
+            asFloat, err := strconv.ParseFloat(asNum, 64)
+            if err != nil {
+                return err
+            }
+            v.SetFloat(asFloat)
+        }
+        return nil
+    case reflect.Array, reflect.Slice:
+        return decodeArray(in, v)
+    case reflect.Map:
+        if m, ok := in["M"]; ok {
+            keyType := v.Type().Key()
+            textKey := reflect.PtrTo(keyType).Implements(textUnmarshalerType)
+            if keyType.Kind() != reflect.String && !textKey {
+                return errors.New("Cannot decode M into map with non string keys: " + v.Type().String())
+            }
+            raw := rawMap(m)
+            result := reflect.MakeMapWithSize(v.Type(), len(raw))
+            for k, entry := range raw {
+                elem := reflect.New(v.Type().Elem()).Elem()
+                if err := decodeValue(entry, elem); err != nil {
+                    return err
+                }
+                key := reflect.New(keyType)
+                if textKey {
+                    if err := key.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+                        return err
+                    }
+                } else {
+                    key.Elem().Set(reflect.ValueOf(k).Convert(keyType))
+                }
+                result.SetMapIndex(key.Elem(), elem)
+            }
+            v.Set(result)
+            return nil
+        }
+    case reflect.Struct:
+        if m, ok := in["M"]; ok {
+            return decodeStruct(rawMap(m), v)
+        }
+    }
+    return decodeJson(in, v)
+}
+
+// The old encoding of anything without a dynamo type: a json string.
+func decodeJson(in map[string]interface{}, v reflect.Value) error {
+    asStr, ok := in["S"].(string)
+    if !ok {
+        return errors.New("Could not decode item into " + v.Type().String())
+    }
+    as := reflect.New(v.Type())
+    if err := json.Unmarshal([]byte(asStr), as.Interface()); err != nil {
+        return err
+    }
+    v.Set(as.Elem())
+    return nil
+}
+
+// A number from N, or from S for fields tagged string.
+func numberString(in map[string]interface{}) (string, bool) {
+    if asNum, ok := in["N"].(string); ok {
+        return asNum, true
+    }
+    asStr, ok := in["S"].(string)
+    return asStr, ok
+}
+
+// Numbers written by awsgo.ConvertToAwsItem always have a fraction, eg. 5.000000
+func parseInt(str string) (int64, error) {
+    asInt, err := strconv.ParseInt(str, 10, 64)
+    if err == nil {
+        return asInt, nil
+    }
+    if asFloat, ferr := strconv.ParseFloat(str, 64); ferr == nil && asFloat == float64(int64(asFloat)) {
+        return int64(asFloat), nil
+    }
+    return 0, err
+}
+
+func parseUint(str string) (uint64, error) {
+    asInt, err := strconv.ParseUint(str, 10, 64)
+    if err == nil {
+        return asInt, nil
+    }
+    if asFloat, ferr := strconv.ParseFloat(str, 64); ferr == nil && asFloat >= 0 && asFloat == float64(uint64(asFloat)) {
+        return uint64(asFloat), nil
+    }
+    return 0, err
+}
+
+func decodeTime(in map[string]interface{}, v reflect.Value) error {
+    if asNum, ok := in["N"].(string); ok {
+        t, err := parseUnixTime(asNum)
+        if err != nil {
+            return err
+        }
+        v.Set(reflect.ValueOf(t))
+        return nil
+    }
+    asStr, ok := in["S"].(string)
+    if !ok {
+        return errors.New("Could not decode time")
+    }
+    // older versions wrote time.Time.String(), including the monotonic clock reading
+    if at := strings.Index(asStr, " m="); at >= 0 {
+        asStr = asStr[:at]
+    }
+    t, err := time.Parse(time.RFC3339Nano, asStr)
+    if err != nil {
+        t, err = time.Parse(timeStringLayout, asStr)
+        if err != nil {
+            return err
+        }
+    }
+    v.Set(reflect.ValueOf(t))
+    return nil
+}
+
+func parseUnixTime(str string) (time.Time, error) {
+    secStr, fracStr := str, ""
+    if at := strings.IndexByte(str, '.'); at >= 0 {
+        secStr, fracStr = str[:at], str[at + 1:]
+    }
+    sec, err := strconv.ParseInt(secStr, 10, 64)
+    if err != nil {
+        return time.Time{}, err
+    }
+    var nanos int64
+    if fracStr != "" {
+        if len(fracStr) > 9 {
+            fracStr = fracStr[:9]
+        }
+        fracStr += strings.Repeat("0", 9 - len(fracStr))
+        if nanos, err = strconv.ParseInt(fracStr, 10, 64); err != nil {
+            return time.Time{}, err
+        }
+        if strings.HasPrefix(secStr, "-") {
+            nanos = -nanos
+        }
+    }
+    return time.Unix(sec, nanos), nil
+}
+
+func decodeArray(in map[string]interface{}, v reflect.Value) error {
+    if in == nil {
+        return nil
+    }
+    // any byte slice, including named ones like json.RawMessage
+    if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+        if asStr, ok := in["B"].(string); ok {
+            b, err := base64.StdEncoding.DecodeString(asStr)
+            if err != nil {
+                return err
+            }
+            v.Set(reflect.ValueOf(b).Convert(v.Type()))
+            return nil
+        }
+    }
+    for kind, entryKind := range map[string]string{"SS": "S", "NS": "N", "BS": "B", "L": ""} {
+        list, ok := in[kind].([]interface{})
+        if !ok {
+            continue
+        }
+        if v.Kind() == reflect.Slice {
+            v.Set(reflect.MakeSlice(v.Type(), len(list), len(list)))
+        } else if len(list) > v.Len() {
+            return fmt.Errorf("Cannot decode %d entries into %s", len(list), v.Type().String())
+        }
+        for i := range list {
+            var entry map[string]interface{}
+            if entryKind == "" {
+                entry, _ = list[i].(map[string]interface{})
+            } else {
+                entry = map[string]interface{}{entryKind: list[i]}
+            }
+            if err := decodeValue(entry, v.Index(i)); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    return decodeJson(in, v)
+}
+
+// Returns the fields tagged hashkey and rangekey, ready to use as GetItemRequest.Search,
+// DeleteItemRequest.DeleteKey or UpdateItemRequest.UpdateKey.
+func MarshalKey(v interface{}) (map[string]interface{}, error) {
+    reflectVal, ok := addressableStruct(v)
+    if !ok {
+        return nil, errors.New("Can only marshal the key of a struct")
+    }
+    key := make(map[string]interface{})
+    hasHash := false
+    for _, f := range structFields(reflectVal.Type()) {
+        if !f.opts.hashKey && !f.opts.rangeKey {
+            continue
+        }
+        hasHash = hasHash || f.opts.hashKey
+        field, ok := fieldByIndex(reflectVal, f.index)
+        if !ok {
+            return nil, fmt.Errorf("%w: %s", Marshal_Error_KeyEmpty, f.name)
+        }
+        item, ok, err := encodeValue(field, f.opts)
+        if err != nil {
+            return nil, fmt.Errorf("Cannot encode field: %s: %w", f.name, err)
+        }
+        if !ok {
+            return nil, fmt.Errorf("%w: %s", Marshal_Error_KeyEmpty, f.name)
+        }
+        key[f.name] = item
+    }
+    if !hasHash {
+        return nil, Marshal_Error_NoHashKey
+    }
+    return key, nil
+}
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -46,6 +47,10 @@ type PutItemRequest struct {
     ReturnConsumedCapacity  string
     ReturnItemCollectionMetrics  string
     ReturnValues            string
+    // Use SetExpression to fill these in from an expression.Builder
+    ConditionExpression     string  `json:",omitempty"`
+    ExpressionAttributeNames    map[string]string   `json:",omitempty"`
+    ExpressionAttributeValues   map[string]interface{}  `json:",omitempty"`
 }
 
 
@@ -109,10 +114,15 @@ func (pir * PutItemRequest) VerifyInput() (error) {
             pir.Expected[k] = ExpectedItem{v.Exists, awsgo.ConvertToAwsItem(v.Value)}
         }
     }
+    values, err := convertExpressionValues(pir.ExpressionAttributeValues)
+    if err != nil {
+        return err
+    }
+    pir.ExpressionAttributeValues = values
     return nil
 }
 func (pir PutItemRequest) CoRequest() (*PutItemResponseFuture, error) {
-    request, err := awsgo.NewAwsRequest(&pir, pir)
+    request, err := awsgo.NewAwsRequest(&pir, &pir)
     if err != nil {
         return nil, err
     }
@@ -157,12 +167,17 @@ func (pir PutItemRequest) CoDoAndDemarshall(request awsgo.AwsRequest, future * P
 }
 
 func (pir PutItemRequest) Request() (*PutItemResponse, error) {
-    request, err := awsgo.NewAwsRequest(&pir, pir)
+    return pir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (pir PutItemRequest) RequestWithContext(ctx context.Context) (*PutItemResponse, error) {
+    request, err := awsgo.NewAwsRequest(&pir, &pir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&pir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &pir)
     if resp == nil {
         return nil, err
     }
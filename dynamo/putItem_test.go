@@ -41,6 +41,7 @@ import (
     "crypto/x509"
     "github.com/fromkeith/awsgo"
     "encoding/json"
+    "errors"
     "bytes"
 )
 
@@ -200,6 +201,33 @@ func Test_BadResponse(t * testing.T) {
 }
 
 
+func Test_ConditionalCheckFailedIsAPIError(t * testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        w.Header().Set("x-amzn-RequestId", "req-1")
+        w.WriteHeader(400)
+        fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+    })
+
+    itemReq := NewPutItemRequest()
+    itemReq.TableName = "asd"
+    itemReq.Item["blah"] = "asdf"
+
+    _, err := doPutItemTest(itemReq, handler)
+    if !IsConditionalCheckFailed(err) || IsResourceNotFound(err) {
+        t.Fatalf("Expected a conditional check failure. Got: %v", err)
+    }
+    var apiErr awsgo.APIError
+    if !errors.As(err, &apiErr) {
+        t.Fatalf("Expected an APIError. Got: %v", err)
+    }
+    if apiErr.ErrorCode() != "ConditionalCheckFailedException" || apiErr.ErrorMessage() != "The conditional request failed" {
+        t.Fatalf("Unexpected error: %v", apiErr)
+    }
+    if apiErr.HttpStatusCode() != 400 || apiErr.RequestID() != "req-1" || apiErr.Retryable() || apiErr.Throttling() {
+        t.Fatalf("Unexpected error details: %+v", apiErr)
+    }
+}
+
 func Test_WorkingPutSingleItem_WithExpected(t * testing.T) {
 
     handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
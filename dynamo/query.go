@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -50,6 +51,12 @@ type QueryRequest struct {
     ScanIndexForward        *bool       `json:",omitempty"`
     Select                  string      `json:",omitempty"`
     TableName               string
+    // Use SetExpression to fill these in from an expression.Builder
+    KeyConditionExpression  string      `json:",omitempty"`
+    FilterExpression        string      `json:",omitempty"`
+    ProjectionExpression    string      `json:",omitempty"`
+    ExpressionAttributeNames    map[string]string   `json:",omitempty"`
+    ExpressionAttributeValues   map[string]interface{}  `json:",omitempty"`
 }
 
 type QueryResponse struct {
@@ -104,6 +111,11 @@ func (gir * QueryRequest) VerifyInput() (error) {
     for k, v := range(gir.ExclusiveStartKey) {
         gir.ExclusiveStartKey[k] = awsgo.ConvertToAwsItem(v)
     }
+    values, err := convertExpressionValues(gir.ExpressionAttributeValues)
+    if err != nil {
+        return err
+    }
+    gir.ExpressionAttributeValues = values
     return nil
 }
 
@@ -129,12 +141,17 @@ func (gir QueryRequest) DeMarshalResponse(response []byte, headers map[string]st
 }
 
 func (gir QueryRequest) Request() (*QueryResponse, error) {
-    request, err := awsgo.NewAwsRequest(&gir, gir)
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir QueryRequest) RequestWithContext(ctx context.Context) (*QueryResponse, error) {
+    request, err := awsgo.NewAwsRequest(&gir, &gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -143,10 +160,17 @@ func (gir QueryRequest) Request() (*QueryResponse, error) {
 
 // evalutes the query again, setting the LastEvaluatedKey
 func (q * QueryResponse) Next(lastRequest *QueryRequest) (*QueryResponse, error) {
+    return q.NextWithContext(context.Background(), lastRequest)
+}
+
+// Same as Next, but the call is aborted once ctx is done.
+func (q * QueryResponse) NextWithContext(ctx context.Context, lastRequest *QueryRequest) (*QueryResponse, error) {
     if len(q.LastEvaluatedKey) == 0 {
         return nil, nil
     }
     req := NewQueryRequest()
+    // keep the Client's config, region, keys and endpoint
+    req.RequestBuilder = copyRequestBuilder(lastRequest.RequestBuilder)
     // copy the last requests stuff
     req.AttributesToGet = lastRequest.AttributesToGet
     req.ConsistentRead = lastRequest.ConsistentRead
@@ -157,11 +181,12 @@ func (q * QueryResponse) Next(lastRequest *QueryRequest) (*QueryResponse, error)
     req.Select = lastRequest.Select
     req.TableName = lastRequest.TableName
     req.Limit = lastRequest.Limit
-    req.HttpClient = lastRequest.HttpClient
-    // std attributes
-    req.Host.Region = lastRequest.Host.Region
-    req.Key = lastRequest.Key
+    req.KeyConditionExpression = lastRequest.KeyConditionExpression
+    req.FilterExpression = lastRequest.FilterExpression
+    req.ProjectionExpression = lastRequest.ProjectionExpression
+    req.ExpressionAttributeNames = lastRequest.ExpressionAttributeNames
+    req.ExpressionAttributeValues = lastRequest.ExpressionAttributeValues
     // set our exclusive key
     req.ExclusiveStartKey = q.LastEvaluatedKey
-    return req.Request()
+    return req.RequestWithContext(ctx)
 }
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+
+import (
+    "testing"
+    "net/http"
+    "net/http/httptest"
+    "fmt"
+    "io/ioutil"
+    "strings"
+    "github.com/fromkeith/awsgo"
+    "time"
+)
+
+
+func Test_QueryNextKeepsClientConfig(t * testing.T) {
+    call := 0
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r * http.Request) {
+        call ++
+        if !strings.Contains(r.Header.Get("Authorization"), "Credential=clientKey/") {
+            t.Errorf("Page %d wasn't signed with the Client's credentials: %s", call, r.Header.Get("Authorization"))
+        }
+        defer r.Body.Close()
+        body, _ := ioutil.ReadAll(r.Body)
+        if call == 1 {
+            if strings.Contains(string(body), "ExclusiveStartKey") {
+                t.Errorf("First page shouldn't have an ExclusiveStartKey: %s", string(body))
+            }
+            fmt.Fprintf(w, `{"Count":1,"Items":[{"id":{"S":"a"}}],"LastEvaluatedKey":{"id":{"S":"a"}}}`)
+            return
+        }
+        if !strings.Contains(string(body), `"ExclusiveStartKey":{"id":{"S":"a"}}`) {
+            t.Errorf("Second page should start after a: %s", string(body))
+        }
+        if !strings.Contains(string(body), `"KeyConditions":{"id":`) {
+            t.Errorf("Second page lost the key condition: %s", string(body))
+        }
+        fmt.Fprintf(w, `{"Count":1,"Items":[{"id":{"S":"b"}}]}`)
+    })
+    ts := httptest.NewServer(handler)
+    defer ts.Close()
+
+    client := NewClient(awsgo.Config{
+        Region: "us-west-2",
+        CredentialsProvider: awsgo.StaticCredentialsProvider{Key: awsgo.NewCredentials("clientKey", "skey", "", time.Time{})},
+        EndpointResolver: awsgo.EndpointResolverFunc(func (host awsgo.AwsHost) (awsgo.Endpoint, error) {
+            return awsgo.Endpoint{URL: ts.URL}, nil
+        }),
+        RetryPolicy: awsgo.NoRetryPolicy{},
+    })
+    req := client.NewQueryRequest()
+    req.TableName = "things"
+    req.KeyConditions = map[string]KeyConditions{
+        "id": {AttributeValueList: []interface{}{"a"}, ComparisonOperator: "EQ"},
+    }
+
+    var ids []string
+    resp, err := req.Request()
+    for resp != nil && err == nil {
+        for _, item := range resp.Items {
+            ids = append(ids, item["id"].(string))
+        }
+        resp, err = resp.Next(req)
+    }
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if call != 2 || strings.Join(ids, ",") != "a,b" {
+        t.Fatalf("Expected 2 pages with a,b. Got %d calls and %v", call, ids)
+    }
+}
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -50,6 +51,11 @@ type ScanRequest struct {
     Select                  string      `json:",omitempty"`
     TableName               string
     TotalSegments           *int         `json:",omitempty"`
+    // Use SetExpression to fill these in from an expression.Builder
+    FilterExpression        string      `json:",omitempty"`
+    ProjectionExpression    string      `json:",omitempty"`
+    ExpressionAttributeNames    map[string]string   `json:",omitempty"`
+    ExpressionAttributeValues   map[string]interface{}  `json:",omitempty"`
 }
 
 type ScanResponse struct {
@@ -103,6 +109,11 @@ func (gir * ScanRequest) VerifyInput() (error) {
     for k, v := range(gir.ExclusiveStartKey) {
         gir.ExclusiveStartKey[k] = awsgo.ConvertToAwsItem(v)
     }
+    values, err := convertExpressionValues(gir.ExpressionAttributeValues)
+    if err != nil {
+        return err
+    }
+    gir.ExpressionAttributeValues = values
     return nil
 }
 
@@ -128,12 +139,17 @@ func (gir ScanRequest) DeMarshalResponse(response []byte, headers map[string]str
 }
 
 func (gir ScanRequest) Request() (*ScanResponse, error) {
-    request, err := awsgo.NewAwsRequest(&gir, gir)
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir ScanRequest) RequestWithContext(ctx context.Context) (*ScanResponse, error) {
+    request, err := awsgo.NewAwsRequest(&gir, &gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -143,10 +159,17 @@ func (gir ScanRequest) Request() (*ScanResponse, error) {
 
 // evalutes the query again, setting the LastEvaluatedKey
 func (q * ScanResponse) Next(lastRequest *ScanRequest) (*ScanResponse, error) {
+    return q.NextWithContext(context.Background(), lastRequest)
+}
+
+// Same as Next, but the call is aborted once ctx is done.
+func (q * ScanResponse) NextWithContext(ctx context.Context, lastRequest *ScanRequest) (*ScanResponse, error) {
     if len(q.LastEvaluatedKey) == 0 {
         return nil, nil
     }
     req := NewScanRequest()
+    // keep the Client's config, region, keys and endpoint
+    req.RequestBuilder = copyRequestBuilder(lastRequest.RequestBuilder)
     // copy the last requests stuff
     req.AttributesToGet = lastRequest.AttributesToGet
     req.Limit = lastRequest.Limit
@@ -156,11 +179,11 @@ func (q * ScanResponse) Next(lastRequest *ScanRequest) (*ScanResponse, error) {
     req.Select = lastRequest.Select
     req.TableName = lastRequest.TableName
     req.TotalSegments = lastRequest.TotalSegments
-    // std attributes
-    req.Host.Region = lastRequest.Host.Region
-    req.Key = lastRequest.Key
-    req.HttpClient = lastRequest.HttpClient
+    req.FilterExpression = lastRequest.FilterExpression
+    req.ProjectionExpression = lastRequest.ProjectionExpression
+    req.ExpressionAttributeNames = lastRequest.ExpressionAttributeNames
+    req.ExpressionAttributeValues = lastRequest.ExpressionAttributeValues
     // set our exclusive key
     req.ExclusiveStartKey = q.LastEvaluatedKey
-    return req.Request()
+    return req.RequestWithContext(ctx)
 }
\ No newline at end of file
@@ -34,10 +34,9 @@ import (
     "encoding/json"
     "fmt"
     "strconv"
-    "reflect"
+    "sort"
     "github.com/fromkeith/awsgo"
     "errors"
-    "time"
 )
 
 // Variable Constants
@@ -87,6 +86,9 @@ const (
     ScanTarget = "DynamoDB_20120810.Scan"
     DescribeTableTarget = "DynamoDB_20120810.DescribeTable"
     UpdateTableTarget = "DynamoDB_20120810.UpdateTable"
+    CreateTableTarget = "DynamoDB_20120810.CreateTable"
+    DeleteTableTarget = "DynamoDB_20120810.DeleteTable"
+    ListTablesTarget = "DynamoDB_20120810.ListTables"
 )
 // Known Errors
 const (
@@ -110,6 +112,61 @@ type CapacityResult struct {
     LocalSecondaryIndexes   map[string]CapacityUnitsStruct
 }
 
+// The total units used, or 0 if capacity wasn't returned.
+func (c *CapacityResult) units() float64 {
+    if c == nil {
+        return 0
+    }
+    return c.CapacityUnits
+}
+
+// Lets awsgo.MetricsHook see the capacity each request used.
+func (r GetItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r PutItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r DeleteItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r UpdateItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r QueryResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r ScanResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r BatchGetItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+func (r BatchWriteItemResponse) ConsumedCapacityUnits() float64 { return r.ConsumedCapacity.units() }
+
+func tableSpanAttributes(tables ...string) []awsgo.SpanAttribute {
+    return []awsgo.SpanAttribute{{Key: "aws.dynamodb.table_names", Value: tables}}
+}
+
+func sortedTableNames(names []string) []awsgo.SpanAttribute {
+    sort.Strings(names)
+    return tableSpanAttributes(names...)
+}
+
+// Lets an awsgo.Tracer know which tables a request touches.
+func (gir GetItemRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir PutItemRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir DeleteItemRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir UpdateItemRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir QueryRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir ScanRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir DescribeTableRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir UpdateTableRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir CreateTableRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+func (gir DeleteTableRequest) SpanAttributes() []awsgo.SpanAttribute { return tableSpanAttributes(gir.TableName) }
+
+func (gir BatchGetItemRequest) SpanAttributes() []awsgo.SpanAttribute {
+    names := make([]string, 0, len(gir.RequestItems))
+    for name := range gir.RequestItems {
+        names = append(names, name)
+    }
+    return sortedTableNames(names)
+}
+
+func (gir BatchWriteItemRequest) SpanAttributes() []awsgo.SpanAttribute {
+    names := make([]string, 0, len(gir.RequestItems))
+    for name := range gir.RequestItems {
+        names = append(names, name)
+    }
+    return sortedTableNames(names)
+}
+
 type ExpectedItem struct {
     Exists  bool        `json:",string"`
     Value   interface{} `json:",omitempty"`
@@ -127,16 +184,64 @@ type ItemCollectionMetricsStruct struct {
     SizeEstimateRangeGB         []string
 }
 
+// Sentinels for the common DynamoDB errors, for use with errors.Is
+var (
+    ErrConditionalCheckFailed = awsgo.ErrorCode("ConditionalCheckFailedException")
+    ErrProvisionedThroughputExceeded = awsgo.ErrorCode("ProvisionedThroughputExceededException")
+    ErrResourceNotFound = awsgo.ErrorCode("ResourceNotFoundException")
+    ErrResourceInUse = awsgo.ErrorCode("ResourceInUseException")
+    ErrValidation = awsgo.ErrorCode("ValidationException")
+    ErrItemCollectionSizeLimitExceeded = awsgo.ErrorCode("ItemCollectionSizeLimitExceededException")
+)
+
 type ErrorResult struct {
     Type        string  `json:"__type"`
     Message     string  `json:"message"`
     StatusCode  int
+    RequestId   string
 }
 
 func (e * ErrorResult) Error() string {
     return fmt.Sprintf("%s : %s", e.Type, e.Message)
 }
 
+func (e * ErrorResult) ErrorCode() string { return awsgo.StripErrorNamespace(e.Type) }
+func (e * ErrorResult) ErrorMessage() string { return e.Message }
+func (e * ErrorResult) HttpStatusCode() int { return e.StatusCode }
+func (e * ErrorResult) RequestID() string { return e.RequestId }
+func (e * ErrorResult) Retryable() bool { return awsgo.IsRetryableError(e.ErrorCode(), e.StatusCode) }
+func (e * ErrorResult) Throttling() bool { return awsgo.IsThrottlingError(e.ErrorCode(), e.StatusCode) }
+func (e * ErrorResult) Is(target error) bool { return awsgo.MatchesErrorCode(e, target) }
+
+func (e * ErrorResult) SetResponseInfo(statusCode int, requestId string) {
+    if e.StatusCode == 0 {
+        e.StatusCode = statusCode
+    }
+    if e.RequestId == "" {
+        e.RequestId = requestId
+    }
+}
+
+// True if err is a failed condition on a put, update or delete.
+func IsConditionalCheckFailed(err error) bool {
+    return errors.Is(err, ErrConditionalCheckFailed)
+}
+
+// True if err means the table's (or index's) provisioned throughput was exceeded.
+func IsProvisionedThroughputExceeded(err error) bool {
+    return errors.Is(err, ErrProvisionedThroughputExceeded)
+}
+
+// True if err means the table or index doesn't exist, or isn't active yet.
+func IsResourceNotFound(err error) bool {
+    return errors.Is(err, ErrResourceNotFound)
+}
+
+// True if err means the request was malformed, Eg. a missing key attribute.
+func IsValidation(err error) bool {
+    return errors.Is(err, ErrValidation)
+}
+
 func CheckForErrorResponse(response []byte, statusCode int) error {
     errorResult := new(ErrorResult)
     err2 := json.Unmarshal([]byte(response), errorResult)
@@ -154,6 +259,18 @@ func CheckForErrorResponse(response []byte, statusCode int) error {
     return nil
 }
 
+// Copies a RequestBuilder for a follow up call, Eg. the next page of a Query.
+// Everything the Client set is kept. The copy gets its own Headers map, as sending a
+// request writes its Content-Length there.
+func copyRequestBuilder(rb awsgo.RequestBuilder) awsgo.RequestBuilder {
+    theCopy := rb
+    theCopy.Headers = make(map[string]string)
+    for k, v := range rb.Headers {
+        theCopy.Headers[k] = v
+    }
+    return theCopy
+}
+
 // returns the value in the map if it exists, otherise 'elze' value is returned
 func AsStringOr(item map[string]interface{}, key, elze string) string {
     if v, ok := item[key].(string); ok {
@@ -171,9 +288,13 @@ func AsFloatOr(item map[string]interface{}, key string, elze float64) float64 {
 }
 
 // parses the value to be a boolean. using strconv.
+// if it is a BOOL it is returned as is
 // if it is a float then it returns true on the value != 0
 // if the value doesn't exit, returns elze
 func AsBoolOr(item map[string]interface{}, key string, elze bool) bool {
+    if v, ok := item[key].(bool); ok {
+        return v
+    }
     if v, ok := item[key].(string); ok {
         if b, bok := strconv.ParseBool(v); bok == nil {
             return b
@@ -185,292 +306,3 @@ func AsBoolOr(item map[string]interface{}, key string, elze bool) bool {
     }
     return elze
 }
-
-
-// Unmarshalls a JSON response from AWS.
-func Unmarshal(in map[string]map[string]interface{}, out interface{}) error {
-    reflectVal := reflect.ValueOf(out)
-    if !reflectVal.IsValid() {
-        return errors.New("Out is not valid")
-    }
-    reflectType := reflectVal.Type()
-    if reflectType.Kind() != reflect.Ptr {
-        return errors.New("Out is not valid pointer")
-    }
-    reflectVal = reflectVal.Elem()
-    reflectType = reflectVal.Type()
-    if reflectType.Kind() != reflect.Struct {
-        return errors.New("Out is not valid pointer to a struct")
-    }
-    for i := 0; i < reflectType.NumField(); i++ {
-        f := reflectType.Field(i)
-        if f.PkgPath != "" {
-            continue // unexported
-        }
-        tag := f.Tag.Get("dynamo")
-        if tag == "-" {
-            continue
-        }
-        name := tag
-        if name == "" {
-            name = f.Name
-        }
-        switch f.Type.Kind() {
-        case reflect.String:
-            if asStr, ok := in[name]["S"].(string); ok {
-                reflectVal.FieldByIndex(f.Index).SetString(asStr)
-            }
-            break
-        case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-            if asNum, ok := in[name]["N"].(string); ok {
-                asInt, err := strconv.ParseInt(asNum, 10, 64)
-                if err != nil {
-                    return err
-                }
-                reflectVal.FieldByIndex(f.Index).SetInt(asInt)
-            }
-            break
-        case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-            if asNum, ok := in[name]["N"].(string); ok {
-                asInt, err := strconv.ParseUint(asNum, 10, 64)
-                if err != nil {
-                    return err
-                }
-                reflectVal.FieldByIndex(f.Index).SetUint(asInt)
-            }
-            break
-        case reflect.Float32, reflect.Float64:
-            if asNum, ok := in[name]["N"].(string); ok {
-                asFloat, err := strconv.ParseFloat(asNum, 64)
-                if err != nil {
-                    return err
-                }
-                reflectVal.FieldByIndex(f.Index).SetFloat(asFloat)
-            }
-            break
-        case reflect.Array, reflect.Slice:
-            err := decodeArray(in[name], reflectVal.FieldByIndex(f.Index))
-            if err != nil {
-                return err
-            }
-            break
-        default:
-            hasItem, ok := in[name]
-            if !ok {
-                break
-            }
-            if _, ok := reflectVal.FieldByIndex(f.Index).Interface().(time.Time); ok {
-                asTime, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", hasItem["S"].(string))
-                if err != nil {
-                    return err
-                }
-                reflectVal.FieldByIndex(f.Index).Set(reflect.ValueOf(asTime))
-                break
-            }
-            if asStr, ok := hasItem["S"].(string); ok {
-                as := reflect.New(f.Type)
-                err := json.Unmarshal([]byte(asStr), as.Interface())
-                if err != nil {
-                    return err
-                }
-                reflectVal.FieldByIndex(f.Index).Set(as.Elem())
-            } else {
-                return errors.New("Cannot decode field: " + name)
-            }
-        }
-    }
-    return nil
-}
-
-func decodeArray(in map[string]interface{}, v reflect.Value) error {
-    if in == nil {
-        return nil
-    }
-    k := v.Type().Elem().Kind()
-    t := v.Type()
-    switch k {
-    case reflect.String:
-        if asStr, ok := in["SS"].([]interface{}); ok {
-            v.Set(reflect.MakeSlice(t, len(asStr), len(asStr)))
-            for i := range asStr {
-                v.Index(i).SetString(asStr[i].(string))
-            }
-        }
-        return nil
-        break
-    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-        if asNum, ok := in["NS"].([]interface{}); ok {
-            v.Set(reflect.MakeSlice(t, len(asNum), len(asNum)))
-            for i := range asNum {
-                asInt, err := strconv.ParseInt(asNum[i].(string), 10, 64)
-                if err != nil {
-                    return err
-                }
-                v.Index(i).SetInt(asInt)
-            }
-        }
-        return nil
-    case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-        if asNum, ok := in["NS"].([]interface{}); ok {
-            v.Set(reflect.MakeSlice(t, len(asNum), len(asNum)))
-            for i := range asNum {
-                asInt, err := strconv.ParseUint(asNum[i].(string), 10, 64)
-                if err != nil {
-                    return err
-                }
-                v.Index(i).SetUint(asInt)
-            }
-        }
-        return nil
-    case reflect.Float32, reflect.Float64:
-        if asNum, ok := in["NS"].([]interface{}); ok {
-            v.Set(reflect.MakeSlice(t, len(asNum), len(asNum)))
-            for i := range asNum {
-                asFloat, err := strconv.ParseFloat(asNum[i].(string), 64)
-                if err != nil {
-                    return err
-                }
-                v.Index(i).SetFloat(asFloat)
-            }
-        }
-        return nil
-    }
-    if asStr, ok := in["S"].(string); ok {
-        as := reflect.New(t)
-        err := json.Unmarshal([]byte(asStr), as.Interface())
-        if err != nil {
-            return err
-        }
-        v.Set(as.Elem())
-        return err
-    }
-    return errors.New("Could not decode item")
-}
-
-
-// takes an struct and returns a map that can be used write or put item with
-//      you can rename a field via: `dynamo:"rename"` tag
-//      fields can be omitted via: `dynamo:"-"` tag
-//      empty strings are not marshalled, same for empty arrays
-//      non basic types (eg interfaces, structs, pointers) are marshalled as json string
-func Marshal(v interface{}) map[string]interface{} {
-    reflectVal := reflect.ValueOf(v)
-    if !reflectVal.IsValid() {
-        return nil
-    }
-    reflectType := reflectVal.Type()
-    if reflectType.Kind() != reflect.Struct {
-        return nil
-    }
-    result := make(map[string]interface{})
-    for i := 0; i < reflectType.NumField(); i++ {
-        f := reflectType.Field(i)
-        if f.PkgPath != "" {
-            continue // unexported
-        }
-        tag := f.Tag.Get("dynamo")
-        if tag == "-" {
-            continue
-        }
-        name := tag
-        if name == "" {
-            name = f.Name
-        }
-        switch f.Type.Kind() {
-        case reflect.String:
-            val := reflectVal.FieldByIndex(f.Index).String()
-            if val == "" {
-                continue
-            }
-            result[name] = awsgo.AwsStringItem{
-                Value: val,
-            }
-            break
-        case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-            val := reflectVal.FieldByIndex(f.Index).Int()
-            result[name] = awsgo.AwsNumberItem{
-                Value: float64(val), // backwards compat
-                ValueStr: strconv.FormatInt(val, 10),
-            }
-            break
-        case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-            val := reflectVal.FieldByIndex(f.Index).Uint()
-            result[name] = awsgo.AwsNumberItem{
-                Value: float64(val), // backwards compat
-                ValueStr: strconv.FormatUint(val, 10),
-            }
-            break
-        case reflect.Float32, reflect.Float64:
-            val := reflectVal.FieldByIndex(f.Index).Float()
-            result[name] = awsgo.AwsNumberItem{
-                Value: val, // backwards compat
-                ValueStr: fmt.Sprintf("%f", val),
-            }
-            break
-        case reflect.Array, reflect.Slice:
-            val := encodeArray(reflectVal.FieldByIndex(f.Index))
-            if val == nil {
-                continue
-            }
-            result[name] = val
-            break
-        default:
-            if asTime, ok := reflectVal.FieldByIndex(f.Index).Interface().(time.Time); ok {
-                result[name] = awsgo.AwsStringItem{
-                    Value: asTime.String(),
-                }
-                break
-            }
-            val, _ := json.Marshal(reflectVal.FieldByIndex(f.Index).Interface())
-            result[name] = awsgo.AwsStringItem{
-                Value: string(val),
-            }
-        }
-    }
-    return result
-}
-
-func encodeArray(v reflect.Value) interface{} {
-    if v.Len() == 0 {
-        return nil
-    }
-    switch v.Type().Elem().Kind() {
-    case reflect.String:
-        return awsgo.AwsStringItem{
-            Values: v.Interface().([]string),
-        }
-    case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-        strArray := make([]string, v.Len())
-        for i := range strArray {
-            val := v.Index(i).Int()
-            strArray[i] = strconv.FormatInt(val, 10)
-        }
-        return awsgo.AwsNumberItem{
-            ValuesStr: strArray,
-        }
-    case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-        strArray := make([]string, v.Len())
-        for i := range strArray {
-            val := v.Index(i).Uint()
-            strArray[i] = strconv.FormatUint(val, 10)
-        }
-        return awsgo.AwsNumberItem{
-            ValuesStr: strArray,
-        }
-    case reflect.Float32, reflect.Float64:
-        strArray := make([]string, v.Len())
-        for i := range strArray {
-            val := v.Index(i).Float()
-            strArray[i] = fmt.Sprintf("%f", val)
-        }
-        return awsgo.AwsNumberItem{
-            ValuesStr: strArray,
-        }
-    }
-    enc, _ := json.Marshal(v.Interface())
-    return awsgo.AwsStringItem{
-        Value: string(enc),
-    }
-}
-
-
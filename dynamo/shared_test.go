@@ -6,6 +6,10 @@ import (
     "encoding/json"
     "log"
     "time"
+    "reflect"
+    "errors"
+    "fmt"
+    "strings"
 )
 
 
@@ -750,18 +754,21 @@ func TestMarshalMutliLevelStruct(t *testing.T) {
         t.Logf("Me not found")
         t.Fail()
     }
-    if asStr, ok := res["Part2"].(awsgo.AwsStringItem); !ok {
+    if asMap, ok := res["Part2"].(awsgo.AwsMapItem); !ok {
         t.Logf("Part2 not found")
         t.Fail()
-    } else if asStr.Value != `{"Them":"Boooo"}` {
-        t.Logf("Part2 = %s", asStr.Value)
+    } else if them, ok := asMap.Values["Them"].(awsgo.AwsStringItem); !ok || them.Value != "Boooo" {
+        t.Logf("Part2 = %v", asMap.Values)
         t.Fail()
     }
-    if asStr, ok := res["Weird"].(awsgo.AwsStringItem); !ok {
+    if asList, ok := res["Weird"].(awsgo.AwsListItem); !ok {
         t.Logf("Weird not found")
         t.Fail()
-    } else if asStr.Value != `[{"Them":"once"},{"Them":"twice"}]` {
-        t.Logf("Weird = %s", asStr.Value)
+    } else if len(asList.Values) != 2 {
+        t.Logf("Weird = %v", asList.Values)
+        t.Fail()
+    } else if second, ok := asList.Values[1].(awsgo.AwsMapItem); !ok || second.Values["Them"].(awsgo.AwsStringItem).Value != "twice" {
+        t.Logf("Weird[1] = %v", asList.Values[1])
         t.Fail()
     }
 }
@@ -842,3 +849,407 @@ func TestUnmarshalDateContainerStruct(t *testing.T) {
     }
 }
 
+
+
+type Embedded struct {
+    Shared          string
+    Overridden      string
+}
+
+type Inner struct {
+    Name            string
+    Count           int
+}
+
+type AllTypes struct {
+    Embedded
+    Overridden      string
+    Flag            bool
+    Off             bool
+    Nested          *Inner
+    Missing         *Inner
+    Ptr             *string
+    Counts          map[string]int
+    Mixed           []interface{}
+    Inners          []Inner
+    Data            []byte
+    Blobs           [][]byte
+    Any             interface{}
+}
+
+func TestRoundTripAllTypes(t *testing.T) {
+    name := "pointed"
+    b := AllTypes{
+        Embedded: Embedded{Shared: "from embedded", Overridden: "hidden"},
+        Overridden: "outer",
+        Flag: true,
+        Nested: &Inner{Name: "in", Count: 3},
+        Ptr: &name,
+        Counts: map[string]int{"a": 1, "b": 2},
+        Mixed: []interface{}{"s", 1.5, true, nil, []interface{}{"deep"}},
+        Inners: []Inner{{Name: "one"}, {Name: "two", Count: 2}},
+        Data: []byte{0, 1, 2, 255},
+        Blobs: [][]byte{[]byte("x"), []byte("y")},
+        Any: map[string]interface{}{"k": "v"},
+    }
+    res := Marshal(&b)
+    if _, ok := res["Missing"]; ok {
+        t.Logf("nil pointers should be left out")
+        t.Fail()
+    }
+    if _, ok := res["Embedded"]; ok {
+        t.Logf("embedded structs should be flattened")
+        t.Fail()
+    }
+    if _, ok := res["Flag"].(awsgo.AwsBoolItem); !ok {
+        t.Logf("Flag should be BOOL: %#v", res["Flag"])
+        t.Fail()
+    }
+    if _, ok := res["Data"].(awsgo.AwsBinaryItem); !ok {
+        t.Logf("Data should be B: %#v", res["Data"])
+        t.Fail()
+    }
+    if _, ok := res["Counts"].(awsgo.AwsMapItem); !ok {
+        t.Logf("Counts should be M: %#v", res["Counts"])
+        t.Fail()
+    }
+    if _, ok := res["Mixed"].(awsgo.AwsListItem); !ok {
+        t.Logf("Mixed should be L: %#v", res["Mixed"])
+        t.Fail()
+    }
+
+    out := AllTypes{}
+    if err := Unmarshal(resToJsonAndBack(res), &out); err != nil {
+        t.Fatalf("Failed to unmarshal: %v", err)
+    }
+    b.Embedded.Overridden = ""
+    if !reflect.DeepEqual(b, out) {
+        t.Errorf("Round trip does not match.\nExpected: %#v\nGot:      %#v", b, out)
+    }
+}
+
+type Blob []byte
+
+type NamedBytes struct {
+    Raw         json.RawMessage
+    Data        Blob
+}
+
+func TestRoundTripNamedBytes(t *testing.T) {
+    b := NamedBytes{
+        Raw: json.RawMessage(`{"a":1}`),
+        Data: Blob{0, 1, 2, 255},
+    }
+    res, err := MarshalItem(b)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if _, ok := res["Data"].(awsgo.AwsBinaryItem); !ok {
+        t.Fatalf("Data should be B: %#v", res["Data"])
+    }
+    out := NamedBytes{}
+    if err := Unmarshal(resToJsonAndBack(res), &out); err != nil {
+        t.Fatalf("Failed to unmarshal: %v", err)
+    }
+    if !reflect.DeepEqual(b, out) {
+        t.Errorf("Round trip does not match.\nExpected: %#v\nGot:      %#v", b, out)
+    }
+}
+
+type Times struct {
+    At          time.Time
+}
+
+func TestRoundTripTimeFormats(t *testing.T) {
+    defer SetDefaultTimeFormat(TimeFormat_String)
+    at := time.Date(2015, 8, 30, 12, 36, 0, 123456789, time.UTC)
+    expected := map[TimeFormat]string{
+        TimeFormat_String: "2015-08-30 12:36:00.123456789 +0000 UTC",
+        TimeFormat_RFC3339: "2015-08-30T12:36:00.123456789Z",
+        TimeFormat_UnixTime: "1440938160.123456789",
+    }
+    for format, str := range expected {
+        SetDefaultTimeFormat(format)
+        res := Marshal(Times{At: at})
+        raw := resToJsonAndBack(res)
+        if raw["At"]["S"] != str && raw["At"]["N"] != str {
+            t.Errorf("Format %d: expected %s. Got: %v", format, str, raw["At"])
+        }
+        out := Times{}
+        if err := Unmarshal(raw, &out); err != nil {
+            t.Errorf("Format %d: failed to unmarshal: %v", format, err)
+        }
+        if !out.At.Equal(at) {
+            t.Errorf("Format %d: expected %s. Got: %s", format, at, out.At)
+        }
+    }
+}
+
+// items written before M and L were supported
+func TestUnmarshalOldEncodings(t *testing.T) {
+    raw := map[string]map[string]interface{}{
+        "Me": {"S": "Weeeeee"},
+        "Part2": {"S": `{"Them":"Boooo"}`},
+        "Weird": {"S": `[{"Them":"once"},{"Them":"twice"}]`},
+    }
+    out := Level1{}
+    if err := Unmarshal(raw, &out); err != nil {
+        t.Fatalf("Failed to unmarshal: %v", err)
+    }
+    if out.Part2.Them != "Boooo" || len(out.Weird) != 2 || out.Weird[1].Them != "twice" {
+        t.Errorf("Unexpected result: %#v", out)
+    }
+    times := Times{}
+    raw = map[string]map[string]interface{}{
+        "At": {"S": "2015-08-30 12:36:00.123456789 -0700 PDT m=+0.852176762"},
+    }
+    if err := Unmarshal(raw, &times); err != nil {
+        t.Fatalf("Failed to unmarshal time with monotonic clock: %v", err)
+    }
+    if times.At.Nanosecond() != 123456789 {
+        t.Errorf("Unexpected time: %s", times.At)
+    }
+    ints := BasicInt{}
+    raw = map[string]map[string]interface{}{
+        "Amanda": {"N": "5.000000"},
+    }
+    if err := Unmarshal(raw, &ints); err != nil || ints.Amanda != 5 {
+        t.Errorf("Expected numbers written by ConvertToAwsItem to decode into ints. Got: %d %v", ints.Amanda, err)
+    }
+    flags := AllTypes{Off: true}
+    raw = map[string]map[string]interface{}{
+        "Flag": {"S": "true"},
+        "Off": {"S": "false"},
+    }
+    if err := Unmarshal(raw, &flags); err != nil || !flags.Flag || flags.Off {
+        t.Errorf("Expected bools stored as json strings to decode. Got: %v %v %v", flags.Flag, flags.Off, err)
+    }
+    // and they are written back as BOOL
+    if item, ok := Marshal(flags)["Flag"].(awsgo.AwsBoolItem); !ok || !item.Value {
+        t.Errorf("Expected Flag to be written back as BOOL. Got %#v", Marshal(flags)["Flag"])
+    }
+    raw = map[string]map[string]interface{}{
+        "Flag": {"S": "yes please"},
+    }
+    if err := Unmarshal(raw, &flags); err == nil {
+        t.Errorf("Expected an error for a string that isn't a bool")
+    }
+}
+
+
+type Tagged struct {
+    User        string      `dynamo:"user,hashkey"`
+    At          time.Time   `dynamo:"at,rangekey,unixtime"`
+    Score       int         `dynamo:",omitempty"`
+    Hidden      bool        `dynamo:"hidden,omitempty"`
+    Zip         int         `dynamo:"zip,string"`
+    Codes       []int       `dynamo:"codes,string"`
+    Tags        []string    `dynamo:"tags,list"`
+    Ids         []int       `dynamo:"ids,set"`
+}
+
+func TestMarshalTagOptions(t *testing.T) {
+    b := Tagged{
+        User: "bob",
+        At: time.Unix(1440938160, 0),
+        Zip: 90210,
+        Codes: []int{1, 2},
+        Tags: []string{"a", "a"},
+        Ids: []int{4, 5},
+    }
+    res := Marshal(b)
+    if _, ok := res["Score"]; ok {
+        t.Logf("Score should be omitted when 0")
+        t.Fail()
+    }
+    if _, ok := res["hidden"]; ok {
+        t.Logf("hidden should be omitted when false")
+        t.Fail()
+    }
+    if at, ok := res["at"].(awsgo.AwsNumberItem); !ok || at.ValueStr != "1440938160" {
+        t.Logf("at should be a unix time: %#v", res["at"])
+        t.Fail()
+    }
+    if zip, ok := res["zip"].(awsgo.AwsStringItem); !ok || zip.Value != "90210" {
+        t.Logf("zip should be a string: %#v", res["zip"])
+        t.Fail()
+    }
+    if codes, ok := res["codes"].(awsgo.AwsStringItem); !ok || len(codes.Values) != 2 {
+        t.Logf("codes should be a string set: %#v", res["codes"])
+        t.Fail()
+    }
+    if tags, ok := res["tags"].(awsgo.AwsListItem); !ok || len(tags.Values) != 2 {
+        t.Logf("tags should be a list: %#v", res["tags"])
+        t.Fail()
+    }
+    if ids, ok := res["ids"].(awsgo.AwsNumberItem); !ok || len(ids.ValuesStr) != 2 {
+        t.Logf("ids should be a number set: %#v", res["ids"])
+        t.Fail()
+    }
+
+    out := Tagged{}
+    if err := Unmarshal(resToJsonAndBack(res), &out); err != nil {
+        t.Fatalf("Failed to unmarshal: %v", err)
+    }
+    if !reflect.DeepEqual(b, out) {
+        t.Errorf("Round trip does not match.\nExpected: %#v\nGot:      %#v", b, out)
+    }
+
+    b.Score = 7
+    b.Hidden = true
+    res = Marshal(b)
+    if _, ok := res["Score"]; !ok {
+        t.Logf("Score should be kept when set")
+        t.Fail()
+    }
+    if _, ok := res["hidden"]; !ok {
+        t.Logf("hidden should be kept when true")
+        t.Fail()
+    }
+}
+
+func TestMarshalKey(t *testing.T) {
+    key, err := MarshalKey(&Tagged{User: "bob", At: time.Unix(10, 0), Score: 3})
+    if err != nil {
+        t.Fatalf("Failed to marshal key: %v", err)
+    }
+    if len(key) != 2 {
+        t.Errorf("Expected only the hash and range key. Got: %v", key)
+    }
+    if user, ok := key["user"].(awsgo.AwsStringItem); !ok || user.Value != "bob" {
+        t.Errorf("Unexpected hash key: %#v", key["user"])
+    }
+    if at, ok := key["at"].(awsgo.AwsNumberItem); !ok || at.ValueStr != "10" {
+        t.Errorf("Unexpected range key: %#v", key["at"])
+    }
+    if _, err := MarshalKey(Tagged{}); !errors.Is(err, Marshal_Error_KeyEmpty) {
+        t.Errorf("Expected Marshal_Error_KeyEmpty. Got: %v", err)
+    }
+    if _, err := MarshalKey(BasicString{Bob: "x"}); err != Marshal_Error_NoHashKey {
+        t.Errorf("Expected Marshal_Error_NoHashKey. Got: %v", err)
+    }
+}
+
+
+// stored as a decimal string, eg. "12.34"
+type Money int64
+
+func (m Money) MarshalDynamo() (interface{}, error) {
+    return fmt.Sprintf("%d.%02d", m / 100, m % 100), nil
+}
+
+func (m *Money) UnmarshalDynamo(value map[string]interface{}) error {
+    asStr, ok := awsgo.FromRawValue(value).(string)
+    if !ok {
+        return errors.New("money should be a string")
+    }
+    var dollars, cents int64
+    if _, err := fmt.Sscanf(asStr, "%d.%02d", &dollars, &cents); err != nil {
+        return err
+    }
+    *m = Money(dollars * 100 + cents)
+    return nil
+}
+
+type Colour int
+
+const (
+    Red Colour = iota
+    Green
+)
+
+func (c Colour) MarshalText() ([]byte, error) {
+    return []byte([]string{"red", "green"}[c]), nil
+}
+
+func (c *Colour) UnmarshalText(text []byte) error {
+    switch string(text) {
+    case "red":
+        *c = Red
+    case "green":
+        *c = Green
+    default:
+        return errors.New("unknown colour: " + string(text))
+    }
+    return nil
+}
+
+type Priced struct {
+    Price       Money
+    Colour      Colour
+}
+
+type Basket struct {
+    Total       Money
+    Prices      []Money
+    Items       []Priced
+    ByColour    map[Colour]Money
+    Favourite   *Colour
+}
+
+func TestMarshalerInterfaces(t *testing.T) {
+    green := Green
+    b := Basket{
+        Total: 1234,
+        Prices: []Money{100, 250},
+        Items: []Priced{{Price: 5, Colour: Green}},
+        ByColour: map[Colour]Money{Red: 1, Green: 2},
+        Favourite: &green,
+    }
+    res, err := MarshalItem(b)
+    if err != nil {
+        t.Fatalf("Failed to marshal: %v", err)
+    }
+    if total, ok := res["Total"].(awsgo.AwsStringItem); !ok || total.Value != "12.34" {
+        t.Errorf("Total should use MarshalDynamo: %#v", res["Total"])
+    }
+    if prices, ok := res["Prices"].(awsgo.AwsListItem); !ok || len(prices.Values) != 2 {
+        t.Errorf("Prices should be a list of MarshalDynamo results: %#v", res["Prices"])
+    }
+    if fav, ok := res["Favourite"].(awsgo.AwsStringItem); !ok || fav.Value != "green" {
+        t.Errorf("Favourite should use MarshalText: %#v", res["Favourite"])
+    }
+    byColour, ok := res["ByColour"].(awsgo.AwsMapItem)
+    if _, hasRed := byColour.Values["red"]; !ok || !hasRed {
+        t.Errorf("ByColour keys should use MarshalText: %#v", res["ByColour"])
+    }
+
+    out := Basket{}
+    if err := Unmarshal(resToJsonAndBack(res), &out); err != nil {
+        t.Fatalf("Failed to unmarshal: %v", err)
+    }
+    if !reflect.DeepEqual(b, out) {
+        t.Errorf("Round trip does not match.\nExpected: %#v\nGot:      %#v", b, out)
+    }
+}
+
+type failingMarshaler struct {}
+
+func (f failingMarshaler) MarshalDynamo() (interface{}, error) {
+    return nil, errors.New("nope")
+}
+
+type WithFailing struct {
+    Name        string
+    Broken      failingMarshaler
+}
+
+func TestMarshalerErrors(t *testing.T) {
+    if _, err := MarshalItem(WithFailing{Name: "x"}); err == nil || !strings.Contains(err.Error(), "nope") {
+        t.Errorf("Expected the MarshalDynamo error. Got: %v", err)
+    }
+    res := Marshal(WithFailing{Name: "x"})
+    if res != nil {
+        t.Errorf("Expected Marshal to return nil on error. Got: %v", res)
+    }
+    if _, err := MarshalItem(struct{ C chan int }{make(chan int)}); err == nil {
+        t.Errorf("Expected the json error for a chan")
+    }
+    out := Basket{}
+    raw := map[string]map[string]interface{}{
+        "Total": {"N": "5"},
+    }
+    if err := Unmarshal(raw, &out); err == nil {
+        t.Errorf("Expected the UnmarshalDynamo error")
+    }
+}
@@ -31,6 +31,7 @@
 package dynamo
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "encoding/json"
@@ -53,11 +54,16 @@ type UpdateItemRequest struct {
 
     Expected                map[string]ExpectedItem  `json:",omitempty"`
     UpdateKey               map[string]interface{}  `json:"Key"`
-    Update                  map[string]AttributeUpdates  `json:"AttributeUpdates"`
+    Update                  map[string]AttributeUpdates  `json:"AttributeUpdates,omitempty"`
     TableName               string
     ReturnConsumedCapacity  string
     ReturnItemCollection    string
     ReturnValues            string
+    // Use SetExpression to fill these in from an expression.Builder
+    ConditionExpression     string  `json:",omitempty"`
+    UpdateExpression        string  `json:",omitempty"`
+    ExpressionAttributeNames    map[string]string   `json:",omitempty"`
+    ExpressionAttributeValues   map[string]interface{}  `json:",omitempty"`
 }
 
 
@@ -112,8 +118,8 @@ func (pir * UpdateItemRequest) VerifyInput() (error) {
     if len(pir.UpdateKey) == 0 {
         return errors.New("UpdateKey cannot be empty")
     }
-    if len(pir.Update) == 0 {
-        return errors.New("Update cannot be empty")
+    if len(pir.Update) == 0 && len(pir.UpdateExpression) == 0 {
+        return errors.New("Update or UpdateExpression must be set")
     }
     if len(pir.Host.Region) == 0 {
         return errors.New("Host.Region cannot be empty")
@@ -133,10 +139,15 @@ func (pir * UpdateItemRequest) VerifyInput() (error) {
             pir.Expected[k] = ExpectedItem{v.Exists, awsgo.ConvertToAwsItem(v.Value)}
         }
     }
+    values, err := convertExpressionValues(pir.ExpressionAttributeValues)
+    if err != nil {
+        return err
+    }
+    pir.ExpressionAttributeValues = values
     return nil
 }
 func (pir UpdateItemRequest) CoRequest() (*UpdateItemResponseFuture, error) {
-    request, err := awsgo.NewAwsRequest(&pir, pir)
+    request, err := awsgo.NewAwsRequest(&pir, &pir)
     if err != nil {
         return nil, err
     }
@@ -177,12 +188,17 @@ func (pir UpdateItemRequest) CoDoAndDemarshall(request awsgo.AwsRequest, future
 }
 
 func (pir UpdateItemRequest) Request() (*UpdateItemResponse, error) {
-    request, err := awsgo.NewAwsRequest(&pir, pir)
+    return pir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (pir UpdateItemRequest) RequestWithContext(ctx context.Context) (*UpdateItemResponse, error) {
+    request, err := awsgo.NewAwsRequest(&pir, &pir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&pir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &pir)
     if resp == nil {
         return nil, err
     }
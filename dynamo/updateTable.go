@@ -32,6 +32,7 @@ package dynamo
 
 
 import (
+    "context"
     "encoding/json"
     "github.com/fromkeith/awsgo"
 )
@@ -60,21 +61,8 @@ type UpdateTableRequest struct {
 }
 
 
-type updateTableDescription struct {
-    AttributeDefinitions        []attributeDefinitions
-    CreationDateTime            float64
-    GlobalSecondaryIndexes      []secondaryIndex
-    ItemCount                   float64
-    KeySchema                   []keySchema
-    LocalSecondaryIndexes       []secondaryIndex
-    ProvisionedThroughput       provisionedThroughput
-    TableName                   string
-    TableSizeBytes              float64
-    TableStatus                 string
-}
-
 type UpdateTableResponse struct {
-    TableDescription            updateTableDescription
+    TableDescription            TableDescription
 }
 
 
@@ -127,12 +115,17 @@ func (req UpdateTableRequest) DeMarshalResponse(response []byte, headers map[str
 }
 
 func (gir UpdateTableRequest) Request() (*UpdateTableResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir UpdateTableRequest) RequestWithContext(ctx context.Context) (*UpdateTableResponse, error) {
     request, err := awsgo.NewAwsRequest(&gir, gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package dynamo
+
+
+import (
+    "context"
+    "github.com/fromkeith/awsgo"
+    "time"
+)
+
+// How long the table waiters sleep between DescribeTable calls when no delay is given.
+const DefaultWaiterDelay = 5 * time.Second
+
+// Polls DescribeTable until the table and all of its global secondary indexes are ACTIVE.
+// A table that doesn't exist yet is polled again, as it may still be getting created.
+// A delay of 0 uses DefaultWaiterDelay. Use ctx to limit how long to wait.
+func WaitUntilTableActive(ctx context.Context, req *DescribeTableRequest, delay time.Duration) (*DescribeTableResponse, error) {
+    if delay <= 0 {
+        delay = DefaultWaiterDelay
+    }
+    for {
+        resp, err := describeTableCopy(ctx, req)
+        if err != nil && !IsResourceNotFound(err) {
+            return resp, err
+        }
+        if err == nil && tableIsActive(resp) {
+            return resp, nil
+        }
+        if err = awsgo.SleepWithContext(ctx, delay); err != nil {
+            return resp, err
+        }
+    }
+}
+
+// Polls DescribeTable until DynamoDB reports that the table no longer exists.
+// A delay of 0 uses DefaultWaiterDelay. Use ctx to limit how long to wait.
+func WaitUntilTableDeleted(ctx context.Context, req *DescribeTableRequest, delay time.Duration) error {
+    if delay <= 0 {
+        delay = DefaultWaiterDelay
+    }
+    for {
+        _, err := describeTableCopy(ctx, req)
+        if IsResourceNotFound(err) {
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+        if err = awsgo.SleepWithContext(ctx, delay); err != nil {
+            return err
+        }
+    }
+}
+
+// each poll gets its own copy, so the caller's Headers aren't written to while we wait
+func describeTableCopy(ctx context.Context, req *DescribeTableRequest) (*DescribeTableResponse, error) {
+    reqCopy := *req
+    reqCopy.RequestBuilder = copyRequestBuilder(req.RequestBuilder)
+    return reqCopy.RequestWithContext(ctx)
+}
+
+func tableIsActive(resp *DescribeTableResponse) bool {
+    if resp.Table.TableStatus != TableStatus_ACTIVE {
+        return false
+    }
+    for _, index := range resp.Table.GlobalSecondaryIndexes {
+        if index.IndexStatus != TableStatus_ACTIVE {
+            return false
+        }
+    }
+    return true
+}
@@ -31,6 +31,7 @@
 package ec2
 
 import (
+    "context"
     "bytes"
     "net/url"
     "github.com/fromkeith/awsgo"
@@ -223,12 +224,17 @@ func (gir DescribeInstancesRequest) DeMarshalResponse(response []byte, headers m
 
 
 func (gir DescribeInstancesRequest) Request() (*DescribeInstancesResult, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir DescribeInstancesRequest) RequestWithContext(ctx context.Context) (*DescribeInstancesResult, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS2
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
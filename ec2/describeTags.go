@@ -31,6 +31,7 @@
 package ec2
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "encoding/xml"
     "net/url"
@@ -122,12 +123,17 @@ func (req DescribeTagsRequest) DeMarshalResponse(response []byte, headers map[st
 
 
 func (gir DescribeTagsRequest) Request() (*DescribeTagsResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir DescribeTagsRequest) RequestWithContext(ctx context.Context) (*DescribeTagsResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS2
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
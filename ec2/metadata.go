@@ -32,37 +32,20 @@ package ec2
 
 
 import (
-    "net/url"
-    "net/http"
-    "bytes"
+    "context"
     "encoding/json"
-    "io"
-    "errors"
-    "fmt"
+    "github.com/fromkeith/awsgo"
 )
 
 
 func MakeSimpleRequest(urlString string) (string, error) {
-    metaDataUri, _ := url.Parse("http://169.254.169.254/latest" + urlString)
-    hreq := http.Request {
-        URL: metaDataUri,
-        Method: "GET",
-        ProtoMajor: 1,
-        ProtoMinor: 1,
-        Close: true,
-    }
-    resp, err := http.DefaultClient.Do(&hreq)
-    if err != nil {
-        return "", err
-    }
-    defer resp.Body.Close()
-    buf := bytes.Buffer{}
-    io.Copy(&buf, resp.Body)
+    return MakeSimpleRequestWithContext(context.Background(), urlString)
+}
 
-    if resp.StatusCode != 200 {
-        return "", errors.New(fmt.Sprintf("Got Status code: %d", resp.StatusCode))
-    }
-    return buf.String(), nil
+// Same as MakeSimpleRequest, but the call is aborted once ctx is done.
+// Requests go through awsgo.DefaultMetadataClient, so use IMDSv2 tokens.
+func MakeSimpleRequestWithContext(ctx context.Context, urlString string) (string, error) {
+    return awsgo.DefaultMetadataClient.GetWithContext(ctx, urlString)
 }
 
 
@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "sync"
+)
+
+var (
+    Endpoint_Error_ServiceEmpty = errors.New("Host.Service cannot be empty")
+)
+
+// Where a request is sent, and how it is signed.
+type Endpoint struct {
+    // Scheme and host, optionally with a port. Eg. https://dynamodb.us-west-2.amazonaws.com
+    // or http://localhost:8000
+    URL             string
+    // The region used to sign the request. Defaults to Host.Region
+    SigningRegion   string
+    // The service name used to sign the request. Defaults to Host.Service
+    SigningName     string
+}
+
+// Decides the Endpoint a request is sent to.
+type EndpointResolver interface {
+    ResolveEndpoint(host AwsHost) (Endpoint, error)
+}
+
+// Lets a plain function be used as an EndpointResolver.
+type EndpointResolverFunc func(host AwsHost) (Endpoint, error)
+
+func (f EndpointResolverFunc) ResolveEndpoint(host AwsHost) (Endpoint, error) {
+    return f(host)
+}
+
+// A group of regions that share a dns suffix, eg. China or GovCloud.
+type partition struct {
+    name                string
+    regionPrefixes      []string
+    dnsSuffix           string
+    dualStackDnsSuffix  string
+}
+
+var partitions = []partition{
+    {"aws-cn", []string{"cn-"}, "amazonaws.com.cn", "api.amazonwebservices.com.cn"},
+    {"aws-us-gov", []string{"us-gov-"}, "amazonaws.com", "api.aws"},
+    {"aws-iso-b", []string{"us-isob-"}, "sc2s.sgov.gov", "sc2s.sgov.gov"},
+    {"aws-iso", []string{"us-iso-"}, "c2s.ic.gov", "c2s.ic.gov"},
+    {"aws", nil, "amazonaws.com", "api.aws"},
+}
+
+func partitionForRegion(region string) partition {
+    for _, p := range partitions {
+        for _, prefix := range p.regionPrefixes {
+            if strings.HasPrefix(region, prefix) {
+                return p
+            }
+        }
+    }
+    return partitions[len(partitions) - 1]
+}
+
+// Services with one endpoint for the whole partition, by service then partition name.
+var globalEndpoints = map[string]map[string]Endpoint{
+    "iam": {
+        "aws": {URL: "https://iam.amazonaws.com", SigningRegion: "us-east-1"},
+        "aws-cn": {URL: "https://iam.cn-north-1.amazonaws.com.cn", SigningRegion: "cn-north-1"},
+        "aws-us-gov": {URL: "https://iam.us-gov.amazonaws.com", SigningRegion: "us-gov-west-1"},
+    },
+    "route53": {
+        "aws": {URL: "https://route53.amazonaws.com", SigningRegion: "us-east-1"},
+        "aws-cn": {URL: "https://route53.amazonaws.com.cn", SigningRegion: "cn-northwest-1"},
+        "aws-us-gov": {URL: "https://route53.us-gov.amazonaws.com", SigningRegion: "us-gov-west-1"},
+    },
+    "cloudfront": {
+        "aws": {URL: "https://cloudfront.amazonaws.com", SigningRegion: "us-east-1"},
+        "aws-cn": {URL: "https://cloudfront.cn-northwest-1.amazonaws.com.cn", SigningRegion: "cn-northwest-1"},
+    },
+}
+
+// Services whose signing name differs from their hostname.
+var signingNames = map[string]string{
+    "email": "ses",
+}
+
+// The built in endpoint table.
+// Knows the partitions (aws, aws-cn, aws-us-gov, aws-iso, aws-iso-b), the global services,
+// S3's us-east-1 endpoint, and FIPS and dual-stack hostnames.
+type DefaultEndpointResolver struct {
+    // Use FIPS 140-2 endpoints. Also enabled by AWS_USE_FIPS_ENDPOINT=true
+    UseFIPS         bool
+    // Use endpoints that resolve to both IPv4 and IPv6. Also enabled by AWS_USE_DUALSTACK_ENDPOINT=true
+    UseDualStack    bool
+}
+
+func (r DefaultEndpointResolver) ResolveEndpoint(host AwsHost) (Endpoint, error) {
+    if host.Service == "" {
+        return Endpoint{}, Endpoint_Error_ServiceEmpty
+    }
+    fips := r.UseFIPS || strings.EqualFold(os.Getenv("AWS_USE_FIPS_ENDPOINT"), "true")
+    dualStack := r.UseDualStack || strings.EqualFold(os.Getenv("AWS_USE_DUALSTACK_ENDPOINT"), "true")
+    endpoint := Endpoint{
+        SigningRegion: host.Region,
+        SigningName: host.Service,
+    }
+    if name, ok := signingNames[host.Service]; ok {
+        endpoint.SigningName = name
+    }
+
+    // a custom domain is used as is
+    if host.Domain != "" && host.Domain != "amazonaws.com" {
+        endpoint.URL = "https://" + host.ToString()
+        return endpoint, nil
+    }
+    // some older requests have no region, and put it in the service name (eg. s3-us-west-2)
+    if host.Region == "" {
+        host.Domain = "amazonaws.com"
+        endpoint.URL = "https://" + host.ToString()
+        return endpoint, nil
+    }
+
+    part := partitionForRegion(host.Region)
+    if global, ok := globalEndpoints[host.Service][part.name]; ok && !fips && !dualStack {
+        global.SigningName = endpoint.SigningName
+        return global, nil
+    }
+
+    service := host.Service
+    if fips {
+        service += "-fips"
+    }
+    suffix := part.dnsSuffix
+    if dualStack {
+        suffix = part.dualStackDnsSuffix
+        // s3 kept its own dual-stack naming
+        if host.Service == "s3" {
+            endpoint.URL = fmt.Sprintf("https://%s.dualstack.%s.%s", service, host.Region, part.dnsSuffix)
+            return endpoint, nil
+        }
+    } else if host.Service == "s3" && host.Region == "us-east-1" && !fips {
+        endpoint.URL = "https://s3.amazonaws.com"
+        return endpoint, nil
+    }
+    endpoint.URL = fmt.Sprintf("https://%s.%s.%s", service, host.Region, suffix)
+    return endpoint, nil
+}
+
+var (
+    endpointLock sync.RWMutex
+    defaultEndpointResolver EndpointResolver = DefaultEndpointResolver{}
+    serviceEndpoints = map[string]string{}
+)
+
+// Sets the EndpointResolver used by requests that don't specify their own.
+func SetDefaultEndpointResolver(resolver EndpointResolver) {
+    endpointLock.Lock()
+    defer endpointLock.Unlock()
+    defaultEndpointResolver = resolver
+}
+
+// Sends every request for service (as in Host.Service, eg. 'dynamodb') to url,
+// for example a local emulator like http://localhost:8000.
+// This takes precedence over the default EndpointResolver. An empty url removes the override.
+//
+// The environment variables AWS_ENDPOINT_URL_<SERVICE> (eg. AWS_ENDPOINT_URL_DYNAMODB) and
+// AWS_ENDPOINT_URL do the same, when no override has been set here.
+func SetServiceEndpoint(service, url string) {
+    endpointLock.Lock()
+    defer endpointLock.Unlock()
+    if url == "" {
+        delete(serviceEndpoints, service)
+        return
+    }
+    serviceEndpoints[service] = url
+}
+
+func serviceEndpointOverride(service string) string {
+    endpointLock.RLock()
+    url, ok := serviceEndpoints[service]
+    endpointLock.RUnlock()
+    if ok {
+        return url
+    }
+    envName := "AWS_ENDPOINT_URL_" + strings.ToUpper(strings.Replace(service, "-", "_", -1))
+    if url = os.Getenv(envName); url != "" {
+        return url
+    }
+    return os.Getenv("AWS_ENDPOINT_URL")
+}
+
+// Works out the Endpoint for host the same way requests do, when they have no
+// EndpointResolver of their own: Host.Override, then SetServiceEndpoint and the
+// AWS_ENDPOINT_URL variables, then the default EndpointResolver.
+func ResolveEndpoint(host AwsHost) (Endpoint, error) {
+    return resolveEndpoint(host, nil)
+}
+
+func resolveEndpoint(host AwsHost, resolver EndpointResolver) (endpoint Endpoint, err error) {
+    if host.Override != "" {
+        endpoint = Endpoint{URL: "https://" + host.Override}
+    } else if resolver != nil {
+        endpoint, err = resolver.ResolveEndpoint(host)
+    } else if url := serviceEndpointOverride(host.Service); url != "" {
+        endpoint = Endpoint{URL: strings.TrimSuffix(url, "/")}
+        // emulators still want a region to check the signature against
+        if host.Region == "" {
+            endpoint.SigningRegion = "us-east-1"
+        }
+    } else {
+        endpointLock.RLock()
+        resolver = defaultEndpointResolver
+        endpointLock.RUnlock()
+        endpoint, err = resolver.ResolveEndpoint(host)
+    }
+    if err != nil {
+        return Endpoint{}, err
+    }
+    if endpoint.SigningRegion == "" {
+        endpoint.SigningRegion = host.Region
+    }
+    if endpoint.SigningName == "" {
+        endpoint.SigningName = host.Service
+        if name, ok := signingNames[host.Service]; ok {
+            endpoint.SigningName = name
+        }
+    }
+    return endpoint, nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "testing"
+)
+
+func Test_DefaultEndpointResolver(t *testing.T) {
+    tests := []struct {
+        resolver    DefaultEndpointResolver
+        host        AwsHost
+        url         string
+        region      string
+        name        string
+    }{
+        {DefaultEndpointResolver{}, AwsHost{Service: "dynamodb", Region: "us-west-2", Domain: "amazonaws.com"},
+            "https://dynamodb.us-west-2.amazonaws.com", "us-west-2", "dynamodb"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "dynamodb", Region: "cn-north-1", Domain: "amazonaws.com"},
+            "https://dynamodb.cn-north-1.amazonaws.com.cn", "cn-north-1", "dynamodb"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "sqs", Region: "us-gov-west-1"},
+            "https://sqs.us-gov-west-1.amazonaws.com", "us-gov-west-1", "sqs"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "s3", Region: "us-east-1", Domain: "amazonaws.com"},
+            "https://s3.amazonaws.com", "us-east-1", "s3"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "email", Region: "eu-west-1", Domain: "amazonaws.com"},
+            "https://email.eu-west-1.amazonaws.com", "eu-west-1", "ses"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "iam", Region: "eu-west-1", Domain: "amazonaws.com"},
+            "https://iam.amazonaws.com", "us-east-1", "iam"},
+        {DefaultEndpointResolver{UseFIPS: true}, AwsHost{Service: "dynamodb", Region: "us-east-1", Domain: "amazonaws.com"},
+            "https://dynamodb-fips.us-east-1.amazonaws.com", "us-east-1", "dynamodb"},
+        {DefaultEndpointResolver{UseDualStack: true}, AwsHost{Service: "ec2", Region: "us-east-1", Domain: "amazonaws.com"},
+            "https://ec2.us-east-1.api.aws", "us-east-1", "ec2"},
+        {DefaultEndpointResolver{UseDualStack: true}, AwsHost{Service: "s3", Region: "us-west-2", Domain: "amazonaws.com"},
+            "https://s3.dualstack.us-west-2.amazonaws.com", "us-west-2", "s3"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "sns", Domain: "amazonaws.com"},
+            "https://sns.amazonaws.com", "", "sns"},
+        {DefaultEndpointResolver{}, AwsHost{Service: "search", Region: "us-west-2", Domain: "example.com"},
+            "https://search.us-west-2.example.com", "us-west-2", "search"},
+    }
+    for _, test := range tests {
+        endpoint, err := test.resolver.ResolveEndpoint(test.host)
+        if err != nil {
+            t.Fatalf("Error should be nil. Got: %v", err)
+        }
+        if endpoint.URL != test.url || endpoint.SigningRegion != test.region || endpoint.SigningName != test.name {
+            t.Errorf("For %v expected %s %s %s. Got %v", test.host, test.url, test.region, test.name, endpoint)
+        }
+    }
+}
+
+func Test_SetServiceEndpoint(t *testing.T) {
+    SetServiceEndpoint("dynamodb", "http://localhost:8000/")
+    defer SetServiceEndpoint("dynamodb", "")
+
+    endpoint, err := ResolveEndpoint(AwsHost{Service: "dynamodb", Region: "us-west-2", Domain: "amazonaws.com"})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if endpoint.URL != "http://localhost:8000" || endpoint.SigningRegion != "us-west-2" {
+        t.Fatalf("Expected the override. Got %v", endpoint)
+    }
+    // other services are untouched
+    endpoint, _ = ResolveEndpoint(AwsHost{Service: "sqs", Region: "us-west-2", Domain: "amazonaws.com"})
+    if endpoint.URL != "https://sqs.us-west-2.amazonaws.com" {
+        t.Fatalf("Expected the default sqs endpoint. Got %v", endpoint)
+    }
+}
+
+func Test_EndpointUrlEnvironment(t *testing.T) {
+    t.Setenv("AWS_ENDPOINT_URL_S3", "http://localhost:9000")
+    endpoint, err := ResolveEndpoint(AwsHost{Service: "s3", Domain: "amazonaws.com"})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if endpoint.URL != "http://localhost:9000" || endpoint.SigningRegion != "us-east-1" {
+        t.Fatalf("Expected the environment endpoint. Got %v", endpoint)
+    }
+}
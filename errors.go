@@ -31,8 +31,12 @@
 package awsgo
 
 import (
+    "encoding/json"
     "encoding/xml"
     "errors"
+    "fmt"
+    "net/http"
+    "strings"
 )
 
 var (
@@ -49,6 +53,8 @@ type Error struct {
 type ErrorResponse struct {
     ErrorT   Error      `xml:"Error"`
     RequestId string
+    // Not part of the xml; filled in from the response.
+    StatusCode  int     `xml:"-"`
 }
 
 type UnmarhsallingError struct {
@@ -60,10 +66,31 @@ func (e * UnmarhsallingError) Error() string {
     return "Error unmarshalling response"
 }
 
+func (e * UnmarhsallingError) Unwrap() error {
+    return e.MarshallError
+}
+
 func (e * ErrorResponse) Error() string {
     return e.ErrorT.Code
 }
 
+func (e * ErrorResponse) ErrorCode() string { return e.ErrorT.Code }
+func (e * ErrorResponse) ErrorMessage() string { return e.ErrorT.Message }
+func (e * ErrorResponse) HttpStatusCode() int { return e.StatusCode }
+func (e * ErrorResponse) RequestID() string { return e.RequestId }
+func (e * ErrorResponse) Retryable() bool { return IsRetryableError(e.ErrorT.Code, e.StatusCode) }
+func (e * ErrorResponse) Throttling() bool { return IsThrottlingError(e.ErrorT.Code, e.StatusCode) }
+func (e * ErrorResponse) Is(target error) bool { return MatchesErrorCode(e, target) }
+
+func (e * ErrorResponse) SetResponseInfo(statusCode int, requestId string) {
+    if e.StatusCode == 0 {
+        e.StatusCode = statusCode
+    }
+    if e.RequestId == "" {
+        e.RequestId = requestId
+    }
+}
+
 
 // Looks at a raw response for an XML error response
 func CheckForErrorXml(response []byte) error {
@@ -73,4 +100,165 @@ func CheckForErrorXml(response []byte) error {
         return errorResponse
     }
     return nil
-}
\ No newline at end of file
+}
+
+// Implemented by every error a service sends back, whatever shape it came in.
+// Get at one with errors.As:
+//
+//      var apiErr awsgo.APIError
+//      if errors.As(err, &apiErr) && apiErr.Throttling() {
+//          ...
+//      }
+type APIError interface {
+    error
+    // The service error code, Eg. ConditionalCheckFailedException. Namespaces are stripped off.
+    ErrorCode() string
+    ErrorMessage() string
+    // The status code of the response. 0 if unknown.
+    HttpStatusCode() int
+    // The request id AWS gave the response. "" if unknown.
+    RequestID() string
+    // True if sending the request again may work.
+    Retryable() bool
+    Throttling() bool
+}
+
+// An error code that can be used with errors.Is. It matches any APIError with the same code.
+//
+//      if errors.Is(err, awsgo.ErrorCode("ResourceNotFoundException")) {
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+    return string(c)
+}
+
+// True if target is an ErrorCode matching err's code. APIErrors use this for their Is method.
+func MatchesErrorCode(err APIError, target error) bool {
+    code, ok := target.(ErrorCode)
+    return ok && err.ErrorCode() == string(code)
+}
+
+// True if err, or any error it wraps, is an APIError with the given code.
+func IsErrorCode(err error, code string) bool {
+    var apiErr APIError
+    return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// True if a response with this error code and status code is safe to retry.
+func IsRetryableError(code string, statusCode int) bool {
+    return IsRetryable(RetryAttempt{StatusCode: statusCode, ErrorCode: code})
+}
+
+// True if a response with this error code and status code means we were throttled.
+func IsThrottlingError(code string, statusCode int) bool {
+    return IsThrottlingCode(code) || statusCode == http.StatusTooManyRequests
+}
+
+// Implemented by errors that only learn their status code and request id once the
+// DeMarshal handler hands them the response.
+type responseInfoSetter interface {
+    SetResponseInfo(statusCode int, requestId string)
+}
+
+// An APIError for responses the service didn't give a more specific error for.
+type ServiceError struct {
+    Code        string
+    Message     string
+    StatusCode  int
+    RequestId   string
+}
+
+// Builds a ServiceError from a failed response, pulling what it can out of the body.
+func NewServiceError(response []byte, headers map[string]string, statusCode int) *ServiceError {
+    code, message := parseErrorBody(response)
+    e := &ServiceError{
+        Code: code,
+        Message: message,
+        StatusCode: statusCode,
+    }
+    for _, name := range []string{"x-amzn-requestid", "x-amz-request-id", "x-amzn-request-id"} {
+        if v, ok := headers[name]; ok {
+            e.RequestId = v
+            break
+        }
+    }
+    return e
+}
+
+func (e * ServiceError) Error() string {
+    if e.Code == "" {
+        return fmt.Sprintf("Bad status code: %d", e.StatusCode)
+    }
+    return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e * ServiceError) ErrorCode() string { return e.Code }
+func (e * ServiceError) ErrorMessage() string { return e.Message }
+func (e * ServiceError) HttpStatusCode() int { return e.StatusCode }
+func (e * ServiceError) RequestID() string { return e.RequestId }
+func (e * ServiceError) Retryable() bool { return IsRetryableError(e.Code, e.StatusCode) }
+func (e * ServiceError) Throttling() bool { return IsThrottlingError(e.Code, e.StatusCode) }
+func (e * ServiceError) Is(target error) bool { return MatchesErrorCode(e, target) }
+
+type jsonErrorCode struct {
+    Type        string  `json:"__type"`
+    Code        string  `json:"code"`
+    Message     string  `json:"message"`
+}
+
+type xmlErrorCode struct {
+    Code        string
+    Message     string
+    ErrorT      Error   `xml:"Error"`
+}
+
+// Pulls the service error code out of a raw error response.
+// Understands both the json '__type' style (dynamo, swf, cloudwatch logs)
+// and the xml <Error><Code> style (s3, sqs, sns, cloudwatch).
+// Namespaces like 'com.amazonaws.dynamodb.v20120810#' are stripped off.
+// Returns "" if no code could be found.
+func ExtractErrorCode(response []byte) string {
+    code, _ := parseErrorBody(response)
+    return code
+}
+
+// Pulls the error message out of a raw error response. Returns "" if there is none.
+func ExtractErrorMessage(response []byte) string {
+    _, message := parseErrorBody(response)
+    return message
+}
+
+// Same as ExtractErrorCode, but also returns the message.
+func parseErrorBody(response []byte) (string, string) {
+    trimmed := strings.TrimSpace(string(response))
+    if strings.HasPrefix(trimmed, "{") {
+        var j jsonErrorCode
+        if err := json.Unmarshal([]byte(trimmed), &j); err == nil {
+            code := j.Type
+            if code == "" {
+                code = j.Code
+            }
+            return StripErrorNamespace(code), j.Message
+        }
+        return "", ""
+    }
+    if strings.HasPrefix(trimmed, "<") {
+        var x xmlErrorCode
+        if err := xml.Unmarshal([]byte(trimmed), &x); err == nil {
+            if x.ErrorT.Code != "" {
+                return x.ErrorT.Code, x.ErrorT.Message
+            }
+            return x.Code, x.Message
+        }
+    }
+    return "", ""
+}
+
+// Removes any namespace from a json '__type', Eg.
+// 'com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException' becomes 'ConditionalCheckFailedException'
+func StripErrorNamespace(code string) string {
+    if i := strings.LastIndex(code, "#"); i >= 0 {
+        return code[i + 1:]
+    }
+    return code
+}
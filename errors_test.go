@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+var _ APIError = (*ErrorResponse)(nil)
+var _ APIError = (*ServiceError)(nil)
+
+func Test_ServiceErrorFromJsonAndXml(t *testing.T) {
+    e := NewServiceError([]byte(`{"__type":"com.amazonaws.swf.base.model#UnknownResourceFault","message":"Unknown domain"}`),
+        map[string]string{"x-amzn-requestid": "req-1"}, 400)
+    if e.ErrorCode() != "UnknownResourceFault" || e.ErrorMessage() != "Unknown domain" || e.RequestID() != "req-1" || e.HttpStatusCode() != 400 {
+        t.Fatalf("Unexpected error: %+v", e)
+    }
+    if e.Retryable() || e.Throttling() {
+        t.Fatalf("Should not be retryable: %+v", e)
+    }
+    e = NewServiceError([]byte(`<ErrorResponse><Error><Code>Throttling</Code><Message>Rate exceeded</Message></Error></ErrorResponse>`), nil, 400)
+    if e.ErrorCode() != "Throttling" || e.ErrorMessage() != "Rate exceeded" || !e.Throttling() || !e.Retryable() {
+        t.Fatalf("Unexpected error: %+v", e)
+    }
+    e = NewServiceError([]byte("oops"), nil, 503)
+    if e.ErrorCode() != "" || !e.Retryable() || e.Error() != "Bad status code: 503" {
+        t.Fatalf("Unexpected error: %+v", e)
+    }
+}
+
+func Test_APIErrorThroughWrapping(t *testing.T) {
+    var err error = RequestError{
+        BaseError: fmt.Errorf("sending: %w", NewServiceError([]byte(`{"__type":"ResourceNotFoundException"}`), nil, 400)),
+        Location: "test",
+        Action: "test",
+    }
+    if !errors.Is(err, ErrorCode("ResourceNotFoundException")) || errors.Is(err, ErrorCode("ValidationException")) {
+        t.Fatalf("errors.Is didn't match the code")
+    }
+    if !IsErrorCode(err, "ResourceNotFoundException") {
+        t.Fatalf("IsErrorCode didn't match the code")
+    }
+    var apiErr APIError
+    if !errors.As(err, &apiErr) || apiErr.HttpStatusCode() != 400 {
+        t.Fatalf("errors.As didn't find the APIError")
+    }
+}
+
+type xmlEchoRequest struct {
+    echoRequest
+}
+
+func (r xmlEchoRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
+    if err := CheckForErrorXml(response); err != nil {
+        return err
+    }
+    return string(response)
+}
+
+func Test_XmlErrorGetsResponseInfo(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("X-Amzn-Requestid", "req-2")
+        w.WriteHeader(400)
+        fmt.Fprint(w, `<ErrorResponse><Error><Code>AWS.SimpleQueueService.NonExistentQueue</Code><Message>nope</Message></Error></ErrorResponse>`)
+    }))
+    defer ts.Close()
+
+    req := &xmlEchoRequest{*newEchoRequest(ts.URL, nil)}
+    request, err := NewAwsRequest(req, map[string]string{})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    request.RequestSigningType = RequestSigningType_AWS4
+    _, err = request.DoAndDemarshall(req)
+    var apiErr APIError
+    if !errors.As(err, &apiErr) {
+        t.Fatalf("Expected an APIError. Got: %v", err)
+    }
+    if apiErr.ErrorCode() != "AWS.SimpleQueueService.NonExistentQueue" || apiErr.ErrorMessage() != "nope" {
+        t.Fatalf("Unexpected error: %+v", apiErr)
+    }
+    if apiErr.HttpStatusCode() != 400 || apiErr.RequestID() != "req-2" {
+        t.Fatalf("Expected the response info to be filled in: %+v", apiErr)
+    }
+}
@@ -0,0 +1,508 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// A request on its way through the Handlers.
+// Handlers read and change it; setting Error fails the request.
+type Operation struct {
+    Context         context.Context
+    // The request being sent. Build and Sign handlers change it in place
+    Request         *AwsRequest
+    // The request being unmarshalled into. nil when only Do was called
+    Builder         RequestBuilderInterface
+    // Which attempt at sending this is, starting at 1
+    Attempt         int
+    // Created by the Send stage for each attempt
+    HttpRequest     *http.Request
+    // The response to the attempt. A Send handler may set this itself to skip the real http call
+    HttpResponse    *http.Response
+    // The response body, read by the Unmarshal stage
+    ResponseBody    []byte
+    // What DeMarshalResponse returned
+    Result          interface{}
+    // Set by any handler to fail the request
+    Error           error
+    // when the request was started
+    start           time.Time
+    // how many attempts were throttled, and the error code of the last failed one
+    throttles       int
+    errorCode       string
+    span            Span
+}
+
+// A named step in a HandlerList.
+type Handler struct {
+    Name    string
+    Fn      func(op *Operation)
+}
+
+// An ordered list of handlers. Running stops at the first handler that sets op.Error.
+type HandlerList struct {
+    list    []Handler
+}
+
+// Adds h to the end of the list.
+func (l *HandlerList) PushBack(h Handler) {
+    l.list = append(l.list, h)
+}
+
+// Adds h to the start of the list.
+func (l *HandlerList) PushFront(h Handler) {
+    l.list = append([]Handler{h}, l.list...)
+}
+
+// Adds h right after the handler called name. Returns false if there is no such handler.
+func (l *HandlerList) InsertAfter(name string, h Handler) bool {
+    for i := range l.list {
+        if l.list[i].Name == name {
+            l.list = append(l.list[:i + 1], append([]Handler{h}, l.list[i + 1:]...)...)
+            return true
+        }
+    }
+    return false
+}
+
+// Removes every handler called name.
+func (l *HandlerList) Remove(name string) {
+    kept := l.list[:0]
+    for _, h := range l.list {
+        if h.Name != name {
+            kept = append(kept, h)
+        }
+    }
+    l.list = kept
+}
+
+// The names of the handlers, in order.
+func (l HandlerList) Names() []string {
+    names := make([]string, len(l.list))
+    for i := range l.list {
+        names[i] = l.list[i].Name
+    }
+    return names
+}
+
+func (l HandlerList) copy() HandlerList {
+    return HandlerList{list: append([]Handler(nil), l.list...)}
+}
+
+// Runs each handler in turn, until one sets op.Error.
+func (l HandlerList) Run(op *Operation) {
+    for _, h := range l.list {
+        h.Fn(op)
+        if op.Error != nil {
+            return
+        }
+    }
+}
+
+// Runs every handler, regardless of op.Error.
+func (l HandlerList) runAll(op *Operation) {
+    for _, h := range l.list {
+        h.Fn(op)
+    }
+}
+
+// The stages a request goes through.
+//      Build: resolve the endpoint and add the required headers
+//      Sign: sign the request. Runs again if the credentials had to be renewed
+//      Send: create the http request and send it. Runs once per attempt; RetryPolicy decides on further attempts
+//      Unmarshal: read the body and call DeMarshalResponse. Only runs for DoAndDemarshall
+//      Complete: always runs last, even when the request failed
+type Handlers struct {
+    Build           HandlerList
+    Sign            HandlerList
+    Send            HandlerList
+    Unmarshal       HandlerList
+    Complete        HandlerList
+}
+
+// A deep copy, so the copy can be changed without affecting h.
+func (h Handlers) Copy() Handlers {
+    return Handlers{
+        Build: h.Build.copy(),
+        Sign: h.Sign.copy(),
+        Send: h.Send.copy(),
+        Unmarshal: h.Unmarshal.copy(),
+        Complete: h.Complete.copy(),
+    }
+}
+
+// Creates the stack of handlers every request uses by default.
+// Add your own to it, then set it on a request, a Config, or with SetDefaultHandlers.
+func NewHandlers() Handlers {
+    var h Handlers
+    h.Build.PushBack(Handler{"awsgo.ResolveEndpoint", resolveEndpointHandler})
+    h.Build.PushBack(Handler{"awsgo.DefaultHeaders", defaultHeadersHandler})
+    h.Sign.PushBack(Handler{"awsgo.Sign", signHandler})
+    h.Send.PushBack(Handler{"awsgo.BuildHttpRequest", buildHttpRequestHandler})
+    h.Send.PushBack(Handler{"awsgo.DumpRequest", dumpRequestHandler})
+    h.Send.PushBack(Handler{"awsgo.HttpSend", httpSendHandler})
+    h.Send.PushBack(Handler{"awsgo.DumpResponse", dumpResponseHandler})
+    h.Unmarshal.PushBack(Handler{"awsgo.ReadBody", readBodyHandler})
+    h.Unmarshal.PushBack(Handler{"awsgo.DeMarshal", deMarshalHandler})
+    h.Complete.PushBack(Handler{"awsgo.Metrics", metricsHandler})
+    h.Complete.PushBack(Handler{"awsgo.LogRequest", logRequestHandler})
+    h.Complete.PushBack(Handler{"awsgo.EndSpan", endSpanHandler})
+    return h
+}
+
+var (
+    handlersLock sync.RWMutex
+    defaultHandlers = NewHandlers()
+)
+
+// Sets the Handlers used by requests that don't specify their own.
+func SetDefaultHandlers(h Handlers) {
+    h = h.Copy()
+    handlersLock.Lock()
+    defer handlersLock.Unlock()
+    defaultHandlers = h
+}
+
+func getDefaultHandlers() Handlers {
+    handlersLock.RLock()
+    defer handlersLock.RUnlock()
+    return defaultHandlers
+}
+
+func resolveEndpointHandler(op *Operation) {
+    endpoint, err := resolveEndpoint(op.Request.Host, op.Request.EndpointResolver)
+    if err != nil {
+        op.Error = err
+        return
+    }
+    endpointUrl, err := url.Parse(endpoint.URL)
+    if err != nil {
+        op.Error = err
+        return
+    }
+    op.Request.endpoint = endpoint
+    op.Request.Headers["Host"] = strings.ToLower(endpointUrl.Host)
+}
+
+func defaultHeadersHandler(op *Operation) {
+    req := op.Request
+    req.Headers["user-agent"] = "go-aws-client-0.1"
+    if _, ok := req.Headers["x-amz-date"]; !ok {
+        req.Headers["x-amz-date"] = IsoDate(req.Date)
+    }
+    if req.Key.token != "" {
+        req.Headers["x-amz-security-token"] = req.Key.token
+    }
+}
+
+func signHandler(op *Operation) {
+    op.Error = signRequest(op.Request)
+}
+
+func buildHttpRequestHandler(op *Operation) {
+    req := op.Request
+    // create headers for the actual request
+    reqHeaders := http.Header{}
+    for k, v := range req.Headers {
+        reqHeaders.Add(k, v)
+    }
+    url_, err := getUrl(*req)
+    if err != nil {
+        op.Error = err
+        return
+    }
+    hreq := (&http.Request {
+        URL: url_,
+        Method: req.RequestMethod,
+        ProtoMajor: 1,
+        ProtoMinor: 1,
+        Header: reqHeaders,
+    }).WithContext(op.Context)
+    if val, ok := req.Headers["Content-Length"]; ok {
+        hreq.ContentLength, _ = strconv.ParseInt(val, 10, 64)
+    }
+    if req.PayloadFactory != nil {
+        if hreq.Body, err = req.PayloadFactory(); err != nil {
+            op.Error = err
+            return
+        }
+        // lets the http client resend it too, Eg. after a redirect
+        hreq.GetBody = req.PayloadFactory
+    } else if req.PayloadReader != nil {
+        hreq.Body = req.PayloadReader
+    }
+    if req.chunkSigner != nil {
+        getBody := hreq.GetBody
+        if hreq.Body == nil {
+            // even an empty payload has to send its final, empty, signed chunk
+            getBody = func () (io.ReadCloser, error) {
+                return ioutil.NopCloser(strings.NewReader(req.Payload)), nil
+            }
+            hreq.Body, _ = getBody()
+        }
+        hreq.Body = req.chunkSigner.wrap(hreq.Body)
+        if getBody != nil {
+            hreq.GetBody = func () (io.ReadCloser, error) {
+                body, err := getBody()
+                if err != nil {
+                    return nil, err
+                }
+                return req.chunkSigner.wrap(body), nil
+            }
+        }
+    } else if req.Payload != "" {
+        // for payloads we know are static, we can reset them for each try.
+        hreq.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(req.Payload)))
+    }
+    op.HttpRequest = hreq
+}
+
+func httpSendHandler(op *Operation) {
+    if op.HttpResponse != nil {
+        return
+    }
+    httpClient := op.Request.HttpClient
+    if httpClient == nil {
+        httpClient = DefaultHttpClient()
+    }
+    op.HttpResponse, op.Error = httpClient.Do(op.HttpRequest)
+}
+
+func readBodyHandler(op *Operation) {
+    defer op.HttpResponse.Body.Close()
+    buf := bytes.Buffer{}
+    if _, err := io.Copy(&buf, op.HttpResponse.Body); err != nil {
+        if op.Context.Err() != nil {
+            op.Error = op.Context.Err()
+            return
+        }
+        // i think i'm seeing some connection reset by peer errors here, but i'm not 100% sure
+        // best i could find was the suggestion that the body was closed by the server before
+        // we could read it, and that this might happen on bad request.
+        if op.HttpResponse.StatusCode >= 200 && op.HttpResponse.StatusCode < 300 {
+            op.Error = RequestError{
+                BaseError: err,
+                Location: "Aws.Request.DoAndDemarshall",
+                Action: "io.Copy",
+            }
+            return
+        }
+    }
+    op.ResponseBody = buf.Bytes()
+}
+
+func deMarshalHandler(op *Operation) {
+    val := op.Builder.DeMarshalResponse(op.ResponseBody, responseHeaders(op.HttpResponse), op.HttpResponse.StatusCode)
+    if t, ok := val.(error); ok {
+        var setter responseInfoSetter
+        if errors.As(t, &setter) {
+            setter.SetResponseInfo(op.HttpResponse.StatusCode, requestId(op.HttpResponse))
+        }
+        op.Error = t
+        return
+    }
+    op.Result = val
+}
+
+func responseHeaders(resp *http.Response) map[string]string {
+    headers := make(map[string]string)
+    for k, v := range resp.Header {
+        headers[strings.ToLower(k)] = strings.Join(v, ";")
+    }
+    return headers
+}
+
+// Takes the request through every stage of h.
+func (req AwsRequest) run(ctx context.Context, h Handlers, rb RequestBuilderInterface) *Operation {
+    op := &Operation{
+        Context: ctx,
+        Request: &req,
+        Builder: rb,
+        start: time.Now(),
+    }
+    op.startSpan()
+    if op.Error = ctx.Err(); op.Error == nil {
+        op.sendSigned(h, req)
+        // if AWS says our clock is off, sign again with the corrected time and try once more.
+        if op.Error == nil && op.clockSkewed() {
+            op.HttpResponse.Body.Close()
+            req.Date = skewedNow()
+            op.sendSigned(h, req)
+        }
+        // if our credentials came from a provider, and AWS says they are stale,
+        // get new ones and try once more.
+        if op.Error == nil && op.credentialsExpired() {
+            op.HttpResponse.Body.Close()
+            req.Key, op.Error = req.keyProvider.Retrieve()
+            if op.Error == nil {
+                req.Date = skewedNow()
+                op.sendSigned(h, req)
+            }
+        }
+        if op.Error == nil && rb != nil {
+            h.Unmarshal.Run(op)
+        }
+    }
+    // the http client closes bodies it sends, but we kept this one open to rewind it
+    if req.payloadCloser != nil {
+        req.payloadCloser.Close()
+    }
+    h.Complete.runAll(op)
+    return op
+}
+
+// Builds and signs a fresh copy of req, then sends it.
+func (op *Operation) sendSigned(h Handlers, req AwsRequest) {
+    // work on a copy of the headers, so we can sign again from scratch
+    headers := make(map[string]string, len(req.Headers) + 4)
+    for k, v := range req.Headers {
+        headers[k] = v
+    }
+    req.Headers = headers
+    op.Request = &req
+    op.HttpRequest = nil
+    op.HttpResponse = nil
+
+    if h.Build.Run(op); op.Error != nil {
+        return
+    }
+    if h.Sign.Run(op); op.Error != nil {
+        return
+    }
+    op.sendWithRetries(h)
+}
+
+// Runs the Send stage until it succeeds, or the RetryPolicy gives up.
+func (op *Operation) sendWithRetries(h Handlers) {
+    ctx := op.Context
+    // we can only retry if we can reset Body..
+    canRetry := op.Request.canResend()
+    retryPolicy := op.Request.RetryPolicy
+    if retryPolicy == nil {
+        retryPolicy = getDefaultRetryPolicy()
+    }
+    firstAttempt := time.Now()
+    for attempt := 1; ; attempt ++ {
+        op.Attempt = attempt
+        op.Error = nil
+        op.HttpResponse = nil
+        h.Send.Run(op)
+        err, resp := op.Error, op.HttpResponse
+        if err == nil && resp == nil {
+            err = errors.New("No Send handler produced a response")
+        }
+        if err != nil && ctx.Err() != nil {
+            op.Error = ctx.Err()
+            return
+        }
+        if err == nil && (resp.StatusCode < 400 || !canRetry) {
+            return
+        }
+        if !canRetry {
+            op.Error = RequestError{
+                BaseError: err,
+                Location: "Aws.Request.Do (Can not retry)",
+                Action: "httpClient.Do",
+            }
+            return
+        }
+        failed := RetryAttempt{
+            Attempt: attempt,
+            Elapsed: time.Since(firstAttempt),
+            Err: err,
+        }
+        if err == nil {
+            failed.StatusCode = resp.StatusCode
+            failed.ErrorCode = peekErrorCode(resp)
+            op.errorCode = failed.ErrorCode
+            if isThrottled(failed) {
+                op.throttles ++
+            }
+        }
+        delay, retry := retryPolicy.ShouldRetry(failed)
+        if !retry {
+            if err != nil {
+                op.HttpResponse = nil
+                op.Error = RequestError{
+                    BaseError: err,
+                    Location: "Aws.Request.Do (Gave up retrying)",
+                    Action: "httpClient.Do",
+                }
+            }
+            return
+        }
+        op.logRetry(failed, delay)
+        op.traceRetry(failed, delay)
+        if resp != nil {
+            resp.Body.Close()
+        }
+        if err = SleepWithContext(ctx, delay); err != nil {
+            op.HttpResponse = nil
+            op.Error = err
+            return
+        }
+    }
+}
+
+// True if the response says our credentials are stale, and they came from a provider
+// that can give us new ones. Can't resend if the payload was a reader.
+func (op *Operation) credentialsExpired() bool {
+    req := op.Request
+    if req.keyProvider == nil || !req.canResend() {
+        return false
+    }
+    resp := op.HttpResponse
+    if resp.StatusCode != 400 && resp.StatusCode != 403 {
+        return false
+    }
+    errorBody := bytes.Buffer{}
+    io.Copy(&errorBody, resp.Body)
+    resp.Body.Close()
+    resp.Body = ioutil.NopCloser(bytes.NewReader(errorBody.Bytes()))
+    if !IsExpiredCredentialsCode(ExtractErrorCode(errorBody.Bytes())) {
+        return false
+    }
+    if inv, ok := req.keyProvider.(CredentialsInvalidator); ok {
+        inv.Invalidate()
+    }
+    return true
+}
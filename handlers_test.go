@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "sync/atomic"
+    "testing"
+)
+
+func newEchoRequest(serverUrl string, h *Handlers) *echoRequest {
+    req := new(echoRequest)
+    req.Host.Service = "dynamodb"
+    req.Host.Region = "us-east-1"
+    req.Headers = make(map[string]string)
+    req.RequestMethod = "POST"
+    req.CanonicalUri = "/"
+    req.Key = Credentials{AccessKeyId: "akey", SecretAccessKey: "skey"}
+    req.RetryPolicy = NoRetryPolicy{}
+    req.EndpointResolver = EndpointResolverFunc(func (host AwsHost) (Endpoint, error) {
+        return Endpoint{URL: serverUrl}, nil
+    })
+    req.Handlers = h
+    return req
+}
+
+func doEcho(t *testing.T, req *echoRequest) (interface{}, error) {
+    request, err := NewAwsRequest(req, map[string]string{})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    request.RequestSigningType = RequestSigningType_AWS4
+    return request.DoAndDemarshall(req)
+}
+
+func Test_HandlersDefaultStack(t *testing.T) {
+    h := NewHandlers()
+    if names := h.Sign.Names(); !reflect.DeepEqual(names, []string{"awsgo.Sign"}) {
+        t.Fatalf("Unexpected sign handlers: %v", names)
+    }
+    h.Send.InsertAfter("awsgo.BuildHttpRequest", Handler{"test.Middle", func (op *Operation) {}})
+    if names := h.Send.Names(); !reflect.DeepEqual(names, []string{"awsgo.BuildHttpRequest", "test.Middle", "awsgo.DumpRequest", "awsgo.HttpSend", "awsgo.DumpResponse"}) {
+        t.Fatalf("Unexpected send handlers: %v", names)
+    }
+    h.Send.Remove("test.Middle")
+    if h.Send.Names()[1] != "awsgo.DumpRequest" {
+        t.Fatalf("Expected test.Middle to be removed: %v", h.Send.Names())
+    }
+    // the defaults are untouched
+    if len(NewHandlers().Send.Names()) != 4 {
+        t.Fatalf("Changing a copy changed the defaults")
+    }
+}
+
+func Test_HandlersCustomHeaderIsSigned(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("x-amz-meta-tag") != "blue" || !strings.Contains(r.Header.Get("Authorization"), "x-amz-meta-tag") {
+            http.Error(w, "missing tag", 400)
+            return
+        }
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    h := NewHandlers()
+    h.Build.PushBack(Handler{"test.Tag", func (op *Operation) {
+        op.Request.Headers["x-amz-meta-tag"] = "blue"
+    }})
+    resp, err := doEcho(t, newEchoRequest(ts.URL, &h))
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.(string) != "ok" {
+        t.Fatalf("Expected ok. Got %v", resp)
+    }
+}
+
+func Test_HandlersFaultInjection(t *testing.T) {
+    calls := 0
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        calls ++
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    h := NewHandlers()
+    h.Send.InsertAfter("awsgo.BuildHttpRequest", Handler{"test.Fault", func (op *Operation) {
+        if op.Attempt == 1 {
+            op.HttpResponse = &http.Response{
+                StatusCode: 500,
+                Body: ioutil.NopCloser(strings.NewReader(`{"__type":"InternalServerError"}`)),
+            }
+        }
+    }})
+    req := newEchoRequest(ts.URL, &h)
+    req.RetryPolicy = DefaultRetryPolicy{BaseDelay: 1}
+    resp, err := doEcho(t, req)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if resp.(string) != "ok" || calls != 1 {
+        t.Fatalf("Expected the second attempt to reach the server once. Got %v after %d calls", resp, calls)
+    }
+}
+
+func Test_HandlersValidateAndComplete(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, "not what we wanted")
+    }))
+    defer ts.Close()
+
+    invalid := errors.New("invalid response")
+    var completed error
+    h := NewHandlers()
+    h.Unmarshal.PushBack(Handler{"test.Validate", func (op *Operation) {
+        if op.Result.(string) != "ok" {
+            op.Error = invalid
+        }
+    }})
+    h.Complete.PushBack(Handler{"test.Audit", func (op *Operation) {
+        completed = op.Error
+    }})
+    if _, err := doEcho(t, newEchoRequest(ts.URL, &h)); err != invalid {
+        t.Fatalf("Expected the validation error. Got: %v", err)
+    }
+    if completed != invalid {
+        t.Fatalf("Expected Complete to see the error. Got: %v", completed)
+    }
+}
+
+func Test_DefaultClientReusesConnections(t *testing.T) {
+    ts := httptest.NewUnstartedServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, "ok")
+    }))
+    var conns int32
+    ts.Config.ConnState = func (c net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            atomic.AddInt32(&conns, 1)
+        }
+    }
+    ts.Start()
+    defer ts.Close()
+
+    for i := 0; i < 3; i ++ {
+        if _, err := doEcho(t, newEchoRequest(ts.URL, nil)); err != nil {
+            t.Fatalf("Error should be nil. Got: %v", err)
+        }
+    }
+    if n := atomic.LoadInt32(&conns); n != 1 {
+        t.Fatalf("Expected 1 connection. Got %d", n)
+    }
+}
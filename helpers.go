@@ -51,7 +51,8 @@ type ResponseMetaData struct {
 
 
 // The host of the service we are hitting.
-// Urls are formed by taking Service.Region.Domain
+// Urls are worked out by an EndpointResolver. Usually that is Service.Region.Domain,
+// but partitions (eg. China), global services and S3 have their own rules.
 type AwsHost struct {
     // Eg. dynamo
     Service string
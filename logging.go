@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "io"
+    "io/ioutil"
+    "log/slog"
+    "net/http"
+    "net/url"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// What is logged at each level:
+//      Info: one line per request, with the operation, status, latency, attempts and request id.
+//            Failed requests are logged at Warn.
+//      Debug: each retry, with the reason and the delay.
+//      LogLevelWire: the signed request and the response, with credentials redacted.
+const (
+    // Requests and responses, headers and bodies, are dumped at this level.
+    // It is below slog.LevelDebug, so the logger's handler has to ask for it.
+    LogLevelWire = slog.LevelDebug - 4
+)
+
+var (
+    loggerLock sync.RWMutex
+    defaultLogger *slog.Logger
+)
+
+// Sets the logger used by requests that don't specify their own. nil turns logging off.
+func SetDefaultLogger(logger *slog.Logger) {
+    loggerLock.Lock()
+    defer loggerLock.Unlock()
+    defaultLogger = logger
+}
+
+func (op *Operation) logger() *slog.Logger {
+    if op.Request != nil && op.Request.Logger != nil {
+        return op.Request.Logger
+    }
+    loggerLock.RLock()
+    defer loggerLock.RUnlock()
+    return defaultLogger
+}
+
+func (op *Operation) logEnabled(level slog.Level) (*slog.Logger, bool) {
+    logger := op.logger()
+    if logger == nil || !logger.Enabled(op.Context, level) {
+        return nil, false
+    }
+    return logger, true
+}
+
+// Eg. GetItem from a X-Amz-Target of DynamoDB_20120810.GetItem, or the Action of a query request.
+func operationName(req *AwsRequest) string {
+    if target, ok := req.Headers["X-Amz-Target"]; ok {
+        return target[strings.LastIndex(target, ".") + 1:]
+    }
+    if i := strings.Index(req.CanonicalUri, "?"); i >= 0 {
+        if q, err := url.ParseQuery(req.CanonicalUri[i + 1:]); err == nil && q.Get("Action") != "" {
+            return q.Get("Action")
+        }
+    }
+    return req.RequestMethod + " " + strings.SplitN(req.CanonicalUri, "?", 2)[0]
+}
+
+func requestId(resp *http.Response) string {
+    if resp == nil {
+        return ""
+    }
+    for _, name := range []string{"X-Amzn-Requestid", "X-Amz-Request-Id", "X-Amzn-Request-Id"} {
+        if v := resp.Header.Get(name); v != "" {
+            return v
+        }
+    }
+    return ""
+}
+
+// Logs the outcome of the request. Runs in the Complete stage.
+func logRequestHandler(op *Operation) {
+    level := slog.LevelInfo
+    if op.Error != nil || (op.HttpResponse != nil && op.HttpResponse.StatusCode >= 400) {
+        level = slog.LevelWarn
+    }
+    logger, ok := op.logEnabled(level)
+    if !ok || op.Request == nil {
+        return
+    }
+    attrs := []slog.Attr{
+        slog.String("service", op.Request.Host.Service),
+        slog.String("operation", operationName(op.Request)),
+        slog.Duration("latency", time.Since(op.start)),
+        slog.Int("attempts", op.Attempt),
+    }
+    if op.HttpResponse != nil {
+        attrs = append(attrs, slog.Int("status", op.HttpResponse.StatusCode))
+        if id := requestId(op.HttpResponse); id != "" {
+            attrs = append(attrs, slog.String("requestId", id))
+        }
+    }
+    if op.Error != nil {
+        attrs = append(attrs, slog.String("error", op.Error.Error()))
+    }
+    logger.LogAttrs(op.Context, level, "awsgo request", attrs...)
+}
+
+func (op *Operation) logRetry(failed RetryAttempt, delay time.Duration) {
+    logger, ok := op.logEnabled(slog.LevelDebug)
+    if !ok {
+        return
+    }
+    attrs := []slog.Attr{
+        slog.String("service", op.Request.Host.Service),
+        slog.String("operation", operationName(op.Request)),
+        slog.Int("attempt", failed.Attempt),
+        slog.Duration("delay", delay),
+    }
+    if failed.Err != nil {
+        attrs = append(attrs, slog.String("error", failed.Err.Error()))
+    } else {
+        attrs = append(attrs, slog.Int("status", failed.StatusCode), slog.String("errorCode", failed.ErrorCode))
+    }
+    logger.LogAttrs(op.Context, slog.LevelDebug, "awsgo retrying request", attrs...)
+}
+
+var redactedHeaders = map[string]bool{
+    "authorization": true,
+    "x-amz-security-token": true,
+}
+
+var redactedQuery = map[string]bool{
+    "signature": true,
+    "x-amz-signature": true,
+    "x-amz-credential": true,
+    "x-amz-security-token": true,
+    "securitytoken": true,
+}
+
+// secrets that turn up in response bodies, eg. from sts or the container credentials endpoint
+var redactedBody = []*regexp.Regexp{
+    regexp.MustCompile(`(<(SecretAccessKey|SessionToken|Token)>)[^<]*(</)`),
+    regexp.MustCompile(`("(SecretAccessKey|SessionToken|Token)"\s*:\s*")[^"]*(")`),
+}
+
+func redactUrl(u *url.URL) string {
+    if u.RawQuery == "" {
+        return u.String()
+    }
+    q := u.Query()
+    for k := range q {
+        if redactedQuery[strings.ToLower(k)] {
+            q.Set(k, "REDACTED")
+        }
+    }
+    c := *u
+    c.RawQuery = q.Encode()
+    return c.String()
+}
+
+func redactHeaders(h http.Header) string {
+    keys := make([]string, 0, len(h))
+    for k := range h {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    buf := bytes.Buffer{}
+    for _, k := range keys {
+        v := strings.Join(h[k], ";")
+        if redactedHeaders[strings.ToLower(k)] {
+            v = "REDACTED"
+        }
+        buf.WriteString(k + ": " + v + "\n")
+    }
+    return buf.String()
+}
+
+// Replaces the credentials in sts and container credentials response bodies with REDACTED.
+// Used for wire logging, and by awsgotest before a recorded body is saved.
+func RedactBody(body []byte) string {
+    for _, re := range redactedBody {
+        body = re.ReplaceAll(body, []byte("${1}REDACTED${3}"))
+    }
+    return string(body)
+}
+
+// Dumps the signed request. Runs in the Send stage, after the http request is built.
+func dumpRequestHandler(op *Operation) {
+    logger, ok := op.logEnabled(LogLevelWire)
+    if !ok || op.HttpRequest == nil {
+        return
+    }
+    body := "(streamed)"
+    if op.Request.PayloadReader == nil && op.Request.PayloadFactory == nil {
+        body = RedactBody([]byte(op.Request.Payload))
+    }
+    logger.LogAttrs(op.Context, LogLevelWire, "awsgo request dump",
+        slog.Int("attempt", op.Attempt),
+        slog.String("method", op.HttpRequest.Method),
+        slog.String("url", redactUrl(op.HttpRequest.URL)),
+        slog.String("headers", redactHeaders(op.HttpRequest.Header)),
+        slog.String("body", body),
+    )
+}
+
+// Dumps the response. The body is read, and replaced so it can still be unmarshalled.
+func dumpResponseHandler(op *Operation) {
+    logger, ok := op.logEnabled(LogLevelWire)
+    if !ok || op.HttpResponse == nil {
+        return
+    }
+    buf := bytes.Buffer{}
+    io.Copy(&buf, op.HttpResponse.Body)
+    op.HttpResponse.Body.Close()
+    op.HttpResponse.Body = ioutil.NopCloser(bytes.NewReader(buf.Bytes()))
+    logger.LogAttrs(op.Context, LogLevelWire, "awsgo response dump",
+        slog.Int("attempt", op.Attempt),
+        slog.Int("status", op.HttpResponse.StatusCode),
+        slog.String("headers", redactHeaders(op.HttpResponse.Header)),
+        slog.String("body", RedactBody(buf.Bytes())),
+    )
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func Test_LoggingRedactsCredentials(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("x-amzn-RequestId", "req-123")
+        fmt.Fprint(w, `{"Credentials":{"AccessKeyId":"ASIA","SecretAccessKey":"very-secret","SessionToken":"session-secret"}}`)
+    }))
+    defer ts.Close()
+
+    buf := bytes.Buffer{}
+    logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: LogLevelWire}))
+    req := newEchoRequest(ts.URL, nil)
+    req.Key = Credentials{AccessKeyId: "akey", SecretAccessKey: "skey", token: "token-secret"}
+    req.Headers["X-Amz-Target"] = "DynamoDB_20120810.GetItem"
+    req.Logger = logger
+    if _, err := doEcho(t, req); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    out := buf.String()
+    for _, secret := range []string{"very-secret", "session-secret", "token-secret", "Signature="} {
+        if strings.Contains(out, secret) {
+            t.Fatalf("Log contains %s:\n%s", secret, out)
+        }
+    }
+    for _, expected := range []string{"awsgo request dump", "awsgo response dump", "operation=GetItem", "requestId=req-123", "status=200"} {
+        if !strings.Contains(out, expected) {
+            t.Fatalf("Log is missing %s:\n%s", expected, out)
+        }
+    }
+}
+
+func Test_LoggingInfoHasNoDumps(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    buf := bytes.Buffer{}
+    req := newEchoRequest(ts.URL, nil)
+    req.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+    if _, err := doEcho(t, req); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if out := buf.String(); strings.Count(out, "\n") != 1 || !strings.Contains(out, "awsgo request") {
+        t.Fatalf("Expected one summary line. Got:\n%s", out)
+    }
+}
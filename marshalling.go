@@ -31,6 +31,7 @@
 package awsgo
 
 import (
+    "encoding/base64"
     "fmt"
     "strconv"
 )
@@ -46,6 +47,25 @@ type AwsNumberItem struct {
     ValuesStr []string  `json:"NS,omitempty"`
     ValueStr string     `json:"N,omitempty"`
 }
+type AwsBoolItem struct {
+    Value bool          `json:"BOOL"`
+}
+// Binary data is base64 encoded on the wire
+type AwsBinaryItem struct {
+    Value []byte        `json:"B,omitempty"`
+    Values [][]byte     `json:"BS,omitempty"`
+}
+type AwsNullItem struct {
+    Null bool           `json:"NULL"`
+}
+// A list whose entries can be of any type. Entries are converted with ConvertToAwsItem
+type AwsListItem struct {
+    Values []interface{}    `json:"L"`
+}
+// A map whose entries can be of any type. Entries are converted with ConvertToAwsItem
+type AwsMapItem struct {
+    Values map[string]interface{}   `json:"M"`
+}
 
 
 func newStringItem(items ... string) AwsStringItem {
@@ -78,90 +98,136 @@ func newNumberItem(items ... float64) AwsNumberItem {
 }
 
 // Converts from an unknown interface... like:
-//     string, []string, float, []float64
-// into the expected awsgo.AwsStringItem or awsgo.AwsNumberItem
+//     string, []string, float, []float64, bool, nil, []byte, [][]byte, []interface{}, map[string]interface{}
+// into the expected awsgo.AwsStringItem, awsgo.AwsNumberItem, awsgo.AwsBoolItem,
+// awsgo.AwsNullItem, awsgo.AwsBinaryItem, awsgo.AwsListItem or awsgo.AwsMapItem
 func ConvertToAwsItem(unknown interface{}) interface{} {
+    item, err := TryConvertToAwsItem(unknown)
+    if err != nil {
+        panic(err.Error())
+    }
+    return item
+}
+
+// Same as ConvertToAwsItem, but returns an error for types it does not know instead of panicking.
+func TryConvertToAwsItem(unknown interface{}) (interface{}, error) {
     switch j := unknown.(type) {
+        case nil:
+            return AwsNullItem{true}, nil
+        case bool:
+            return AwsBoolItem{j}, nil
+        case []byte:
+            return AwsBinaryItem{Value: j}, nil
+        case [][]byte:
+            return AwsBinaryItem{Values: j}, nil
+        case []interface{}:
+            list := make([]interface{}, len(j))
+            for i := range j {
+                item, err := TryConvertToAwsItem(j[i])
+                if err != nil {
+                    return nil, err
+                }
+                list[i] = item
+            }
+            return AwsListItem{list}, nil
+        case map[string]interface{}:
+            m := make(map[string]interface{}, len(j))
+            for k, v := range j {
+                item, err := TryConvertToAwsItem(v)
+                if err != nil {
+                    return nil, err
+                }
+                m[k] = item
+            }
+            return AwsMapItem{m}, nil
         case string:
-            return newStringItem(j)
+            return newStringItem(j), nil
         case float64:
-            return newNumberItem(j)
+            return newNumberItem(j), nil
         case int:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case uint:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case float32:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case int64:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case uint64:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case int32:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
         case uint32:
-            return newNumberItem(float64(j))
+            return newNumberItem(float64(j)), nil
+        case int16:
+            return newNumberItem(float64(j)), nil
+        case uint16:
+            return newNumberItem(float64(j)), nil
+        case int8:
+            return newNumberItem(float64(j)), nil
+        case uint8:
+            return newNumberItem(float64(j)), nil
         case []string:
-            return AwsStringItem{"", j}
+            return AwsStringItem{"", j}, nil
         case []int:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []uint:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []int64:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []uint64:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []int32:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []uint32:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []float32:
             // we need to cast these over
             vals64 := make([]float64, len(j))
             for i := range j {
                 vals64[i] = float64(j[i])
             }
-            return newNumberItem(vals64...)
+            return newNumberItem(vals64...), nil
         case []float64:
-            return newNumberItem(j...)
+            return newNumberItem(j...), nil
         case AwsNumberItem:
-            return j
+            return j, nil
         case AwsStringItem:
-            return j
+            return j, nil
+        case AwsBoolItem, AwsNullItem, AwsBinaryItem, AwsListItem, AwsMapItem:
+            return j, nil
         default:
-            panic(fmt.Sprintf("Unknown data type: %v %T", j, j))
-            return j
+            return nil, fmt.Errorf("Unknown data type: %v %T", j, j)
     }
-    return unknown
 }
 
 // converts from raw JSON map to the expected types Eg. float64, string
@@ -216,5 +282,75 @@ func FromRawMapToEasyTypedMap(raw map[string]map[string]interface{}, item map[st
                 panic(fmt.Sprintf("Item map was type 'NS' but did not have []string content! (We expect it as string, but convert to []float). Got %T", t))
             }
         }
+        for _, kind := range []string{"BOOL", "NULL", "B", "BS", "L", "M"} {
+            if _, ok := value[kind]; ok {
+                item[key] = FromRawValue(value)
+            }
+        }
+    }
+}
+
+// Converts one raw JSON attribute value to the expected type. Eg. {"N": "5"} becomes 5.0.
+// Lists become []interface{}, maps map[string]interface{}, binary []byte and NULL nil.
+func FromRawValue(value map[string]interface{}) interface{} {
+    for kind, v := range value {
+        switch kind {
+        case "S":
+            t, _ := v.(string)
+            return t
+        case "N":
+            t, _ := v.(string)
+            f, _ := strconv.ParseFloat(t, 64)
+            return f
+        case "BOOL":
+            t, _ := v.(bool)
+            return t
+        case "NULL":
+            return nil
+        case "B":
+            t, _ := v.(string)
+            b, _ := base64.StdEncoding.DecodeString(t)
+            return b
+        case "SS":
+            list, _ := v.([]interface{})
+            vals := make([]string, len(list))
+            for i := range list {
+                vals[i], _ = list[i].(string)
+            }
+            return vals
+        case "NS":
+            list, _ := v.([]interface{})
+            nums := make([]float64, len(list))
+            for i := range list {
+                t, _ := list[i].(string)
+                nums[i], _ = strconv.ParseFloat(t, 64)
+            }
+            return nums
+        case "BS":
+            list, _ := v.([]interface{})
+            bins := make([][]byte, len(list))
+            for i := range list {
+                t, _ := list[i].(string)
+                bins[i], _ = base64.StdEncoding.DecodeString(t)
+            }
+            return bins
+        case "L":
+            list, _ := v.([]interface{})
+            vals := make([]interface{}, len(list))
+            for i := range list {
+                entry, _ := list[i].(map[string]interface{})
+                vals[i] = FromRawValue(entry)
+            }
+            return vals
+        case "M":
+            m, _ := v.(map[string]interface{})
+            vals := make(map[string]interface{}, len(m))
+            for k := range m {
+                entry, _ := m[k].(map[string]interface{})
+                vals[k] = FromRawValue(entry)
+            }
+            return vals
+        }
     }
+    return nil
 }
@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    DefaultMetadataEndpoint = "http://169.254.169.254"
+    DefaultMetadataTokenTTL = 6 * time.Hour
+    // With AllowV1Fallback, how long to stick with IMDSv1 after a token couldn't be fetched
+    // before asking for one again. Off EC2 each ask can take the whole http timeout.
+    MetadataV1FallbackPeriod = 5 * time.Minute
+)
+
+// Returned when the metadata service responds with something other than a 200.
+type MetadataError struct {
+    StatusCode      int
+    Path            string
+}
+
+func (e MetadataError) Error() string {
+    return fmt.Sprintf("Got Status code: %d", e.StatusCode)
+}
+
+// Talks to the EC2 instance metadata service using IMDSv2 session tokens.
+// A token is fetched with a PUT to /latest/api/token, cached, and refreshed before it expires.
+// Safe for concurrent use.
+type MetadataClient struct {
+    // Where the metadata service lives. Defaults to AWS_EC2_METADATA_SERVICE_ENDPOINT,
+    // or http://169.254.169.254. Point this at an httptest server for testing.
+    Endpoint        string
+    // The http client to use. Defaults to one with a 5 second timeout.
+    HttpClient      *http.Client
+    // How long each token is valid for. Defaults to 6 hours, the most AWS allows.
+    TokenTTL        time.Duration
+    // If true and a token can't be fetched, fall back to unauthenticated IMDSv1 requests.
+    AllowV1Fallback bool
+
+    lock            sync.Mutex
+    token           string
+    tokenExpires    time.Time
+    // don't ask for a token again until then, just use IMDSv1
+    v1Until         time.Time
+}
+
+// Used for credentials and by the ec2 package.
+// Falls back to IMDSv1 so older instances keep working; set AllowV1Fallback to false to forbid it.
+var DefaultMetadataClient = &MetadataClient{AllowV1Fallback: true}
+
+var defaultMetadataHttpClient = &http.Client{Timeout: 5 * time.Second}
+
+// Gets the given path under /latest. Eg. "/meta-data/instance-id"
+func (c *MetadataClient) Get(path string) (string, error) {
+    return c.GetWithContext(context.Background(), path)
+}
+
+// Same as Get, but the call is aborted once ctx is done.
+func (c *MetadataClient) GetWithContext(ctx context.Context, path string) (string, error) {
+    var token string
+    if !c.usingV1() {
+        var err error
+        token, err = c.getToken(ctx)
+        if err != nil {
+            if !c.AllowV1Fallback || ctx.Err() != nil {
+                return "", err
+            }
+            c.fallBackToV1()
+        }
+    }
+    body, status, err := c.do(ctx, "GET", path, token)
+    if err != nil {
+        return "", err
+    }
+    // the token may have been revoked on us. Get a new one and try once more.
+    if status == 401 && token != "" {
+        c.invalidateToken()
+        if token, err = c.getToken(ctx); err != nil {
+            return "", err
+        }
+        if body, status, err = c.do(ctx, "GET", path, token); err != nil {
+            return "", err
+        }
+    }
+    if status == 401 && token == "" {
+        // IMDSv1 has been turned off, so ask for a token next time
+        c.lock.Lock()
+        c.v1Until = time.Time{}
+        c.lock.Unlock()
+    }
+    if status != 200 {
+        return "", MetadataError{StatusCode: status, Path: path}
+    }
+    return body, nil
+}
+
+func (c *MetadataClient) endpoint() string {
+    if c.Endpoint != "" {
+        return strings.TrimSuffix(c.Endpoint, "/")
+    }
+    if v := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"); v != "" {
+        return strings.TrimSuffix(v, "/")
+    }
+    return DefaultMetadataEndpoint
+}
+
+func (c *MetadataClient) ttl() time.Duration {
+    if c.TokenTTL > 0 {
+        return c.TokenTTL
+    }
+    return DefaultMetadataTokenTTL
+}
+
+func (c *MetadataClient) usingV1() bool {
+    if !c.AllowV1Fallback {
+        return false
+    }
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    return time.Now().Before(c.v1Until)
+}
+
+func (c *MetadataClient) fallBackToV1() {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    c.v1Until = time.Now().Add(MetadataV1FallbackPeriod)
+}
+
+func (c *MetadataClient) invalidateToken() {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    c.token = ""
+}
+
+// Returns the cached token, fetching a new one if it is missing or close to expiring.
+func (c *MetadataClient) getToken(ctx context.Context) (string, error) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    ttl := c.ttl()
+    // refresh with a tenth of the ttl left, so in flight requests don't use a dead token
+    if c.token != "" && time.Now().Add(ttl / 10).Before(c.tokenExpires) {
+        return c.token, nil
+    }
+    requested := time.Now()
+    body, status, err := c.do(ctx, "PUT", "/api/token", "")
+    if err != nil {
+        return "", err
+    }
+    if status != 200 {
+        return "", MetadataError{StatusCode: status, Path: "/api/token"}
+    }
+    c.token = body
+    c.tokenExpires = requested.Add(ttl)
+    return c.token, nil
+}
+
+func (c *MetadataClient) do(ctx context.Context, method, path, token string) (string, int, error) {
+    u, err := url.Parse(c.endpoint() + "/latest" + path)
+    if err != nil {
+        return "", 0, err
+    }
+    hreq := (&http.Request {
+        URL: u,
+        Method: method,
+        ProtoMajor: 1,
+        ProtoMinor: 1,
+        Header: http.Header{},
+    }).WithContext(ctx)
+    if method == "PUT" {
+        hreq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", fmt.Sprintf("%d", int64(c.ttl() / time.Second)))
+    }
+    if token != "" {
+        hreq.Header.Set("X-aws-ec2-metadata-token", token)
+    }
+    httpClient := c.HttpClient
+    if httpClient == nil {
+        httpClient = defaultMetadataHttpClient
+    }
+    resp, err := httpClient.Do(hreq)
+    if err != nil {
+        if ctx.Err() != nil {
+            return "", 0, ctx.Err()
+        }
+        return "", 0, err
+    }
+    defer resp.Body.Close()
+    buf := bytes.Buffer{}
+    io.Copy(&buf, resp.Body)
+    return buf.String(), resp.StatusCode, nil
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// a stand in for the metadata service. Counts token requests, and only
+// accepts 'goodToken' unless allowV1 is set.
+type fakeMetadata struct {
+    tokenRequests   int
+    allowV1         bool
+    tokenStatus     int
+    goodToken       string
+}
+
+func (f *fakeMetadata) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path == "/latest/api/token" {
+        f.tokenRequests ++
+        if r.Method != "PUT" {
+            http.Error(w, "", 405)
+            return
+        }
+        if r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+            http.Error(w, "", 400)
+            return
+        }
+        if f.tokenStatus != 0 {
+            http.Error(w, "", f.tokenStatus)
+            return
+        }
+        fmt.Fprint(w, f.goodToken)
+        return
+    }
+    token := r.Header.Get("X-aws-ec2-metadata-token")
+    if token != f.goodToken && !(token == "" && f.allowV1) {
+        http.Error(w, "", 401)
+        return
+    }
+    switch r.URL.Path {
+    case "/latest/meta-data/instance-id":
+        fmt.Fprint(w, "i-1234")
+    case "/latest/meta-data/iam/security-credentials/":
+        fmt.Fprint(w, "myrole\n")
+    case "/latest/meta-data/iam/security-credentials/myrole":
+        fmt.Fprint(w, `{"Code":"Success","AccessKeyId":"akey","SecretAccessKey":"skey","Token":"tok","Expiration":"2100-01-01T00:00:00Z"}`)
+    default:
+        http.Error(w, "", 404)
+    }
+}
+
+func Test_MetadataTokenIsCached(t *testing.T) {
+    fake := &fakeMetadata{goodToken: "token1"}
+    ts := httptest.NewServer(fake)
+    defer ts.Close()
+
+    client := &MetadataClient{Endpoint: ts.URL}
+    for i := 0; i < 3; i++ {
+        id, err := client.Get("/meta-data/instance-id")
+        if err != nil {
+            t.Fatalf("Error should be nil. Got: %v", err)
+        }
+        if id != "i-1234" {
+            t.Fatalf("Expected i-1234. Got %s", id)
+        }
+    }
+    if fake.tokenRequests != 1 {
+        t.Fatalf("Expected 1 token request. Got %d", fake.tokenRequests)
+    }
+}
+
+func Test_MetadataTokenRefreshedOn401(t *testing.T) {
+    fake := &fakeMetadata{goodToken: "token1"}
+    ts := httptest.NewServer(fake)
+    defer ts.Close()
+
+    client := &MetadataClient{Endpoint: ts.URL}
+    if _, err := client.Get("/meta-data/instance-id"); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    // the service forgets our token
+    fake.goodToken = "token2"
+    if _, err := client.Get("/meta-data/instance-id"); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if fake.tokenRequests != 2 {
+        t.Fatalf("Expected 2 token requests. Got %d", fake.tokenRequests)
+    }
+}
+
+func Test_MetadataV1Fallback(t *testing.T) {
+    fake := &fakeMetadata{allowV1: true, tokenStatus: 404}
+    ts := httptest.NewServer(fake)
+    defer ts.Close()
+
+    client := &MetadataClient{Endpoint: ts.URL}
+    if _, err := client.Get("/meta-data/instance-id"); err == nil {
+        t.Fatalf("Expected an error without AllowV1Fallback")
+    } else if mErr, ok := err.(MetadataError); !ok || mErr.StatusCode != 404 {
+        t.Fatalf("Expected a 404 MetadataError. Got %T : %v", err, err)
+    }
+
+    client.AllowV1Fallback = true
+    id, err := client.Get("/meta-data/instance-id")
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if id != "i-1234" {
+        t.Fatalf("Expected i-1234. Got %s", id)
+    }
+}
+
+func Test_MetadataV1FallbackIsRemembered(t *testing.T) {
+    fake := &fakeMetadata{allowV1: true, tokenStatus: 403}
+    ts := httptest.NewServer(fake)
+    defer ts.Close()
+
+    client := &MetadataClient{Endpoint: ts.URL, AllowV1Fallback: true}
+    for i := 0; i < 3; i++ {
+        if _, err := client.Get("/meta-data/instance-id"); err != nil {
+            t.Fatalf("Error should be nil. Got: %v", err)
+        }
+    }
+    if fake.tokenRequests != 1 {
+        t.Fatalf("Expected 1 token request while falling back. Got %d", fake.tokenRequests)
+    }
+
+    // once the period is over we try for a token again
+    client.v1Until = time.Now().Add(-time.Second)
+    fake.tokenStatus = 0
+    fake.goodToken = "token1"
+    fake.allowV1 = false
+    if _, err := client.Get("/meta-data/instance-id"); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if fake.tokenRequests != 2 {
+        t.Fatalf("Expected a second token request. Got %d", fake.tokenRequests)
+    }
+}
+
+func Test_InstanceMetadataCredentials(t *testing.T) {
+    fake := &fakeMetadata{goodToken: "token1"}
+    ts := httptest.NewServer(fake)
+    defer ts.Close()
+
+    provider := InstanceMetadataCredentialsProvider{Client: &MetadataClient{Endpoint: ts.URL}}
+    creds, err := provider.Retrieve()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if creds.AccessKeyId != "akey" || creds.SecretAccessKey != "skey" || creds.GetToken() != "tok" {
+        t.Fatalf("Got wrong credentials: %v", creds)
+    }
+    if creds.GetExpiration().Year() != 2100 {
+        t.Fatalf("Expected expiration in 2100. Got %v", creds.GetExpiration())
+    }
+}
+
+func Test_ContainerCredentials(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/v2/credentials/abc" || r.Header.Get("Authorization") != "secret-token" {
+            w.WriteHeader(401)
+            return
+        }
+        fmt.Fprint(w, `{"AccessKeyId":"akey","SecretAccessKey":"skey","Token":"tok","Expiration":"2100-01-01T00:00:00Z","RoleArn":"arn:aws:iam::123456789012:role/task"}`)
+    }))
+    defer ts.Close()
+
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", ts.URL + "/v2/credentials/abc")
+    t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "secret-token")
+    creds, err := (ContainerCredentialsProvider{}).Retrieve()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if creds.AccessKeyId != "akey" || creds.SecretAccessKey != "skey" || creds.GetToken() != "tok" {
+        t.Fatalf("Got wrong credentials: %v", creds)
+    }
+    if creds.GetExpiration().Year() != 2100 {
+        t.Fatalf("Expected expiration in 2100. Got %v", creds.GetExpiration())
+    }
+}
+
+func Test_ContainerCredentialsRejectsRemoteHttp(t *testing.T) {
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "http://example.com/creds")
+    if _, err := (ContainerCredentialsProvider{}).Retrieve(); err != Credentials_Error_ContainerHostNotAllowed {
+        t.Fatalf("Expected ContainerHostNotAllowed. Got: %v", err)
+    }
+}
+
+func Test_ContainerCredentialsNotConfigured(t *testing.T) {
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+    t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "")
+    if _, err := (ContainerCredentialsProvider{}).Retrieve(); err != Credentials_Error_EnvNotSet {
+        t.Fatalf("Expected EnvNotSet. Got: %v", err)
+    }
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "context"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// What happened to one request. Handed to a MetricsHook once the request is complete.
+type RequestMetrics struct {
+    // Eg. dynamodb
+    Service         string
+    // Eg. GetItem
+    Operation       string
+    Region          string
+    // The status code of the final response. 0 if there wasn't one.
+    StatusCode      int
+    // The service error code of the final response, Eg. ConditionalCheckFailedException
+    ErrorCode       string
+    // Set if the request failed
+    Err             error
+    // From the start of the request, including retries and backoff
+    Latency         time.Duration
+    // How many times the request was sent
+    Attempts        int
+    // How many of those attempts were throttled
+    Throttles       int
+    RequestId       string
+    // Capacity units used, for responses that report it. Eg. DynamoDB with ReturnConsumedCapacity set
+    ConsumedCapacity float64
+}
+
+// Implemented by responses that report the capacity they used.
+type ConsumedCapacityReporter interface {
+    ConsumedCapacityUnits() float64
+}
+
+// Receives the RequestMetrics of every request. Must be safe to call from many goroutines.
+type MetricsHook interface {
+    RequestCompleted(ctx context.Context, m RequestMetrics)
+}
+
+// Lets a plain function be used as a MetricsHook.
+type MetricsHookFunc func(ctx context.Context, m RequestMetrics)
+
+func (f MetricsHookFunc) RequestCompleted(ctx context.Context, m RequestMetrics) {
+    f(ctx, m)
+}
+
+var (
+    metricsLock sync.RWMutex
+    defaultMetricsHook MetricsHook
+)
+
+// Sets the MetricsHook used by requests that don't specify their own. nil turns metrics off.
+func SetDefaultMetricsHook(hook MetricsHook) {
+    metricsLock.Lock()
+    defer metricsLock.Unlock()
+    defaultMetricsHook = hook
+}
+
+func (op *Operation) metricsHook() MetricsHook {
+    if op.Request != nil && op.Request.MetricsHook != nil {
+        return op.Request.MetricsHook
+    }
+    metricsLock.RLock()
+    defer metricsLock.RUnlock()
+    return defaultMetricsHook
+}
+
+// Hands the RequestMetrics to the MetricsHook. Runs in the Complete stage.
+func metricsHandler(op *Operation) {
+    hook := op.metricsHook()
+    if hook == nil || op.Request == nil {
+        return
+    }
+    m := RequestMetrics{
+        Service: op.Request.Host.Service,
+        Operation: operationName(op.Request),
+        Region: op.Request.Host.Region,
+        Err: op.Error,
+        Latency: time.Since(op.start),
+        Attempts: op.Attempt,
+        Throttles: op.throttles,
+        RequestId: requestId(op.HttpResponse),
+    }
+    if op.HttpResponse != nil {
+        m.StatusCode = op.HttpResponse.StatusCode
+    }
+    if m.StatusCode >= 400 || m.Err != nil {
+        m.ErrorCode = op.errorCode
+        if m.ErrorCode == "" && op.ResponseBody != nil {
+            m.ErrorCode = ExtractErrorCode(op.ResponseBody)
+        }
+    }
+    if c, ok := op.Result.(ConsumedCapacityReporter); ok {
+        m.ConsumedCapacity = c.ConsumedCapacityUnits()
+    }
+    hook.RequestCompleted(op.Context, m)
+}
+
+// true for responses that mean we were throttled
+func isThrottled(failed RetryAttempt) bool {
+    return IsThrottlingCode(failed.ErrorCode) || failed.StatusCode == http.StatusTooManyRequests
+}
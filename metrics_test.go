@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+type capacityResult string
+
+func (c capacityResult) ConsumedCapacityUnits() float64 {
+    return 2.5
+}
+
+func Test_MetricsHookSeesThrottlesAndErrors(t *testing.T) {
+    calls := 0
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        calls ++
+        if calls == 1 {
+            http.Error(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException"}`, 400)
+            return
+        }
+        http.Error(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException"}`, 400)
+    }))
+    defer ts.Close()
+
+    var got RequestMetrics
+    req := newEchoRequest(ts.URL, nil)
+    req.Headers["X-Amz-Target"] = "DynamoDB_20120810.PutItem"
+    req.RetryPolicy = DefaultRetryPolicy{ThrottleBaseDelay: 1}
+    req.MetricsHook = MetricsHookFunc(func (ctx context.Context, m RequestMetrics) {
+        got = m
+    })
+    if _, err := doEcho(t, req); err == nil {
+        t.Fatalf("Expected an error")
+    }
+    if got.Service != "dynamodb" || got.Operation != "PutItem" || got.Attempts != 2 || got.Throttles != 1 {
+        t.Fatalf("Unexpected metrics: %+v", got)
+    }
+    if got.StatusCode != 400 || got.ErrorCode != "ConditionalCheckFailedException" || got.Err == nil {
+        t.Fatalf("Unexpected error metrics: %+v", got)
+    }
+}
+
+func Test_MetricsHookSeesConsumedCapacity(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    var got RequestMetrics
+    h := NewHandlers()
+    h.Unmarshal.PushBack(Handler{"test.Capacity", func (op *Operation) {
+        op.Result = capacityResult(op.Result.(string))
+    }})
+    req := newEchoRequest(ts.URL, &h)
+    req.MetricsHook = MetricsHookFunc(func (ctx context.Context, m RequestMetrics) {
+        got = m
+    })
+    if _, err := doEcho(t, req); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if got.ConsumedCapacity != 2.5 || got.StatusCode != 200 || got.ErrorCode != "" || got.Attempts != 1 {
+        t.Fatalf("Unexpected metrics: %+v", got)
+    }
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "crypto/sha256"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "sync"
+)
+
+// Gives a fresh copy of a request's body. Called once per attempt, so requests with one can be
+// retried. Pass one as the payload to NewAwsRequest, or give it an io.ReadSeeker and one is made
+// that rewinds it.
+type BodyFactory func() (io.ReadCloser, error)
+
+// A BodyFactory that rewinds r to where it was when we were given it. The bodies don't close r;
+// that is done once the request is finished.
+//
+// The transport may still be reading the last attempt's body after RoundTrip returns, Eg. when
+// the server answers before the upload is done. So each attempt reads through its own
+// offsetReader, and the previous one is closed (waiting out any Read in progress) before r is
+// rewound for the next.
+func seekerBody(r io.ReadSeeker) (BodyFactory, int64, error) {
+    start, err := r.Seek(0, io.SeekCurrent)
+    if err != nil {
+        return nil, 0, err
+    }
+    end, err := r.Seek(0, io.SeekEnd)
+    if err != nil {
+        return nil, 0, err
+    }
+    var last *offsetReader
+    factory := func() (io.ReadCloser, error) {
+        if last != nil {
+            last.Close()
+        }
+        if _, err := r.Seek(start, io.SeekStart); err != nil {
+            return nil, err
+        }
+        last = &offsetReader{r: r}
+        return last, nil
+    }
+    // leave it where we found it, until we need it
+    _, err = r.Seek(start, io.SeekStart)
+    return factory, end - start, err
+}
+
+// Reads one attempt's body from a seeker shared by every attempt. Once closed it never touches
+// the seeker again, and Close waits for a Read in progress to finish.
+type offsetReader struct {
+    lock        sync.Mutex
+    r           io.Reader
+    closed      bool
+}
+
+func (o *offsetReader) Read(p []byte) (int, error) {
+    o.lock.Lock()
+    defer o.lock.Unlock()
+    if o.closed {
+        return 0, io.EOF
+    }
+    return o.r.Read(p)
+}
+
+func (o *offsetReader) Close() error {
+    o.lock.Lock()
+    defer o.lock.Unlock()
+    o.closed = true
+    return nil
+}
+
+func readCloser(r io.Reader) io.ReadCloser {
+    if rc, ok := r.(io.ReadCloser); ok {
+        return rc
+    }
+    return ioutil.NopCloser(r)
+}
+
+// True if the body can be sent more than once.
+func (req *AwsRequest) canResend() bool {
+    return req.PayloadReader == nil
+}
+
+// The hex SHA256 of the payload, for SigV4. Bodies from a PayloadFactory are streamed through
+// the hash rather than held in memory. A PayloadReader can only be read once, so it gets
+// UNSIGNED-PAYLOAD, which only s3 accepts.
+func (req *AwsRequest) payloadSha256() (string, error) {
+    if req.PayloadReader != nil {
+        return UnsignedPayload, nil
+    }
+    if req.PayloadFactory == nil {
+        return fmt.Sprintf("%x", sha256.Sum256([]byte(req.Payload))), nil
+    }
+    body, err := req.PayloadFactory()
+    if err != nil {
+        return "", err
+    }
+    defer body.Close()
+    hasher := sha256.New()
+    if _, err := io.Copy(hasher, body); err != nil {
+        return "", err
+    }
+    return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// Fails the first attempt with a 500, and keeps the bodies it was sent.
+func newFlakyServer(bodies *[]string) *httptest.Server {
+    return httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        body, _ := ioutil.ReadAll(r.Body)
+        *bodies = append(*bodies, fmt.Sprintf("%d:%s", r.ContentLength, body))
+        if len(*bodies) == 1 {
+            http.Error(w, "oops", 500)
+            return
+        }
+        fmt.Fprint(w, "ok")
+    }))
+}
+
+func doEchoWithPayload(t *testing.T, req *echoRequest, payload interface{}) (interface{}, error) {
+    request, err := NewAwsRequest(req, payload)
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    request.RequestSigningType = RequestSigningType_AWS4
+    return request.DoAndDemarshall(req)
+}
+
+type closeCounter struct {
+    *strings.Reader
+    closes int
+}
+
+func (c *closeCounter) Close() error {
+    c.closes ++
+    return nil
+}
+
+func Test_ReadSeekerPayloadIsRetried(t *testing.T) {
+    var bodies []string
+    ts := newFlakyServer(&bodies)
+    defer ts.Close()
+
+    req := newEchoRequest(ts.URL, nil)
+    req.RetryPolicy = DefaultRetryPolicy{BaseDelay: 1}
+    source := &closeCounter{Reader: strings.NewReader("skip:hello world")}
+    source.Seek(5, io.SeekStart)
+    if _, err := doEchoWithPayload(t, req, source); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if len(bodies) != 2 || bodies[0] != "11:hello world" || bodies[1] != "11:hello world" {
+        t.Fatalf("Expected the body twice. Got: %v", bodies)
+    }
+    if source.closes != 1 {
+        t.Fatalf("Expected the source to be closed once. Got %d", source.closes)
+    }
+}
+
+// Counts Seeks that happen while a Read is still running.
+type overlapDetector struct {
+    *bytes.Reader
+    reading     int32
+    overlaps    int32
+}
+
+func (o *overlapDetector) Read(p []byte) (int, error) {
+    atomic.AddInt32(&o.reading, 1)
+    defer atomic.AddInt32(&o.reading, -1)
+    // slow enough that the transport is still mid Read when an early reply comes back
+    time.Sleep(5 * time.Millisecond)
+    return o.Reader.Read(p)
+}
+
+func (o *overlapDetector) Seek(offset int64, whence int) (int64, error) {
+    if atomic.LoadInt32(&o.reading) > 0 {
+        atomic.AddInt32(&o.overlaps, 1)
+    }
+    return o.Reader.Seek(offset, whence)
+}
+
+func Test_ReadSeekerPayloadRetriedAfterEarlyReply(t *testing.T) {
+    payload := bytes.Repeat([]byte("0123456789abcdef"), 1 << 14)
+    var attempts int32
+    var secondBody []byte
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        if atomic.AddInt32(&attempts, 1) == 1 {
+            // answer without reading the upload
+            http.Error(w, "oops", 500)
+            return
+        }
+        secondBody, _ = ioutil.ReadAll(r.Body)
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    req := newEchoRequest(ts.URL, nil)
+    req.RetryPolicy = DefaultRetryPolicy{BaseDelay: 1}
+    source := &overlapDetector{Reader: bytes.NewReader(payload)}
+    if _, err := doEchoWithPayload(t, req, source); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if attempts != 2 {
+        t.Fatalf("Expected 2 attempts. Got %d", attempts)
+    }
+    if !bytes.Equal(secondBody, payload) {
+        t.Fatalf("The retried body was corrupted. Got %d bytes", len(secondBody))
+    }
+    if source.overlaps != 0 {
+        t.Fatalf("The source was rewound while the last attempt was still reading it %d times", source.overlaps)
+    }
+}
+
+func Test_SeekerBodyClosesLastAttempt(t *testing.T) {
+    factory, length, err := seekerBody(strings.NewReader("hello"))
+    if err != nil || length != 5 {
+        t.Fatalf("Unexpected: %d %v", length, err)
+    }
+    first, _ := factory()
+    buf := make([]byte, 2)
+    first.Read(buf)
+    second, _ := factory()
+    if n, err := first.Read(buf); n != 0 || err != io.EOF {
+        t.Fatalf("The last attempt's body should be closed. Got %d %v", n, err)
+    }
+    if body, _ := ioutil.ReadAll(second); string(body) != "hello" {
+        t.Fatalf("Expected hello. Got %s", body)
+    }
+}
+
+func Test_BodyFactoryPayloadIsRetried(t *testing.T) {
+    var bodies []string
+    ts := newFlakyServer(&bodies)
+    defer ts.Close()
+
+    req := newEchoRequest(ts.URL, nil)
+    req.Headers["Content-Length"] = "5"
+    req.RetryPolicy = DefaultRetryPolicy{BaseDelay: 1}
+    calls := 0
+    factory := BodyFactory(func () (io.ReadCloser, error) {
+        calls ++
+        return ioutil.NopCloser(strings.NewReader("hello")), nil
+    })
+    if _, err := doEchoWithPayload(t, req, factory); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    // one to hash the payload when signing, and one per attempt
+    if len(bodies) != 2 || bodies[1] != "5:hello" || calls != 3 {
+        t.Fatalf("Expected the body twice. Got: %v after %d calls", bodies, calls)
+    }
+}
+
+func Test_ReaderPayloadIsNotRetried(t *testing.T) {
+    var bodies []string
+    ts := newFlakyServer(&bodies)
+    defer ts.Close()
+
+    req := newEchoRequest(ts.URL, nil)
+    req.Headers["Content-Length"] = "5"
+    req.RetryPolicy = DefaultRetryPolicy{BaseDelay: 1}
+    // hide the Seek
+    payload := struct { io.Reader }{strings.NewReader("hello")}
+    if _, err := doEchoWithPayload(t, req, payload); err == nil {
+        t.Fatalf("Expected an error")
+    }
+    if len(bodies) != 1 {
+        t.Fatalf("Expected a single attempt. Got: %v", bodies)
+    }
+}
+
+func Test_SigV4PayloadHashIsStreamed(t *testing.T) {
+    req := AwsRequest{
+        RequestMethod: "PUT",
+        CanonicalUri: "/examplebucket/test.txt",
+        Headers: map[string]string{"Host": "s3.amazonaws.com"},
+        Key: sigV4TestKey,
+        Date: sigV4TestDate,
+        endpoint: Endpoint{SigningRegion: "us-east-1", SigningName: "s3"},
+        PayloadFactory: func () (io.ReadCloser, error) {
+            return ioutil.NopCloser(strings.NewReader("Welcome to Amazon S3.")), nil
+        },
+    }
+    if err := req.createSignature(); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    if req.Headers["x-amz-content-sha256"] != fmt.Sprintf("%x", sha256.Sum256([]byte("Welcome to Amazon S3."))) {
+        t.Fatalf("Unexpected payload hash: %s", req.Headers["x-amz-content-sha256"])
+    }
+    req.PayloadFactory = nil
+    req.PayloadReader = ioutil.NopCloser(strings.NewReader("once"))
+    if err := req.createSignature(); err != nil || req.Headers["x-amz-content-sha256"] != UnsignedPayload {
+        t.Fatalf("Expected an unsigned payload. Got: %v %s", err, req.Headers["x-amz-content-sha256"])
+    }
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "crypto/sha256"
+    "errors"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// The longest a SigV4 presigned url can be valid for.
+const MaxPresignExpires = 7 * 24 * time.Hour
+
+var (
+    Verification_Error_PresignExpires = errors.New("Presign expires must be between 1 second and 7 days")
+    Verification_Error_PresignScope = errors.New("Presign needs a signing region and service. Set Host.Region and Host.Service")
+)
+
+// Builds rb, then returns a url with a SigV4 signature in its query string. Anyone can use the
+// url to make the request until expires has passed, without credentials of their own.
+// Only the host is signed, so no other headers need to be sent; x-amz- headers are moved into
+// the query. Works for any service, Eg. a s3 GetObjectRequest, or a sqs ReceiveMessageRequest
+// handed to a browser.
+func Presign(rb RequestBuilderInterface, expires time.Duration) (string, error) {
+    request, err := NewAwsRequest(rb, nil)
+    if err != nil {
+        return "", err
+    }
+    return request.Presign(expires)
+}
+
+// Same as Presign, for a request that has already been built.
+func (req AwsRequest) Presign(expires time.Duration) (string, error) {
+    if expires < time.Second || expires > MaxPresignExpires {
+        return "", Verification_Error_PresignExpires
+    }
+    endpoint, err := resolveEndpoint(req.Host, req.EndpointResolver)
+    if err != nil {
+        return "", err
+    }
+    // the url would look fine, but AWS would reject every use of it
+    if endpoint.SigningRegion == "" || endpoint.SigningName == "" {
+        return "", Verification_Error_PresignScope
+    }
+    endpointUrl, err := url.Parse(endpoint.URL)
+    if err != nil {
+        return "", err
+    }
+    path, query := splitCanonicalUri(req.CanonicalUri)
+
+    var params []string
+    if query != "" {
+        params = append(params, query)
+    }
+    // whoever uses the url can't send our headers, so anything AWS reads goes in the query
+    for k, v := range req.Headers {
+        name := strings.ToLower(k)
+        if strings.HasPrefix(name, "x-amz-") && name != "x-amz-date" && name != "x-amz-content-sha256" {
+            params = append(params, Escape(name) + "=" + Escape(v))
+        }
+    }
+    scope := v4Scope(req.Date, endpoint.SigningRegion, endpoint.SigningName)
+    params = append(params,
+        "X-Amz-Algorithm=" + v4Algorithm,
+        "X-Amz-Credential=" + Escape(req.Key.AccessKeyId + "/" + scope),
+        "X-Amz-Date=" + IsoDate(req.Date),
+        fmt.Sprintf("X-Amz-Expires=%d", int64(expires / time.Second)),
+        "X-Amz-SignedHeaders=host",
+    )
+    if req.Key.token != "" {
+        params = append(params, "X-Amz-Security-Token=" + Escape(req.Key.token))
+    }
+    canonicalQuery := v4CanonicalQuery(strings.Join(params, "&"))
+
+    // s3 can't know the payload of a presigned url ahead of time
+    payloadHash := UnsignedPayload
+    if endpoint.SigningName != "s3" {
+        payloadHash = fmt.Sprintf("%x", sha256.Sum256([]byte(req.Payload)))
+    }
+    canonicalHeaders := "host:" + strings.ToLower(endpointUrl.Host) + "\n"
+    canonicalReq := v4CanonicalRequest(req.RequestMethod, path, canonicalQuery, canonicalHeaders, "host", payloadHash)
+
+    signingKey := v4SigningKey(req.Key.SecretAccessKey, req.Date, endpoint.SigningRegion, endpoint.SigningName)
+    signature := v4Signature(signingKey, v4StringToSign(req.Date, scope, canonicalReq))
+
+    if path == "" {
+        path = "/"
+    }
+    return fmt.Sprintf("%s%s?%s&X-Amz-Signature=%s", endpoint.URL, path, canonicalQuery, signature), nil
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "io"
+    "math/rand"
+    "net"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// Error codes that mean we are being throttled. These are retried with a longer backoff.
+var throttlingErrorCodes = map[string]bool{
+    // dynamo
+    "ProvisionedThroughputExceededException": true,
+    "RequestLimitExceeded": true,
+    // dynamo, swf, cloudwatch logs
+    "ThrottlingException": true,
+    // cloudwatch, sns, ses
+    "Throttling": true,
+    // sqs
+    "RequestThrottled": true,
+    "AWS.SimpleQueueService.RequestThrottled": true,
+    // s3
+    "SlowDown": true,
+    // generic
+    "ThrottledException": true,
+    "TooManyRequestsException": true,
+    "RequestThrottledException": true,
+    "BandwidthLimitExceeded": true,
+}
+
+// Error codes that mean something went wrong on AWS's end, and trying again may work.
+var transientErrorCodes = map[string]bool{
+    "InternalError": true,
+    "InternalFailure": true,
+    "InternalServerError": true,
+    "ServiceUnavailable": true,
+    "ServiceUnavailableException": true,
+    "RequestTimeout": true,
+    "RequestTimeoutException": true,
+}
+
+// Error codes that mean the credentials used have expired, or been revoked.
+var expiredCredentialsCodes = map[string]bool{
+    "ExpiredToken": true,
+    "ExpiredTokenException": true,
+    "InvalidClientTokenId": true,
+    "UnrecognizedClientException": true,
+    "TokenRefreshRequired": true,
+}
+
+// Returns true if the service error code means new credentials are needed.
+func IsExpiredCredentialsCode(code string) bool {
+    return expiredCredentialsCodes[code]
+}
+
+// Returns true if the service error code means the request was throttled.
+func IsThrottlingCode(code string) bool {
+    return throttlingErrorCodes[code]
+}
+
+// Returns true if the service error code is safe to retry.
+// This includes all throttling codes.
+func IsRetryableCode(code string) bool {
+    return throttlingErrorCodes[code] || transientErrorCodes[code]
+}
+
+// Describes a failed attempt at a request. Handed to a RetryPolicy to decide what to do next.
+type RetryAttempt struct {
+    // How many attempts have been made so far. Starts at 1.
+    Attempt         int
+    // Time since the first attempt was sent.
+    Elapsed         time.Duration
+    // The status code of the response. 0 if we never got a response.
+    StatusCode      int
+    // The service error code, Eg. ProvisionedThroughputExceededException. Empty if unknown.
+    ErrorCode       string
+    // The error from the http client, if we never got a response.
+    Err             error
+}
+
+// Decides whether a failed request should be tried again.
+type RetryPolicy interface {
+    // Returns how long to wait before the next attempt, and false if we should give up.
+    ShouldRetry(attempt RetryAttempt) (time.Duration, bool)
+}
+
+// The RetryPolicy used when one isn't set on the request.
+// Retries throttling, 5xx and temporary network errors using full jitter exponential backoff.
+type DefaultRetryPolicy struct {
+    // Total number of attempts, including the first. 0 or less means use the default of 6.
+    MaxAttempts         int
+    // Stop retrying once this much time has passed since the first attempt. 0 means no limit.
+    MaxElapsed          time.Duration
+    // Base backoff for non throttling errors. 0 means 50ms.
+    BaseDelay           time.Duration
+    // Base backoff for throttling errors. 0 means 500ms.
+    ThrottleBaseDelay   time.Duration
+    // Cap on a single backoff. 0 means 20s.
+    MaxDelay            time.Duration
+}
+
+var (
+    retryPolicyLock sync.RWMutex
+    defaultRetryPolicy RetryPolicy = DefaultRetryPolicy{}
+)
+
+// Sets the RetryPolicy used by requests that don't specify their own.
+func SetDefaultRetryPolicy(policy RetryPolicy) {
+    retryPolicyLock.Lock()
+    defer retryPolicyLock.Unlock()
+    defaultRetryPolicy = policy
+}
+
+func getDefaultRetryPolicy() RetryPolicy {
+    retryPolicyLock.RLock()
+    defer retryPolicyLock.RUnlock()
+    return defaultRetryPolicy
+}
+
+// A RetryPolicy that never retries.
+type NoRetryPolicy struct {}
+
+func (p NoRetryPolicy) ShouldRetry(attempt RetryAttempt) (time.Duration, bool) {
+    return 0, false
+}
+
+func (p DefaultRetryPolicy) ShouldRetry(attempt RetryAttempt) (time.Duration, bool) {
+    maxAttempts := p.MaxAttempts
+    if maxAttempts <= 0 {
+        maxAttempts = 6
+    }
+    if attempt.Attempt >= maxAttempts {
+        return 0, false
+    }
+    throttled := IsThrottlingCode(attempt.ErrorCode) || attempt.StatusCode == 429
+    if !throttled && !IsRetryable(attempt) {
+        return 0, false
+    }
+    base := p.BaseDelay
+    if base <= 0 {
+        base = 50 * time.Millisecond
+    }
+    if throttled {
+        base = p.ThrottleBaseDelay
+        if base <= 0 {
+            base = 500 * time.Millisecond
+        }
+    }
+    maxDelay := p.MaxDelay
+    if maxDelay <= 0 {
+        maxDelay = 20 * time.Second
+    }
+    delay := FullJitterBackoff(attempt.Attempt, base, maxDelay)
+    if p.MaxElapsed > 0 && attempt.Elapsed + delay > p.MaxElapsed {
+        return 0, false
+    }
+    return delay, true
+}
+
+// Returns true if the attempt failed in a way that is safe to retry.
+// That is throttling, 5xx errors, retryable service error codes and temporary network errors.
+func IsRetryable(attempt RetryAttempt) bool {
+    if attempt.Err != nil {
+        return isRetryableNetError(attempt.Err)
+    }
+    if IsRetryableCode(attempt.ErrorCode) {
+        return true
+    }
+    if attempt.StatusCode == 429 {
+        return true
+    }
+    // 501 Not Implemented is never going to change
+    return attempt.StatusCode >= 500 && attempt.StatusCode != 501
+}
+
+func isRetryableNetError(err error) bool {
+    // try mimic the fix in https://code.google.com/p/go/issues/detail?id=6163
+    if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) {
+        return true
+    }
+    var netErr net.Error
+    if errors.As(err, &netErr) {
+        if netErr.Timeout() || netErr.Temporary() {
+            return true
+        }
+    }
+    // the server hung up on a kept alive connection
+    if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+        return true
+    }
+    return strings.Contains(err.Error(), "connection reset")
+}
+
+// Picks a random delay between 0 and min(maxDelay, base * 2^(attempt-1)).
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+func FullJitterBackoff(attempt int, base, maxDelay time.Duration) time.Duration {
+    ceiling := base
+    for i := 1; i < attempt && ceiling < maxDelay; i++ {
+        ceiling *= 2
+    }
+    if ceiling > maxDelay {
+        ceiling = maxDelay
+    }
+    if ceiling <= 0 {
+        return 0
+    }
+    return time.Duration(rand.Int63n(int64(ceiling)))
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "syscall"
+    "testing"
+    "time"
+)
+
+func Test_FullJitterBackoffBounds(t *testing.T) {
+    base := 10 * time.Millisecond
+    maxDelay := 100 * time.Millisecond
+    tests := []struct {
+        attempt     int
+        ceiling     time.Duration
+    }{
+        {1, 10 * time.Millisecond},
+        {2, 20 * time.Millisecond},
+        {3, 40 * time.Millisecond},
+        {4, 80 * time.Millisecond},
+        {5, 100 * time.Millisecond},
+        {50, 100 * time.Millisecond},
+    }
+    for _, test := range tests {
+        sawAboveHalf := false
+        for i := 0; i < 500; i++ {
+            d := FullJitterBackoff(test.attempt, base, maxDelay)
+            if d < 0 || d >= test.ceiling {
+                t.Fatalf("Attempt %d: delay %v outside [0, %v)", test.attempt, d, test.ceiling)
+            }
+            if d > test.ceiling / 2 {
+                sawAboveHalf = true
+            }
+        }
+        if !sawAboveHalf {
+            t.Errorf("Attempt %d: delay never exceeded half of %v, backoff isn't growing", test.attempt, test.ceiling)
+        }
+    }
+    if d := FullJitterBackoff(3, 0, maxDelay); d != 0 {
+        t.Errorf("A zero base should give no delay. Got %v", d)
+    }
+}
+
+func Test_DefaultRetryPolicyMaxDelay(t *testing.T) {
+    p := DefaultRetryPolicy{MaxAttempts: 100, BaseDelay: time.Second, MaxDelay: 3 * time.Millisecond}
+    for i := 1; i < 50; i++ {
+        d, ok := p.ShouldRetry(RetryAttempt{Attempt: i, StatusCode: 500})
+        if !ok {
+            t.Fatalf("Attempt %d should be retried", i)
+        }
+        if d >= 3 * time.Millisecond {
+            t.Fatalf("Attempt %d: delay %v is above MaxDelay", i, d)
+        }
+    }
+    p = DefaultRetryPolicy{MaxAttempts: 3}
+    if _, ok := p.ShouldRetry(RetryAttempt{Attempt: 3, StatusCode: 500}); ok {
+        t.Errorf("Should give up after MaxAttempts")
+    }
+    p = DefaultRetryPolicy{MaxElapsed: time.Millisecond, BaseDelay: time.Hour}
+    if _, ok := p.ShouldRetry(RetryAttempt{Attempt: 5, StatusCode: 500, Elapsed: time.Millisecond}); ok {
+        t.Errorf("Should give up once MaxElapsed has passed")
+    }
+    if _, ok := p.ShouldRetry(RetryAttempt{Attempt: 1, StatusCode: 400, ErrorCode: "ValidationException"}); ok {
+        t.Errorf("Should not retry a validation error")
+    }
+}
+
+type tempNetError struct {
+    timeout     bool
+    temporary   bool
+}
+
+func (e tempNetError) Error() string { return "net error" }
+func (e tempNetError) Timeout() bool { return e.timeout }
+func (e tempNetError) Temporary() bool { return e.temporary }
+
+var _ net.Error = tempNetError{}
+
+func Test_IsRetryableClassification(t *testing.T) {
+    tests := []struct {
+        name        string
+        attempt     RetryAttempt
+        retryable   bool
+        throttling  bool
+    }{
+        {"throttling code", RetryAttempt{StatusCode: 400, ErrorCode: "ProvisionedThroughputExceededException"}, true, true},
+        {"s3 slow down", RetryAttempt{StatusCode: 503, ErrorCode: "SlowDown"}, true, true},
+        {"429 without code", RetryAttempt{StatusCode: 429}, true, true},
+        {"transient code", RetryAttempt{StatusCode: 400, ErrorCode: "RequestTimeout"}, true, false},
+        {"500", RetryAttempt{StatusCode: 500}, true, false},
+        {"503", RetryAttempt{StatusCode: 503}, true, false},
+        {"501", RetryAttempt{StatusCode: 501}, false, false},
+        {"400 validation", RetryAttempt{StatusCode: 400, ErrorCode: "ValidationException"}, false, false},
+        {"404", RetryAttempt{StatusCode: 404}, false, false},
+        {"connection reset", RetryAttempt{Err: fmt.Errorf("write: %w", syscall.ECONNRESET)}, true, false},
+        {"connection aborted", RetryAttempt{Err: syscall.ECONNABORTED}, true, false},
+        {"net timeout", RetryAttempt{Err: tempNetError{timeout: true}}, true, false},
+        {"unexpected EOF", RetryAttempt{Err: io.ErrUnexpectedEOF}, true, false},
+        {"wrapped EOF", RetryAttempt{Err: fmt.Errorf("reading response: %w", io.EOF)}, true, false},
+        {"EOF in the text only", RetryAttempt{Err: errors.New("no more input: EOF")}, false, false},
+        {"other net error", RetryAttempt{Err: tempNetError{}}, false, false},
+        {"bad url", RetryAttempt{Err: errors.New("unsupported protocol scheme")}, false, false},
+    }
+    for _, test := range tests {
+        if got := IsRetryable(test.attempt); got != test.retryable {
+            t.Errorf("%s: IsRetryable = %v, expected %v", test.name, got, test.retryable)
+        }
+        if test.attempt.Err != nil {
+            continue
+        }
+        throttled := IsThrottlingCode(test.attempt.ErrorCode) || test.attempt.StatusCode == 429
+        if throttled != test.throttling {
+            t.Errorf("%s: throttled = %v, expected %v", test.name, throttled, test.throttling)
+        }
+        // a 1ns base always gives no delay, so only throttling, with its hour base, waits
+        delay, ok := DefaultRetryPolicy{BaseDelay: time.Nanosecond, ThrottleBaseDelay: time.Hour}.ShouldRetry(test.attempt)
+        if ok != test.retryable {
+            t.Errorf("%s: ShouldRetry = %v, expected %v", test.name, ok, test.retryable)
+        }
+        if ok && (delay > 0) != test.throttling {
+            t.Errorf("%s: delay %v, throttling should use ThrottleBaseDelay", test.name, delay)
+        }
+    }
+}
@@ -42,6 +42,8 @@ import (
 
 // Generates an expirable link to an s3 object
 // Generated link will be: "//s3.amazonaws.com/{bucket}/{path}?{queryargs}"
+// These are signed with the old V2 signature, which regions added since 2014 don't accept;
+// use awsgo.Presign with a GetObjectRequest there.
 func GenerateExpirableLinkVersion(bucket, path string, expires time.Time, creds awsgo.Credentials, version string) string {
     var header string
 
@@ -31,6 +31,7 @@
 package s3
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "fmt"
 )
@@ -128,12 +129,17 @@ func (gi * GetObjectRequest) VerifyInput() (error) {
 
 
 func (gor GetObjectRequest) Request() (*GetObjectResponse, error) {
+    return gor.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gor GetObjectRequest) RequestWithContext(ctx context.Context) (*GetObjectResponse, error) {
     request, err := awsgo.NewAwsRequest(&gor, nil)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_REST
-    resp, err := request.DoAndDemarshall(&gor)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gor)
     if resp == nil {
         return nil, err
     }
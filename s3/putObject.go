@@ -31,6 +31,7 @@
 package s3
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -42,8 +43,15 @@ import (
 type PutObjectRequest struct {
     awsgo.RequestBuilder
 
+    // Uploads from an io.ReadSeeker (Eg. an *os.File) are rewound and retried if they fail.
+    // Anything else is only sent once.
     Source io.ReadCloser
+    // Used instead of Source when set. Called for a fresh copy of the object on each attempt.
+    Body awsgo.BodyFactory
     Length int64
+    // Sign with SigV4, sending the object as signed chunks (aws-chunked) so it is never buffered.
+    // Needs Length, and Host.Region. Otherwise the old REST signature is used.
+    Chunked bool
     ContentType string
     Permissions string
     Path string
@@ -74,11 +82,20 @@ func NewPutObjectRequest() (*PutObjectRequest) {
 type BadStatusCodeError struct {
     StatusCode              int
     Content                 string
+    RequestId               string
 }
 func (b BadStatusCodeError) Error() string {
     return fmt.Sprintf("Code: %d", b.StatusCode)
 }
 
+func (b BadStatusCodeError) ErrorCode() string { return awsgo.ExtractErrorCode([]byte(b.Content)) }
+func (b BadStatusCodeError) ErrorMessage() string { return awsgo.ExtractErrorMessage([]byte(b.Content)) }
+func (b BadStatusCodeError) HttpStatusCode() int { return b.StatusCode }
+func (b BadStatusCodeError) RequestID() string { return b.RequestId }
+func (b BadStatusCodeError) Retryable() bool { return awsgo.IsRetryableError(b.ErrorCode(), b.StatusCode) }
+func (b BadStatusCodeError) Throttling() bool { return awsgo.IsThrottlingError(b.ErrorCode(), b.StatusCode) }
+func (b BadStatusCodeError) Is(target error) bool { return awsgo.MatchesErrorCode(b, target) }
+
 func (por PutObjectRequest) DeMarshalResponse(a []byte, headers map[string]string, statusCode int) (interface{}) {
     if headers == nil {
         return nil
@@ -87,6 +104,7 @@ func (por PutObjectRequest) DeMarshalResponse(a []byte, headers map[string]strin
         return BadStatusCodeError{
             StatusCode: statusCode,
             Content: string(a),
+            RequestId: headers["x-amz-request-id"],
         }
     }
     response := new(PutObjectResponse)
@@ -106,12 +124,22 @@ func (por PutObjectRequest) DeMarshalResponse(a []byte, headers map[string]strin
     return response
 }
 
+func (por PutObjectRequest) signingType() int {
+    if por.Chunked {
+        return awsgo.RequestSigningType_AWS4_CHUNKED
+    }
+    return awsgo.RequestSigningType_REST
+}
+
+func (por PutObjectRequest) payload() interface{} {
+    if por.Body != nil {
+        return por.Body
+    }
+    return por.Source
+}
+
 func (por * PutObjectRequest) VerifyInput() (error) {
     por.Host.Service = "s3"
-    if por.Host.Region != "" {
-        por.Host.Service = "s3-" + por.Host.Region
-        por.Host.Region = ""
-    }
     if len(por.ContentType) == 0 {
         return errors.New("ContentType be empty")
     }
@@ -121,6 +149,9 @@ func (por * PutObjectRequest) VerifyInput() (error) {
     if len(por.Path) == 0 {
         return errors.New("Path be empty")
     }
+    if por.Chunked && len(por.Host.Region) == 0 {
+        return errors.New("Host.Region must be set to use Chunked")
+    }
     por.Headers["Content-Type"] = por.ContentType
     por.Headers["x-amz-acl"] = por.Permissions
     por.Headers["Content-Length"] = fmt.Sprintf("%d", por.Length)
@@ -134,7 +165,7 @@ func (por * PutObjectRequest) VerifyInput() (error) {
 
 
 func (por PutObjectRequest) CoRequest() (*PutObjectResponseFuture, error) {
-    request, err := awsgo.NewAwsRequest(&por, por.Source)
+    request, err := awsgo.NewAwsRequest(&por, por.payload())
     if err != nil {
         return nil, err
     }
@@ -146,7 +177,7 @@ func (por PutObjectRequest) CoRequest() (*PutObjectResponseFuture, error) {
 }
 
 func (por PutObjectRequest) CoDoAndDemarshall(request awsgo.AwsRequest, future * PutObjectResponseFuture) {
-    request.RequestSigningType = awsgo.RequestSigningType_REST
+    request.RequestSigningType = por.signingType()
     resp, err := request.DoAndDemarshall(&por)
     if err != nil {
         future.errResponse <- err
@@ -158,12 +189,17 @@ func (por PutObjectRequest) CoDoAndDemarshall(request awsgo.AwsRequest, future *
 }
 
 func (por PutObjectRequest) Request() (*PutObjectResponse, error) {
-    request, err := awsgo.NewAwsRequest(&por, por.Source)
+    return por.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (por PutObjectRequest) RequestWithContext(ctx context.Context) (*PutObjectResponse, error) {
+    request, err := awsgo.NewAwsRequest(&por, por.payload())
     if err != nil {
         return nil, err
     }
-    request.RequestSigningType = awsgo.RequestSigningType_REST
-    resp, err := request.DoAndDemarshall(&por)
+    request.RequestSigningType = por.signingType()
+    resp, err := request.DoAndDemarshallWithContext(ctx, &por)
     if resp == nil {
         return nil, err
     }
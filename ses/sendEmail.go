@@ -31,6 +31,7 @@
 package ses
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     //"errors"
     "fmt"
@@ -115,7 +116,9 @@ func addStringList(uri string, item []string, prefix string) string {
 
 func (gir * SendEmailRequest) VerifyInput() (error) {
     gir.Host.Service = "email"
-    gir.Host.Region = "us-east-1"
+    if gir.Host.Region == "" {
+        gir.Host.Region = "us-east-1"
+    }
 
     gir.CanonicalUri = "/?Action=SendEmail&Version=2010-12-01"
 
@@ -146,13 +149,18 @@ func (gir SendEmailRequest) DeMarshalResponse(response []byte, headers map[strin
     return giResponse
 }
 
-func (gir SendEmailRequest) Request() (*SendEmailResponse, error) {    
+func (gir SendEmailRequest) Request() (*SendEmailResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir SendEmailRequest) RequestWithContext(ctx context.Context) (*SendEmailResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
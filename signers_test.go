@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "testing"
+)
+
+func Test_V2Signature(t *testing.T) {
+    req := AwsRequest{
+        RequestMethod: "GET",
+        CanonicalUri: "/123456789012/MyQueue?Action=SendMessage&MessageBody=hello%20world&Version=2012-11-05",
+        Headers: map[string]string{"Host": "sqs.us-east-1.amazonaws.com"},
+        Key: sigV4TestKey,
+        Date: sigV4TestDate,
+    }
+    req.createV2Signature()
+    expected := "/123456789012/MyQueue?Action=SendMessage&MessageBody=hello%20world&Version=2012-11-05" +
+        "&AWSAccessKeyId=AKIDEXAMPLE&SignatureMethod=HmacSHA256&SignatureVersion=2&Timestamp=2015-08-30T12%3A36%3A00Z" +
+        "&Signature=y65pEwPzjsvDvoxqlGbdYYaFbbN160y1ySbsRIONpms%3D"
+    if req.CanonicalUri != expected {
+        t.Fatalf("Expected '%s'. Got '%s'", expected, req.CanonicalUri)
+    }
+}
+
+func Test_Aws3Signature(t *testing.T) {
+    req := AwsRequest{
+        RequestMethod: "POST",
+        CanonicalUri: "/",
+        Headers: map[string]string{
+            "Host": "swf.us-east-1.amazonaws.com",
+            "X-Amz-Target": "SimpleWorkflowService.PollForDecisionTask",
+            "Content-Type": "application/x-amz-json-1.0",
+        },
+        Payload: `{"domain":"test"}`,
+        Key: sigV4TestKey,
+        Date: sigV4TestDate,
+    }
+    req.createSignatureAws3()
+    if req.Headers["x-amz-date"] != "Sun, 30 Aug 2015 12:36:00 UTC" {
+        t.Fatalf("Unexpected x-amz-date: %s", req.Headers["x-amz-date"])
+    }
+    expected := "AWS3 AWSAccessKeyId=AKIDEXAMPLE,Algorithm=HmacSHA256,SignedHeaders=host;x-amz-date;x-amz-target," +
+        "Signature=Ddp8dYKMolqWBAGOjy8e0sBpNGHenfF0nkZc7MB+aHc="
+    if req.Headers["X-Amzn-Authorization"] != expected {
+        t.Fatalf("Expected '%s'. Got '%s'", expected, req.Headers["X-Amzn-Authorization"])
+    }
+}
+
+func Test_RestSignature(t *testing.T) {
+    req := AwsRequest{
+        RequestMethod: "GET",
+        CanonicalUri: "/examplebucket/test.txt",
+        Headers: map[string]string{
+            "Host": "s3.amazonaws.com",
+            "x-amz-date": "20150830T123600Z",
+        },
+        Key: sigV4TestKey,
+    }
+    req.createRestSignature()
+    if req.Headers["Authorization"] != "AWS AKIDEXAMPLE:ztVkrPCu9hSGHKpjPZzJfmxrE9o=" {
+        t.Fatalf("Unexpected Authorization: %s", req.Headers["Authorization"])
+    }
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+)
+
+const (
+    v4Algorithm = "AWS4-HMAC-SHA256"
+    // Used in place of the payload hash when the payload isn't signed, Eg. presigned s3 urls.
+    UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// http://docs.aws.amazon.com/general/latest/gr/sigv4-create-canonical-request.html
+func (req * AwsRequest) createSignature() error {
+    payloadHash, err := req.payloadSha256()
+    if err != nil {
+        return err
+    }
+    req.signV4(payloadHash)
+    return nil
+}
+
+// Signs the request with the given payload hash, setting the Authorization header.
+// Returns the signing key, so chunks of a streamed payload can be signed with it.
+func (req * AwsRequest) signV4(payloadHash string) []byte {
+    // the resolved endpoint knows when the service name and the url don't match
+    fixedService := req.endpoint.SigningName
+    region := req.endpoint.SigningRegion
+
+    req.scope = v4Scope(req.Date, region, fixedService)
+
+    fixedUrl, query := splitCanonicalUri(req.CanonicalUri)
+
+    // s3 wants to know the payload hash, nobody else cares
+    if fixedService == "s3" {
+        req.Headers["x-amz-content-sha256"] = payloadHash
+    }
+
+    canonicalHeaders, signedHeaders := v4CanonicalHeaders(req.Headers)
+    canonicalReq := v4CanonicalRequest(req.RequestMethod, fixedUrl, v4CanonicalQuery(query),
+        canonicalHeaders, signedHeaders, payloadHash)
+
+    signingKey := v4SigningKey(req.Key.SecretAccessKey, req.Date, region, fixedService)
+    req.signature = v4Signature(signingKey, v4StringToSign(req.Date, req.scope, canonicalReq))
+
+    req.Headers["Authorization"] =
+        fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+            v4Algorithm, req.Key.AccessKeyId, req.scope, signedHeaders, req.signature)
+    return signingKey
+}
+
+// Splits a CanonicalUri into its path and query.
+func splitCanonicalUri(uri string) (string, string) {
+    if i := strings.Index(uri, "?"); i >= 0 {
+        return uri[:i], uri[i + 1:]
+    }
+    return uri, ""
+}
+
+// Eg. 20150830/us-east-1/iam/aws4_request
+func v4Scope(date time.Time, region, service string) string {
+    return fmt.Sprintf("%s/%s/%s/aws4_request", simpleDate(date), region, service)
+}
+
+// The query parameters sorted by name, then value. The query is expected to already be escaped.
+func v4CanonicalQuery(query string) string {
+    if query == "" {
+        return ""
+    }
+    params := strings.Split(query, "&")
+    for i := range params {
+        params[i] = strings.Replace(params[i], "+", "%20", -1)
+        if !strings.Contains(params[i], "=") {
+            params[i] += "="
+        }
+    }
+    sort.Slice(params, func (a, b int) bool {
+        ka, va, _ := strings.Cut(params[a], "=")
+        kb, vb, _ := strings.Cut(params[b], "=")
+        if ka != kb {
+            return ka < kb
+        }
+        return va < vb
+    })
+    return strings.Join(params, "&")
+}
+
+// Lower cased header names, sorted, with their values trimmed and inner runs of spaces collapsed.
+// Returns the canonical headers, and the list of signed headers.
+func v4CanonicalHeaders(headers map[string]string) (string, string) {
+    names := make([]string, 0, len(headers))
+    values := make(map[string]string, len(headers))
+    for k, v := range headers {
+        name := strings.ToLower(k)
+        names = append(names, name)
+        values[name] = strings.Join(strings.Fields(v), " ")
+    }
+    sort.Strings(names)
+    canonical := strings.Builder{}
+    for _, name := range names {
+        canonical.WriteString(name)
+        canonical.WriteString(":")
+        canonical.WriteString(values[name])
+        canonical.WriteString("\n")
+    }
+    return canonical.String(), strings.Join(names, ";")
+}
+
+func v4CanonicalRequest(method, path, query, canonicalHeaders, signedHeaders, payloadHash string) string {
+    if path == "" {
+        path = "/"
+    }
+    return fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+        method, path, query, canonicalHeaders, signedHeaders, payloadHash)
+}
+
+func v4StringToSign(date time.Time, scope, canonicalRequest string) string {
+    return fmt.Sprintf("%s\n%s\n%s\n%x",
+        v4Algorithm, IsoDate(date), scope, sha256.Sum256([]byte(canonicalRequest)))
+}
+
+// http://docs.aws.amazon.com/general/latest/gr/sigv4-calculate-signature.html
+func v4SigningKey(secretAccessKey string, date time.Time, region, service string) []byte {
+    key := hmacSha256([]byte("AWS4" + secretAccessKey), simpleDate(date))
+    key = hmacSha256(key, region)
+    key = hmacSha256(key, service)
+    return hmacSha256(key, "aws4_request")
+}
+
+func v4Signature(signingKey []byte, stringToSign string) string {
+    return fmt.Sprintf("%x", hmacSha256(signingKey, stringToSign))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+    hmacHasher := hmac.New(createHMacHasher256, key)
+    hmacHasher.Write([]byte(data))
+    return hmacHasher.Sum(nil)
+}
@@ -31,6 +31,7 @@
 package sns
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "encoding/xml"
     "net/url"
@@ -70,12 +71,21 @@ type PublishError struct {
     Response        *PublishResponse
     Code            int
     RawResponse     string
+    RequestId       string
 }
 
 func (p PublishError) Error() string {
     return fmt.Sprintf("Response was %d", p.Code)
 }
 
+func (p PublishError) ErrorCode() string { return awsgo.ExtractErrorCode([]byte(p.RawResponse)) }
+func (p PublishError) ErrorMessage() string { return awsgo.ExtractErrorMessage([]byte(p.RawResponse)) }
+func (p PublishError) HttpStatusCode() int { return p.Code }
+func (p PublishError) RequestID() string { return p.RequestId }
+func (p PublishError) Retryable() bool { return awsgo.IsRetryableError(p.ErrorCode(), p.Code) }
+func (p PublishError) Throttling() bool { return awsgo.IsThrottlingError(p.ErrorCode(), p.Code) }
+func (p PublishError) Is(target error) bool { return awsgo.MatchesErrorCode(p, target) }
+
 
 func NewPublishRequest() *PublishRequest {
     req := new(PublishRequest)
@@ -129,6 +139,7 @@ func (req PublishRequest) DeMarshalResponse(response []byte, headers map[string]
             Response: giResponse,
             Code: statusCode,
             RawResponse: string(response),
+            RequestId: headers["x-amzn-requestid"],
         }
     }
     return giResponse
@@ -136,12 +147,17 @@ func (req PublishRequest) DeMarshalResponse(response []byte, headers map[string]
 
 
 func (gir PublishRequest) Request() (*PublishResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir PublishRequest) RequestWithContext(ctx context.Context) (*PublishResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS2
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
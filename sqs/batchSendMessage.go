@@ -31,6 +31,7 @@
 package sqs
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -128,12 +129,17 @@ func (gir SendBatchMessageRequest) DeMarshalResponse(response []byte, headers ma
 }
 
 func (gir SendBatchMessageRequest) Request() (*SendBatchMessageResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir SendBatchMessageRequest) RequestWithContext(ctx context.Context) (*SendBatchMessageResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -32,6 +32,7 @@ package sqs
 
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -106,13 +107,18 @@ func (gir ChangeMessageVisibilityRequest) DeMarshalResponse(response []byte, hea
     return giResponse
 }
 
-func (gir ChangeMessageVisibilityRequest) Request() (*ChangeMessageVisibilityResponse, error) {    
+func (gir ChangeMessageVisibilityRequest) Request() (*ChangeMessageVisibilityResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir ChangeMessageVisibilityRequest) RequestWithContext(ctx context.Context) (*ChangeMessageVisibilityResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
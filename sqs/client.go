@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sqs
+
+import (
+    "github.com/fromkeith/awsgo"
+)
+
+// Creates SQS requests that share a Config, so region, credentials and
+// the http client don't have to be set on every request.
+type Client struct {
+    Config      awsgo.Config
+}
+
+func NewClient(cfg awsgo.Config) *Client {
+    return &Client{Config: cfg}
+}
+
+func (c *Client) NewChangeMessageVisibilityRequest() *ChangeMessageVisibilityRequest {
+    req := NewChangeMessageVisibilityRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewDeleteMessageRequest() *DeleteMessageRequest {
+    req := NewDeleteMessageRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewReceiveMessageRequest() *ReceiveMessageRequest {
+    req := NewReceiveMessageRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewSendBatchMessageRequest() *SendBatchMessageRequest {
+    req := NewSendBatchMessageRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
+
+func (c *Client) NewSendMessageRequest() *SendMessageRequest {
+    req := NewSendMessageRequest()
+    c.Config.Apply(&req.RequestBuilder)
+    return req
+}
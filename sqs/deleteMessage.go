@@ -31,6 +31,7 @@
 package sqs
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -100,13 +101,18 @@ func (gir DeleteMessageRequest) DeMarshalResponse(response []byte, headers map[s
     return giResponse
 }
 
-func (gir DeleteMessageRequest) Request() (*DeleteMessageResponse, error) {    
+func (gir DeleteMessageRequest) Request() (*DeleteMessageResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir DeleteMessageRequest) RequestWithContext(ctx context.Context) (*DeleteMessageResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
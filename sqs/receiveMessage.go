@@ -31,6 +31,7 @@
 package sqs
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -154,12 +155,17 @@ func (gir ReceiveMessageRequest) DeMarshalResponse(response []byte, headers map[
 }
 
 func (gir ReceiveMessageRequest) Request() (*ReceiveMessageResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir ReceiveMessageRequest) RequestWithContext(ctx context.Context) (*ReceiveMessageResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -31,6 +31,7 @@
 package sqs
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "errors"
     "fmt"
@@ -94,7 +95,7 @@ func (gir SendMessageRequest) DeMarshalResponse(response []byte, headers map[str
         return err
     }
     if statusCode < 200 || statusCode >= 300 {
-        return errors.New(fmt.Sprintf("Bad Status code: %d", statusCode))
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     giResponse := new(SendMessageResponse)
     //fmt.Println(string(response))
@@ -106,12 +107,17 @@ func (gir SendMessageRequest) DeMarshalResponse(response []byte, headers map[str
 }
 
 func (gir SendMessageRequest) Request() (*SendMessageResponse, error) {
+    return gir.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (gir SendMessageRequest) RequestWithContext(ctx context.Context) (*SendMessageResponse, error) {
     request, err := awsgo.BuildEmptyContentRequest(&gir)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS4
-    resp, err := request.DoAndDemarshall(&gir)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &gir)
     if resp == nil {
         return nil, err
     }
@@ -28,4 +28,20 @@
  * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
  */
 
-package sqs
\ No newline at end of file
+package sqs
+
+import (
+    "github.com/fromkeith/awsgo"
+)
+
+func queueSpanAttributes(queue string) []awsgo.SpanAttribute {
+    return []awsgo.SpanAttribute{{Key: "aws.sqs.queue_url", Value: queue}}
+}
+
+// Lets an awsgo.Tracer know which queue a request is for.
+func (gir SendMessageRequest) SpanAttributes() []awsgo.SpanAttribute { return queueSpanAttributes(gir.TaskQueue) }
+func (gir SendBatchMessageRequest) SpanAttributes() []awsgo.SpanAttribute { return queueSpanAttributes(gir.QueueUrl) }
+func (gir ReceiveMessageRequest) SpanAttributes() []awsgo.SpanAttribute { return queueSpanAttributes(gir.TaskQueue) }
+func (gir DeleteMessageRequest) SpanAttributes() []awsgo.SpanAttribute { return queueSpanAttributes(gir.TaskQueue) }
+func (gir ChangeMessageVisibilityRequest) SpanAttributes() []awsgo.SpanAttribute { return queueSpanAttributes(gir.TaskQueue) }
+
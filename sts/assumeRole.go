@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "context"
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "net/url"
+)
+
+type AssumeRoleRequest struct {
+    awsgo.RequestBuilder
+
+    RoleArn                 string
+    RoleSessionName         string
+    // How long the credentials last, in seconds. 0 uses the STS default of an hour.
+    DurationSeconds         int
+    ExternalId              string
+    // An optional IAM policy, in json, to further restrict the session
+    Policy                  string
+    // For roles that require MFA
+    SerialNumber            string
+    TokenCode               string
+}
+
+type AssumeRoleResult struct {
+    Credentials             Credentials
+    AssumedRoleUser         AssumedRoleUser
+    PackedPolicySize        int
+}
+
+type AssumeRoleResponse struct {
+    AssumeRoleResult        AssumeRoleResult
+    ResponseMetadata        awsgo.ResponseMetaData
+}
+
+// Creates a new AssumeRoleRequest, populating in some defaults
+func NewAssumeRoleRequest() *AssumeRoleRequest {
+    req := new(AssumeRoleRequest)
+    setupStsRequest(&req.RequestBuilder)
+    return req
+}
+
+func (req * AssumeRoleRequest) VerifyInput() (error) {
+    if len(req.RoleArn) == 0 {
+        return Verification_Error_RoleArnEmpty
+    }
+    if len(req.RoleSessionName) == 0 {
+        return Verification_Error_RoleSessionNameEmpty
+    }
+    val := make(url.Values)
+    val.Set("RoleArn", req.RoleArn)
+    val.Set("RoleSessionName", req.RoleSessionName)
+    if req.DurationSeconds > 0 {
+        val.Set("DurationSeconds", fmt.Sprintf("%d", req.DurationSeconds))
+    }
+    if req.ExternalId != "" {
+        val.Set("ExternalId", req.ExternalId)
+    }
+    if req.Policy != "" {
+        val.Set("Policy", req.Policy)
+    }
+    if req.SerialNumber != "" {
+        val.Set("SerialNumber", req.SerialNumber)
+        val.Set("TokenCode", req.TokenCode)
+    }
+    setQueryUri(&req.RequestBuilder, "AssumeRole", val)
+    return nil
+}
+
+func (req AssumeRoleRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) (interface{}) {
+    resp := new(AssumeRoleResponse)
+    if err := unmarshalStsResponse(response, statusCode, resp); err != nil {
+        return err
+    }
+    return resp
+}
+
+func (req AssumeRoleRequest) Request() (*AssumeRoleResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req AssumeRoleRequest) RequestWithContext(ctx context.Context) (*AssumeRoleResponse, error) {
+    request, err := awsgo.BuildEmptyContentRequest(&req)
+    if err != nil {
+        return nil, err
+    }
+    request.RequestSigningType = awsgo.RequestSigningType_AWS4
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
+    if resp == nil {
+        return nil, err
+    }
+    return resp.(*AssumeRoleResponse), err
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "io"
+    "net/http"
+    "net/url"
+)
+
+// AssumeRoleWithWebIdentity is not signed; the web identity token is the credential.
+// So unlike the other calls this doesn't go through awsgo.AwsRequest.
+type AssumeRoleWithWebIdentityRequest struct {
+    // Defaults to us-east-1
+    Region                  string
+    // Defaults to awsgo.DefaultHttpClient()
+    HttpClient              *http.Client
+
+    RoleArn                 string
+    RoleSessionName         string
+    WebIdentityToken        string
+    // Only needed for OAuth 2.0 access tokens
+    ProviderId              string
+    DurationSeconds         int
+    Policy                  string
+}
+
+type AssumeRoleWithWebIdentityResult struct {
+    Credentials                 Credentials
+    AssumedRoleUser             AssumedRoleUser
+    PackedPolicySize            int
+    Provider                    string
+    Audience                    string
+    SubjectFromWebIdentityToken string
+}
+
+type AssumeRoleWithWebIdentityResponse struct {
+    AssumeRoleWithWebIdentityResult AssumeRoleWithWebIdentityResult
+    ResponseMetadata        awsgo.ResponseMetaData
+}
+
+// Creates a new AssumeRoleWithWebIdentityRequest, populating in some defaults
+func NewAssumeRoleWithWebIdentityRequest() *AssumeRoleWithWebIdentityRequest {
+    req := new(AssumeRoleWithWebIdentityRequest)
+    req.Region = "us-east-1"
+    return req
+}
+
+func (req AssumeRoleWithWebIdentityRequest) VerifyInput() (error) {
+    if len(req.RoleArn) == 0 {
+        return Verification_Error_RoleArnEmpty
+    }
+    if len(req.RoleSessionName) == 0 {
+        return Verification_Error_RoleSessionNameEmpty
+    }
+    if len(req.WebIdentityToken) == 0 {
+        return Verification_Error_WebIdentityTokenEmpty
+    }
+    return nil
+}
+
+func (req AssumeRoleWithWebIdentityRequest) DeMarshalResponse(response []byte, headers http.Header, statusCode int) (*AssumeRoleWithWebIdentityResponse, error) {
+    resp := new(AssumeRoleWithWebIdentityResponse)
+    if err := unmarshalStsResponse(response, statusCode, resp); err != nil {
+        return nil, err
+    }
+    return resp, nil
+}
+
+func (req AssumeRoleWithWebIdentityRequest) Request() (*AssumeRoleWithWebIdentityResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req AssumeRoleWithWebIdentityRequest) RequestWithContext(ctx context.Context) (*AssumeRoleWithWebIdentityResponse, error) {
+    if err := req.VerifyInput(); err != nil {
+        return nil, err
+    }
+    region := req.Region
+    if region == "" {
+        region = "us-east-1"
+    }
+    val := make(url.Values)
+    val.Set("RoleArn", req.RoleArn)
+    val.Set("RoleSessionName", req.RoleSessionName)
+    val.Set("WebIdentityToken", req.WebIdentityToken)
+    if req.ProviderId != "" {
+        val.Set("ProviderId", req.ProviderId)
+    }
+    if req.DurationSeconds > 0 {
+        val.Set("DurationSeconds", fmt.Sprintf("%d", req.DurationSeconds))
+    }
+    if req.Policy != "" {
+        val.Set("Policy", req.Policy)
+    }
+    val.Set("Action", "AssumeRoleWithWebIdentity")
+    val.Set("Version", StsApiVersion)
+    endpoint, err := awsgo.ResolveEndpoint(awsgo.AwsHost{Service: "sts", Region: region, Domain: "amazonaws.com"})
+    if err != nil {
+        return nil, err
+    }
+    u, err := url.Parse(endpoint.URL + "/?" + val.Encode())
+    if err != nil {
+        return nil, err
+    }
+    hreq := http.Request{
+        URL: u,
+        Method: "GET",
+        Header: http.Header{},
+    }
+    client := req.HttpClient
+    if client == nil {
+        client = awsgo.DefaultHttpClient()
+    }
+    resp, err := client.Do(hreq.WithContext(ctx))
+    if err != nil {
+        if ctx.Err() != nil {
+            return nil, ctx.Err()
+        }
+        return nil, err
+    }
+    defer resp.Body.Close()
+    buf := bytes.Buffer{}
+    if _, err = io.Copy(&buf, resp.Body); err != nil {
+        if ctx.Err() != nil {
+            return nil, ctx.Err()
+        }
+        return nil, err
+    }
+    return req.DeMarshalResponse(buf.Bytes(), resp.Header, resp.StatusCode)
+}
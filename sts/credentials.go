@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "context"
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Assumes RoleArn using the credentials from Source.
+// Source can itself be an AssumeRoleProvider, which lets roles be chained.
+// Use NewAssumeRoleProvider to get one that caches and refreshes the credentials.
+type AssumeRoleProvider struct {
+    // The credentials used to call AssumeRole. nil uses the default provider.
+    Source          awsgo.CredentialsProvider
+    RoleArn         string
+    // Defaults to 'awsgo-<timestamp>'
+    RoleSessionName string
+    ExternalId      string
+    // How long the assumed credentials last. 0 uses the STS default of an hour.
+    Duration        time.Duration
+    Policy          string
+    // Region of the sts endpoint to use. Defaults to us-east-1
+    Region          string
+    HttpClient      *http.Client
+}
+
+// Creates a cached AssumeRoleProvider, which refreshes the credentials before they expire.
+func NewAssumeRoleProvider(source awsgo.CredentialsProvider, roleArn string) *awsgo.CredentialsCache {
+    return awsgo.NewCredentialsCache(&AssumeRoleProvider{
+        Source: source,
+        RoleArn: roleArn,
+    })
+}
+
+func (p AssumeRoleProvider) Retrieve() (awsgo.Credentials, error) {
+    req := NewAssumeRoleRequest()
+    req.CredentialsProvider = p.Source
+    req.HttpClient = p.HttpClient
+    if p.Region != "" {
+        req.Host.Region = p.Region
+    }
+    req.RoleArn = p.RoleArn
+    req.RoleSessionName = sessionName(p.RoleSessionName)
+    req.ExternalId = p.ExternalId
+    req.DurationSeconds = int(p.Duration / time.Second)
+    req.Policy = p.Policy
+    resp, err := req.RequestWithContext(context.Background())
+    if err != nil {
+        return awsgo.Credentials{}, err
+    }
+    return resp.AssumeRoleResult.Credentials.AwsCredentials(), nil
+}
+
+// Assumes RoleArn with the web identity token read from TokenFile.
+// The file is re-read on every refresh, as the token is typically rotated by whatever writes it.
+type WebIdentityProvider struct {
+    // Defaults to AWS_WEB_IDENTITY_TOKEN_FILE
+    TokenFile       string
+    // Defaults to AWS_ROLE_ARN
+    RoleArn         string
+    // Defaults to AWS_ROLE_SESSION_NAME, or 'awsgo-<timestamp>'
+    RoleSessionName string
+    Duration        time.Duration
+    Region          string
+    HttpClient      *http.Client
+}
+
+func (p WebIdentityProvider) Retrieve() (awsgo.Credentials, error) {
+    tokenFile := firstNonEmpty(p.TokenFile, os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+    roleArn := firstNonEmpty(p.RoleArn, os.Getenv("AWS_ROLE_ARN"))
+    if tokenFile == "" || roleArn == "" {
+        return awsgo.Credentials{}, awsgo.Credentials_Error_EnvNotSet
+    }
+    token, err := ioutil.ReadFile(tokenFile)
+    if err != nil {
+        return awsgo.Credentials{}, err
+    }
+    req := NewAssumeRoleWithWebIdentityRequest()
+    if p.Region != "" {
+        req.Region = p.Region
+    }
+    req.HttpClient = p.HttpClient
+    req.RoleArn = roleArn
+    req.RoleSessionName = sessionName(firstNonEmpty(p.RoleSessionName, os.Getenv("AWS_ROLE_SESSION_NAME")))
+    req.WebIdentityToken = strings.TrimSpace(string(token))
+    req.DurationSeconds = int(p.Duration / time.Second)
+    resp, err := req.Request()
+    if err != nil {
+        return awsgo.Credentials{}, err
+    }
+    return resp.AssumeRoleWithWebIdentityResult.Credentials.AwsCredentials(), nil
+}
+
+// Builds a provider for a profile in the shared config file (~/.aws/config), the same way the AWS cli does.
+// A profile with role_arn assumes that role, using the credentials of source_profile
+// (which may itself assume a role) or of credential_source.
+// A profile without role_arn uses its static keys from the shared credentials file.
+// The returned provider is cached.
+func NewSharedConfigCredentialsProvider(profile string) (awsgo.CredentialsProvider, error) {
+    p, err := sharedConfigProvider("", profile, map[string]bool{})
+    if err != nil {
+        return nil, err
+    }
+    return awsgo.NewCredentialsCache(p), nil
+}
+
+func sharedConfigProvider(filename, profile string, visited map[string]bool) (awsgo.CredentialsProvider, error) {
+    if visited[profile] {
+        return nil, fmt.Errorf("Profile '%s' has a source_profile loop", profile)
+    }
+    visited[profile] = true
+    section, err := awsgo.LoadSharedConfigProfile(filename, profile)
+    if err != nil {
+        // a profile may exist only in the credentials file
+        return awsgo.SharedCredentialsProvider{Profile: profile}, nil
+    }
+    roleArn := section["role_arn"]
+    if roleArn == "" {
+        return awsgo.SharedCredentialsProvider{Profile: profile}, nil
+    }
+    if section["web_identity_token_file"] != "" {
+        return WebIdentityProvider{
+            TokenFile: section["web_identity_token_file"],
+            RoleArn: roleArn,
+            RoleSessionName: section["role_session_name"],
+            Region: section["region"],
+        }, nil
+    }
+    var source awsgo.CredentialsProvider
+    if src := section["source_profile"]; src != "" {
+        if src == profile {
+            // a profile can hold both the keys, and the role they assume
+            source = awsgo.SharedCredentialsProvider{Profile: profile}
+        } else if source, err = sharedConfigProvider(filename, src, visited); err != nil {
+            return nil, err
+        }
+    } else if cs := section["credential_source"]; cs != "" {
+        if source, err = credentialSource(cs); err != nil {
+            return nil, err
+        }
+    } else {
+        return nil, fmt.Errorf("Profile '%s' has role_arn, but neither source_profile nor credential_source", profile)
+    }
+    p := &AssumeRoleProvider{
+        Source: source,
+        RoleArn: roleArn,
+        RoleSessionName: section["role_session_name"],
+        ExternalId: section["external_id"],
+        Region: section["region"],
+    }
+    if d := section["duration_seconds"]; d != "" {
+        secs, err := strconv.Atoi(d)
+        if err != nil {
+            return nil, fmt.Errorf("Profile '%s' has an invalid duration_seconds: %s", profile, d)
+        }
+        p.Duration = time.Duration(secs) * time.Second
+    }
+    return p, nil
+}
+
+func credentialSource(name string) (awsgo.CredentialsProvider, error) {
+    switch name {
+    case "Environment":
+        return awsgo.EnvCredentialsProvider{}, nil
+    case "Ec2InstanceMetadata":
+        return awsgo.InstanceMetadataCredentialsProvider{}, nil
+    case "EcsContainer":
+        return awsgo.ContainerCredentialsProvider{}, nil
+    }
+    return nil, fmt.Errorf("Unsupported credential_source: %s", name)
+}
+
+func sessionName(name string) string {
+    if name != "" {
+        return name
+    }
+    return fmt.Sprintf("awsgo-%d", time.Now().UnixNano())
+}
+
+func firstNonEmpty(a, b string) string {
+    if a != "" {
+        return a
+    }
+    return b
+}
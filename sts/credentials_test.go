@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "crypto/x509"
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const assumeRoleResponseXml = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/demo/session</Arn>
+      <AssumedRoleId>ARO123EXAMPLE123:session</AssumedRoleId>
+    </AssumedRoleUser>
+    <Credentials>
+      <AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <PackedPolicySize>6</PackedPolicySize>
+  </AssumeRoleResult>
+  <ResponseMetadata>
+    <RequestId>c6104cbe-af31-11e0-8154-cbc7ccf896c7</RequestId>
+  </ResponseMetadata>
+</AssumeRoleResponse>`
+
+func Test_AssumeRole(t *testing.T) {
+    handler := http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        if q.Get("Action") != "AssumeRole" || q.Get("RoleArn") != "arn:aws:iam::123456789012:role/demo" {
+            http.Error(w, "bad query: " + r.URL.RawQuery, 400)
+            return
+        }
+        fmt.Fprint(w, assumeRoleResponseXml)
+    })
+    ts := httptest.NewTLSServer(handler)
+    defer ts.Close()
+    certAsx509, _ := x509.ParseCertificate(ts.TLS.Certificates[0].Certificate[0])
+
+    req := NewAssumeRoleRequest()
+    req.Host.Override = strings.TrimPrefix(ts.URL, "https://")
+    req.HttpClient = awsgo.CreateCertApprovedClient([]*x509.Certificate{certAsx509})
+    req.Key = awsgo.Credentials{AccessKeyId: "akey", SecretAccessKey: "skey"}
+    req.RoleArn = "arn:aws:iam::123456789012:role/demo"
+    req.RoleSessionName = "session"
+    resp, err := req.Request()
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    creds := resp.AssumeRoleResult.Credentials.AwsCredentials()
+    if creds.AccessKeyId != "ASIAEXAMPLE" || creds.GetToken() != "token" {
+        t.Fatalf("Unexpected credentials: %v", creds)
+    }
+    if creds.GetExpiration().Year() != 2030 {
+        t.Fatalf("Expected expiration in 2030. Got %v", creds.GetExpiration())
+    }
+    if resp.AssumeRoleResult.AssumedRoleUser.AssumedRoleId != "ARO123EXAMPLE123:session" {
+        t.Fatalf("Unexpected role user: %v", resp.AssumeRoleResult.AssumedRoleUser)
+    }
+}
+
+func Test_AssumeRoleVerifyInput(t *testing.T) {
+    req := NewAssumeRoleRequest()
+    req.RoleSessionName = "session"
+    if err := req.VerifyInput(); err != Verification_Error_RoleArnEmpty {
+        t.Fatalf("Expected RoleArnEmpty. Got: %v", err)
+    }
+}
+
+func writeConfig(t *testing.T, content string) {
+    filename := filepath.Join(t.TempDir(), "config")
+    if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("AWS_CONFIG_FILE", filename)
+}
+
+func Test_SharedConfigChainsRoles(t *testing.T) {
+    writeConfig(t, `
+[profile base]
+region = us-west-2
+
+[profile middle]
+role_arn = arn:aws:iam::123456789012:role/middle
+source_profile = base
+
+[profile top]
+role_arn = arn:aws:iam::123456789012:role/top
+source_profile = middle
+external_id = abc
+duration_seconds = 900
+`)
+    p, err := sharedConfigProvider("", "top", map[string]bool{})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    top, ok := p.(*AssumeRoleProvider)
+    if !ok || top.RoleArn != "arn:aws:iam::123456789012:role/top" || top.ExternalId != "abc" || top.Duration.Seconds() != 900 {
+        t.Fatalf("Unexpected top provider: %#v", p)
+    }
+    middle, ok := top.Source.(*AssumeRoleProvider)
+    if !ok || middle.RoleArn != "arn:aws:iam::123456789012:role/middle" {
+        t.Fatalf("Unexpected middle provider: %#v", top.Source)
+    }
+    if base, ok := middle.Source.(awsgo.SharedCredentialsProvider); !ok || base.Profile != "base" {
+        t.Fatalf("Unexpected base provider: %#v", middle.Source)
+    }
+}
+
+func Test_SharedConfigDetectsLoops(t *testing.T) {
+    writeConfig(t, `
+[profile a]
+role_arn = arn:aws:iam::123456789012:role/a
+source_profile = b
+
+[profile b]
+role_arn = arn:aws:iam::123456789012:role/b
+source_profile = a
+`)
+    if _, err := sharedConfigProvider("", "a", map[string]bool{}); err == nil {
+        t.Fatalf("Expected a loop error")
+    }
+}
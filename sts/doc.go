@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+/*
+Package sts talks to the AWS Security Token Service.
+
+AssumeRole
+
+    req := sts.NewAssumeRoleRequest()
+    req.RoleArn = "arn:aws:iam::123456789012:role/demo"
+    req.RoleSessionName = "my-session"
+    resp, err := req.Request()
+    // test err ...
+    creds := resp.AssumeRoleResult.Credentials.AwsCredentials()
+
+Assuming a role for every request
+
+NewAssumeRoleProvider returns a provider that assumes the role, and refreshes the credentials
+before they expire. Roles can be chained by using one AssumeRoleProvider as the source of another.
+
+    provider := sts.NewAssumeRoleProvider(nil, "arn:aws:iam::123456789012:role/demo")
+    awsgo.SetDefaultCredentialsProvider(provider)
+
+Shared config
+
+NewSharedConfigCredentialsProvider reads a profile from ~/.aws/config, following
+role_arn, source_profile, credential_source, external_id, role_session_name,
+duration_seconds and web_identity_token_file as the AWS cli does.
+
+    // [profile deploy]
+    // role_arn = arn:aws:iam::123456789012:role/deploy
+    // source_profile = default
+    provider, err := sts.NewSharedConfigCredentialsProvider("deploy")
+*/
+package sts
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "context"
+    "github.com/fromkeith/awsgo"
+    "net/url"
+)
+
+type GetCallerIdentityRequest struct {
+    awsgo.RequestBuilder
+}
+
+type GetCallerIdentityResult struct {
+    Account                 string
+    Arn                     string
+    UserId                  string
+}
+
+type GetCallerIdentityResponse struct {
+    GetCallerIdentityResult GetCallerIdentityResult
+    ResponseMetadata        awsgo.ResponseMetaData
+}
+
+// Creates a new GetCallerIdentityRequest, populating in some defaults
+func NewGetCallerIdentityRequest() *GetCallerIdentityRequest {
+    req := new(GetCallerIdentityRequest)
+    setupStsRequest(&req.RequestBuilder)
+    return req
+}
+
+func (req * GetCallerIdentityRequest) VerifyInput() (error) {
+    setQueryUri(&req.RequestBuilder, "GetCallerIdentity", make(url.Values))
+    return nil
+}
+
+func (req GetCallerIdentityRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) (interface{}) {
+    resp := new(GetCallerIdentityResponse)
+    if err := unmarshalStsResponse(response, statusCode, resp); err != nil {
+        return err
+    }
+    return resp
+}
+
+func (req GetCallerIdentityRequest) Request() (*GetCallerIdentityResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req GetCallerIdentityRequest) RequestWithContext(ctx context.Context) (*GetCallerIdentityResponse, error) {
+    request, err := awsgo.BuildEmptyContentRequest(&req)
+    if err != nil {
+        return nil, err
+    }
+    request.RequestSigningType = awsgo.RequestSigningType_AWS4
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
+    if resp == nil {
+        return nil, err
+    }
+    return resp.(*GetCallerIdentityResponse), err
+}
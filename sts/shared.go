@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package sts
+
+import (
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "github.com/fromkeith/awsgo"
+    "net/url"
+    "time"
+)
+
+const (
+    StsApiVersion = "2011-06-15"
+)
+
+var (
+    Verification_Error_RoleArnEmpty = errors.New("RoleArn cannot be empty")
+    Verification_Error_RoleSessionNameEmpty = errors.New("RoleSessionName cannot be empty")
+    Verification_Error_WebIdentityTokenEmpty = errors.New("WebIdentityToken cannot be empty")
+)
+
+// Temporary credentials handed out by STS
+type Credentials struct {
+    AccessKeyId             string
+    SecretAccessKey         string
+    SessionToken            string
+    Expiration              time.Time
+}
+
+// Converts to credentials usable on any awsgo request
+func (c Credentials) AwsCredentials() awsgo.Credentials {
+    return awsgo.NewCredentials(c.AccessKeyId, c.SecretAccessKey, c.SessionToken, c.Expiration)
+}
+
+type AssumedRoleUser struct {
+    Arn                     string
+    AssumedRoleId           string
+}
+
+// Returned when STS responds with a status code we didn't expect, but no xml error.
+type BadStatusCodeError struct {
+    StatusCode              int
+    Content                 string
+}
+
+func (b BadStatusCodeError) Error() string {
+    return fmt.Sprintf("Code: %d", b.StatusCode)
+}
+
+func (b BadStatusCodeError) ErrorCode() string { return awsgo.ExtractErrorCode([]byte(b.Content)) }
+func (b BadStatusCodeError) ErrorMessage() string { return awsgo.ExtractErrorMessage([]byte(b.Content)) }
+func (b BadStatusCodeError) HttpStatusCode() int { return b.StatusCode }
+func (b BadStatusCodeError) RequestID() string { return "" }
+func (b BadStatusCodeError) Retryable() bool { return awsgo.IsRetryableError(b.ErrorCode(), b.StatusCode) }
+func (b BadStatusCodeError) Throttling() bool { return awsgo.IsThrottlingError(b.ErrorCode(), b.StatusCode) }
+func (b BadStatusCodeError) Is(target error) bool { return awsgo.MatchesErrorCode(b, target) }
+
+func setupStsRequest(rb *awsgo.RequestBuilder) {
+    rb.Host.Service = "sts"
+    // sts is global, but requests must be signed for a region.
+    rb.Host.Region = "us-east-1"
+    rb.Host.Domain = "amazonaws.com"
+    rb.Headers = make(map[string]string)
+    rb.RequestMethod = "GET"
+    rb.CanonicalUri = "/"
+}
+
+func setQueryUri(rb *awsgo.RequestBuilder, action string, val url.Values) {
+    val.Set("Action", action)
+    val.Set("Version", StsApiVersion)
+    rb.CanonicalUri = "/?" + val.Encode()
+}
+
+func unmarshalStsResponse(response []byte, statusCode int, out interface{}) error {
+    if err := awsgo.CheckForErrorXml(response); err != nil {
+        // AssumeRoleWithWebIdentity doesn't go through the DeMarshal handler, so fill this in here.
+        err.(*awsgo.ErrorResponse).SetResponseInfo(statusCode, "")
+        return err
+    }
+    if statusCode < 200 || statusCode >= 300 {
+        return BadStatusCodeError{
+            StatusCode: statusCode,
+            Content: string(response),
+        }
+    }
+    if err := xml.Unmarshal(response, out); err != nil {
+        return &awsgo.UnmarhsallingError{
+            ActualContent: string(response),
+            MarshallError: err,
+        }
+    }
+    return nil
+}
@@ -31,9 +31,9 @@
 package swf
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/fromkeith/awsgo"
-	"log"
 )
 
 type PollForActivityTaskRequest struct {
@@ -72,8 +72,8 @@ func (req *PollForActivityTaskRequest) VerifyInput() error {
 }
 
 func (req PollForActivityTaskRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
-	if statusCode != 200 {
-		log.Println("response: ", string(response))
+	if statusCode < 200 || statusCode >= 300 {
+		return awsgo.NewServiceError(response, headers, statusCode)
 	}
 	resp := new(PollForActivityTaskResponse)
 	if err := json.Unmarshal(response, resp); err != nil {
@@ -83,12 +83,17 @@ func (req PollForActivityTaskRequest) DeMarshalResponse(response []byte, headers
 }
 
 func (req PollForActivityTaskRequest) Request() (*PollForActivityTaskResponse, error) {
+	return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req PollForActivityTaskRequest) RequestWithContext(ctx context.Context) (*PollForActivityTaskResponse, error) {
 	request, err := awsgo.NewAwsRequest(&req, req)
 	if err != nil {
 		return nil, err
 	}
 	request.RequestSigningType = awsgo.RequestSigningType_AWS3
-	resp, err := request.DoAndDemarshall(&req)
+	resp, err := request.DoAndDemarshallWithContext(ctx, &req)
 	if resp == nil {
 		return nil, err
 	}
@@ -31,9 +31,9 @@
 package swf
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/fromkeith/awsgo"
-	"log"
 )
 
 type TaskList struct {
@@ -425,8 +425,8 @@ func (req *PollForDecisionTaskRequest) VerifyInput() error {
 }
 
 func (req PollForDecisionTaskRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
-	if statusCode != 200 {
-		log.Println("response: ", string(response))
+	if statusCode < 200 || statusCode >= 300 {
+		return awsgo.NewServiceError(response, headers, statusCode)
 	}
 	resp := new(PollForDecisionTaskResponse)
 	if err := json.Unmarshal(response, resp); err != nil {
@@ -436,12 +436,17 @@ func (req PollForDecisionTaskRequest) DeMarshalResponse(response []byte, headers
 }
 
 func (req PollForDecisionTaskRequest) Request() (*PollForDecisionTaskResponse, error) {
+	return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req PollForDecisionTaskRequest) RequestWithContext(ctx context.Context) (*PollForDecisionTaskResponse, error) {
 	request, err := awsgo.NewAwsRequest(&req, req)
 	if err != nil {
 		return nil, err
 	}
 	request.RequestSigningType = awsgo.RequestSigningType_AWS3
-	resp, err := request.DoAndDemarshall(&req)
+	resp, err := request.DoAndDemarshallWithContext(ctx, &req)
 	if resp == nil {
 		return nil, err
 	}
@@ -31,9 +31,9 @@
 package swf
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "log"
-    "errors"
     "encoding/json"
 )
 
@@ -71,7 +71,7 @@ func (req *RespondActivityTaskHeartbeatRequest) VerifyInput() error {
 func (req RespondActivityTaskHeartbeatRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
     if statusCode != 200 {
         log.Println("RespondActivityTaskHeartbeatRequest.Response: ", string(response))
-        return errors.New("Bad response code!")
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(RespondActivityTaskHeartbeatResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -81,12 +81,17 @@ func (req RespondActivityTaskHeartbeatRequest) DeMarshalResponse(response []byte
 }
 
 func (req RespondActivityTaskHeartbeatRequest) Request() (*RespondActivityTaskHeartbeatResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req RespondActivityTaskHeartbeatRequest) RequestWithContext(ctx context.Context) (*RespondActivityTaskHeartbeatResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS3
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
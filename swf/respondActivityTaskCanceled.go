@@ -31,9 +31,9 @@
 package swf
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "log"
-    "errors"
 )
 
 
@@ -70,18 +70,23 @@ func (req *RespondActivityTaskCanceledRequest) VerifyInput() error {
 func (req RespondActivityTaskCanceledRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
     log.Println("response: ", string(response))
     if statusCode != 200 {
-        return errors.New("Bad response code!")
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     return new(RespondActivityTaskCanceledResponse)
 }
 
 func (req RespondActivityTaskCanceledRequest) Request() (*RespondActivityTaskCanceledResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req RespondActivityTaskCanceledRequest) RequestWithContext(ctx context.Context) (*RespondActivityTaskCanceledResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS3
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -31,9 +31,9 @@
 package swf
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "log"
-    "errors"
 )
 
 
@@ -70,18 +70,23 @@ func (req *RespondActivityTaskCompletedRequest) VerifyInput() error {
 func (req RespondActivityTaskCompletedRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
     if statusCode != 200 {
         log.Println("RespondActivityTaskCompletedRequest.Response: ", string(response))
-        return errors.New("Bad response code!")
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     return new(RespondActivityTaskCompletedResponse)
 }
 
 func (req RespondActivityTaskCompletedRequest) Request() (*RespondActivityTaskCompletedResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req RespondActivityTaskCompletedRequest) RequestWithContext(ctx context.Context) (*RespondActivityTaskCompletedResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS3
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -31,9 +31,9 @@
 package swf
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "log"
-    "errors"
 )
 
 
@@ -71,18 +71,23 @@ func (req *RespondActivityTaskFailedRequest) VerifyInput() error {
 func (req RespondActivityTaskFailedRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
     log.Println("response: ", string(response))
     if statusCode != 200 {
-        return errors.New("Bad response code!")
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     return new(RespondActivityTaskFailedResponse)
 }
 
 func (req RespondActivityTaskFailedRequest) Request() (*RespondActivityTaskFailedResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req RespondActivityTaskFailedRequest) RequestWithContext(ctx context.Context) (*RespondActivityTaskFailedResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS3
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -31,9 +31,9 @@
 package swf
 
 import (
+	"context"
 	"github.com/fromkeith/awsgo"
 	"log"
-	"errors"
 )
 
 type CancelTimerDecisionAttributes struct {
@@ -156,18 +156,23 @@ func (req *RespondDecisionTaskCompletedRequest) VerifyInput() error {
 func (req RespondDecisionTaskCompletedRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
 	if statusCode != 200 {
 		log.Println("response: ", string(response))
-		return errors.New("Bad response code!")
+		return awsgo.NewServiceError(response, headers, statusCode)
 	}
 	return new(RespondDecisionTaskCompletedResponse)
 }
 
 func (req RespondDecisionTaskCompletedRequest) Request() (*RespondDecisionTaskCompletedResponse, error) {
+	return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req RespondDecisionTaskCompletedRequest) RequestWithContext(ctx context.Context) (*RespondDecisionTaskCompletedResponse, error) {
 	request, err := awsgo.NewAwsRequest(&req, req)
 	if err != nil {
 		return nil, err
 	}
 	request.RequestSigningType = awsgo.RequestSigningType_AWS3
-	resp, err := request.DoAndDemarshall(&req)
+	resp, err := request.DoAndDemarshallWithContext(ctx, &req)
 	if resp == nil {
 		return nil, err
 	}
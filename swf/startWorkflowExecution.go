@@ -31,9 +31,9 @@
 package swf
 
 import (
+    "context"
     "github.com/fromkeith/awsgo"
     "log"
-    "errors"
     "encoding/json"
 )
 
@@ -78,7 +78,7 @@ func (req *StartWorkflowExecutionRequest) VerifyInput() error {
 func (req StartWorkflowExecutionRequest) DeMarshalResponse(response []byte, headers map[string]string, statusCode int) interface{} {
     if statusCode != 200 {
         log.Println("StartWorkflowExecutionRequest.Response: ", string(response))
-        return errors.New("Bad response code!")
+        return awsgo.NewServiceError(response, headers, statusCode)
     }
     resp := new(StartWorkflowExecutionResponse)
     if err := json.Unmarshal(response, resp); err != nil {
@@ -88,12 +88,17 @@ func (req StartWorkflowExecutionRequest) DeMarshalResponse(response []byte, head
 }
 
 func (req StartWorkflowExecutionRequest) Request() (*StartWorkflowExecutionResponse, error) {
+    return req.RequestWithContext(context.Background())
+}
+
+// Same as Request, but the call is aborted once ctx is done.
+func (req StartWorkflowExecutionRequest) RequestWithContext(ctx context.Context) (*StartWorkflowExecutionResponse, error) {
     request, err := awsgo.NewAwsRequest(&req, req)
     if err != nil {
         return nil, err
     }
     request.RequestSigningType = awsgo.RequestSigningType_AWS3
-    resp, err := request.DoAndDemarshall(&req)
+    resp, err := request.DoAndDemarshallWithContext(ctx, &req)
     if resp == nil {
         return nil, err
     }
@@ -34,6 +34,7 @@ package swfhelper
 
 import (
     "code.google.com/p/go-uuid/uuid"
+    "context"
     "errors"
     "fmt"
     "github.com/fromkeith/awsgo"
@@ -103,8 +104,14 @@ func (a *ActivityWorker) SetActivityHeartbeatInterval(actType swf.ActivityType,
 
 // starts polling for activitiies, indefinitely.
 func (a *ActivityWorker) Start() error {
+    return a.StartWithContext(context.Background())
+}
+
+// starts polling for activities until ctx is done, returning ctx.Err().
+// Activities already handed to a handler are left to finish on their own.
+func (a *ActivityWorker) StartWithContext(ctx context.Context) error {
     if a.Identity == "" {
-        ec2Identity, err := ec2.InstanceId()
+        ec2Identity, err := ec2.MakeSimpleRequestWithContext(ctx, "/meta-data/instance-id")
         if err != nil {
             return err
         }
@@ -120,11 +127,12 @@ func (a *ActivityWorker) Start() error {
             a.workerPool <- true
         }
     }
-    for {
-        a.startActivityGetting()
+    for ctx.Err() == nil {
+        a.startActivityGetting(ctx)
     }
+    return ctx.Err()
 }
-func (a *ActivityWorker) startActivityGetting() {
+func (a *ActivityWorker) startActivityGetting(ctx context.Context) {
     defer func() {
         rec := recover()
         if rec != nil {
@@ -132,10 +140,14 @@ func (a *ActivityWorker) startActivityGetting() {
             debug.PrintStack()
         }
     }()
-    for {
+    for ctx.Err() == nil {
         if a.MaxWorkers > 0 {
             // wait for 1
-            <- a.workerPool
+            select {
+            case <- a.workerPool:
+            case <- ctx.Done():
+                return
+            }
         }
         poll := swf.NewPollForActivityTaskRequest()
         poll.Domain = a.Domain
@@ -143,13 +155,15 @@ func (a *ActivityWorker) startActivityGetting() {
         poll.TaskList = swf.TaskList{Name: a.TaskList}
 
         poll.Host.Region = a.Region
-        poll.Key, _ = awsgo.GetSecurityKeys()
 
-        resp, err := poll.Request()
+        resp, err := poll.RequestWithContext(ctx)
         if err != nil {
-            log.Println("Error making poll for activity task request.", err)
-            time.Sleep(1 * time.Second)
             a.workerPool <- true
+            if ctx.Err() != nil {
+                return
+            }
+            log.Println("Error making poll for activity task request.", err)
+            awsgo.SleepWithContext(ctx, 1 * time.Second)
             continue
         }
         // no activity to work on
@@ -227,7 +241,6 @@ forloop:
     heart.TaskToken = a.pollTask.TaskToken
     heart.Details = details
     heart.Host.Region = a.region
-    heart.Key, _ = awsgo.GetSecurityKeys()
     resp, err := heart.Request()
     if err != nil {
         log.Println("Error sending heartbeat: ", err)
@@ -287,7 +300,6 @@ func (a *ActivityContext) markCompletedRequest(result string) error {
     com.Result = result
     com.TaskToken = a.pollTask.TaskToken
     com.Host.Region = a.region
-    com.Key, _ = awsgo.GetSecurityKeys()
     _, err := com.Request()
     return err
 }
@@ -315,7 +327,6 @@ func (a *ActivityContext) markFailedRequest(reason, details string) error {
     fal.Details = details
     fal.TaskToken = a.pollTask.TaskToken
     fal.Host.Region = a.region
-    fal.Key, _ = awsgo.GetSecurityKeys()
     _, err := fal.Request()
     return err
 }
@@ -343,7 +354,6 @@ func (a *ActivityContext) markCanceledRequest(details string) error {
     canc.Details = details
     canc.TaskToken = a.pollTask.TaskToken
     canc.Host.Region = a.region
-    canc.Key, _ = awsgo.GetSecurityKeys()
     _, err := canc.Request()
     return err
 }
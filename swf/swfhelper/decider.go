@@ -32,6 +32,7 @@ package swfhelper
 
 import (
 	"code.google.com/p/go-uuid/uuid"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/fromkeith/awsgo"
@@ -313,8 +314,14 @@ func (d *Decider) newWorker() *SwfWorkflow {
 
 // starts polling for decisions, indefinitely.
 func (d *Decider) Start() error {
+	return d.StartWithContext(context.Background())
+}
+
+// starts polling for decisions until ctx is done, returning ctx.Err().
+// Workflows already handed to a handler are left to finish on their own.
+func (d *Decider) StartWithContext(ctx context.Context) error {
 	if d.Identity == "" {
-		ec2Identity, err := ec2.InstanceId()
+		ec2Identity, err := ec2.MakeSimpleRequestWithContext(ctx, "/meta-data/instance-id")
 		if err != nil {
 			return err
 		}
@@ -329,11 +336,12 @@ func (d *Decider) Start() error {
 			d.workerPool <- d.newWorker()
 		}
 	}
-	for {
-		d.startDeciding()
+	for ctx.Err() == nil {
+		d.startDeciding(ctx)
 	}
+	return ctx.Err()
 }
-func (d *Decider) startDeciding() {
+func (d *Decider) startDeciding(ctx context.Context) {
 	defer func() {
 		rec := recover()
 		if rec != nil {
@@ -341,8 +349,13 @@ func (d *Decider) startDeciding() {
 			debug.PrintStack()
 		}
 	}()
-	for {
-		worker := <-d.workerPool
+	for ctx.Err() == nil {
+		var worker *SwfWorkflow
+		select {
+		case worker = <-d.workerPool:
+		case <-ctx.Done():
+			return
+		}
 		worker.history = worker.history[0:0]
 		worker.nextActivityId = 0
 		worker.nextOnceMarkerId = 0
@@ -356,25 +369,34 @@ func (d *Decider) startDeciding() {
 		poll.TaskList = swf.TaskList{Name: d.TaskList}
 
 		poll.Host.Region = d.Region
-		poll.Key, _ = awsgo.GetSecurityKeys()
 
-		resp, err := poll.Request()
+		resp, err := poll.RequestWithContext(ctx)
 		if err != nil {
-			log.Println("Error making poll for decision request.", err)
-			time.Sleep(1 * time.Second)
 			d.workerPool <- worker
+			if ctx.Err() != nil {
+				return
+			}
+			log.Println("Error making poll for decision request.", err)
+			awsgo.SleepWithContext(ctx, 1*time.Second)
 			continue
 		}
 		if resp.TaskToken == "" {
 			d.workerPool <- worker
 			continue
 		}
-		d.handleDecisionTaskResponse(resp, worker)
+		if err := d.handleDecisionTaskResponse(ctx, resp, worker); err != nil {
+			d.workerPool <- worker
+			return
+		}
 	}
 }
 
-func (d *Decider) handleDecisionTaskResponse(resp *swf.PollForDecisionTaskResponse, worker *SwfWorkflow) {
-	worker.history = d.fillInHistory(resp, worker.history)
+func (d *Decider) handleDecisionTaskResponse(ctx context.Context, resp *swf.PollForDecisionTaskResponse, worker *SwfWorkflow) error {
+	var err error
+	worker.history, err = d.fillInHistory(ctx, resp, worker.history)
+	if err != nil {
+		return err
+	}
 
 	key := fmt.Sprintf("%s==>%s", resp.WorkflowType.Name, resp.WorkflowType.Version)
 	if h, ok := d.workflowHandlers[key]; !ok {
@@ -392,9 +414,11 @@ func (d *Decider) handleDecisionTaskResponse(resp *swf.PollForDecisionTaskRespon
 			h(worker)
 		}()
 	}
+	return nil
 }
 
-func (d *Decider) fillInHistory(lastResp *swf.PollForDecisionTaskResponse, events []swf.HistoryEvent) []swf.HistoryEvent {
+// pages through the rest of the history. Only fails if ctx is done first.
+func (d *Decider) fillInHistory(ctx context.Context, lastResp *swf.PollForDecisionTaskResponse, events []swf.HistoryEvent) ([]swf.HistoryEvent, error) {
 	for lastResp != nil {
 		events = append(events, lastResp.Events...)
 
@@ -409,23 +433,27 @@ func (d *Decider) fillInHistory(lastResp *swf.PollForDecisionTaskResponse, event
 		poll.NextPageToken = lastResp.NextPageToken
 
 		poll.Host.Region = d.Region
-		poll.Key, _ = awsgo.GetSecurityKeys()
 
 		for i := 0; ; i++ {
-			resp, err := poll.Request()
+			resp, err := poll.RequestWithContext(ctx)
 			if err != nil {
+				if ctx.Err() != nil {
+					return events, ctx.Err()
+				}
 				log.Println("Error making poll for decision request.", err)
 				if i > 10 {
 					panic("Failed too many times to try get decidion task request.")
 				}
-				time.Sleep(time.Duration(i*i) * 200 * time.Millisecond)
+				if err = awsgo.SleepWithContext(ctx, time.Duration(i*i)*200*time.Millisecond); err != nil {
+					return events, err
+				}
 				continue
 			}
 			lastResp = resp
 			break
 		}
 	}
-	return events
+	return events, nil
 }
 
 // decodes the execution workflows input as the given interface
@@ -451,7 +479,6 @@ func (w *SwfWorkflow) Decide() {
 		req.Decisions = w.decisions
 		req.TaskToken = w.taskToken
 		req.Host.Region = w.region
-		req.Key, _ = awsgo.GetSecurityKeys()
 		_, err := req.Request()
 		if err != nil {
 			if i > 10 {
@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// A key/value pair on a Span. Keys follow the OpenTelemetry conventions, eg. rpc.method
+type SpanAttribute struct {
+    Key     string
+    Value   interface{}
+}
+
+// Starts spans for requests. Adapt your tracing SDK (eg. OpenTelemetry) to this, so
+// awsgo doesn't have to depend on it.
+type Tracer interface {
+    // Starts a span, as a child of any span in ctx. Returns a ctx holding the new span,
+    // which is used for the http request.
+    StartSpan(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+type Span interface {
+    SetAttributes(attrs ...SpanAttribute)
+    AddEvent(name string, attrs ...SpanAttribute)
+    RecordError(err error)
+    End()
+}
+
+// Implemented by requests that have more to add to their span, eg. the DynamoDB table or SQS queue.
+type SpanAttributer interface {
+    SpanAttributes() []SpanAttribute
+}
+
+var (
+    tracerLock sync.RWMutex
+    defaultTracer Tracer
+)
+
+// Sets the Tracer used by requests that don't specify their own. nil turns tracing off.
+func SetDefaultTracer(tracer Tracer) {
+    tracerLock.Lock()
+    defer tracerLock.Unlock()
+    defaultTracer = tracer
+}
+
+func (op *Operation) tracer() Tracer {
+    if op.Request.Tracer != nil {
+        return op.Request.Tracer
+    }
+    tracerLock.RLock()
+    defer tracerLock.RUnlock()
+    return defaultTracer
+}
+
+// Starts the span for the request, if there is a Tracer.
+func (op *Operation) startSpan() {
+    tracer := op.tracer()
+    if tracer == nil {
+        return
+    }
+    operation := operationName(op.Request)
+    attrs := []SpanAttribute{
+        {"rpc.system", "aws-api"},
+        {"rpc.service", op.Request.Host.Service},
+        {"rpc.method", operation},
+        {"cloud.region", op.Request.Host.Region},
+    }
+    if a, ok := op.Builder.(SpanAttributer); ok {
+        attrs = append(attrs, a.SpanAttributes()...)
+    }
+    op.Context, op.span = tracer.StartSpan(op.Context, op.Request.Host.Service + "." + operation, attrs...)
+}
+
+func (op *Operation) traceRetry(failed RetryAttempt, delay time.Duration) {
+    if op.span == nil {
+        return
+    }
+    attrs := []SpanAttribute{
+        {"http.request.resend_count", failed.Attempt},
+        {"delay", delay.String()},
+    }
+    if failed.Err != nil {
+        attrs = append(attrs, SpanAttribute{"error", failed.Err.Error()})
+    } else {
+        attrs = append(attrs, SpanAttribute{"http.response.status_code", failed.StatusCode}, SpanAttribute{"aws.error_code", failed.ErrorCode})
+    }
+    op.span.AddEvent("retry", attrs...)
+}
+
+// Ends the span. Runs in the Complete stage.
+func endSpanHandler(op *Operation) {
+    if op.span == nil {
+        return
+    }
+    attrs := []SpanAttribute{
+        {"http.request.resend_count", op.Attempt - 1},
+    }
+    if op.HttpResponse != nil {
+        attrs = append(attrs, SpanAttribute{"http.response.status_code", op.HttpResponse.StatusCode})
+        if id := requestId(op.HttpResponse); id != "" {
+            attrs = append(attrs, SpanAttribute{"aws.request_id", id})
+        }
+    }
+    op.span.SetAttributes(attrs...)
+    if op.Error != nil {
+        op.span.RecordError(op.Error)
+    }
+    op.span.End()
+}
+
+// A Tracer that keeps its spans in memory. Useful in tests.
+type RecordingTracer struct {
+    lock    sync.Mutex
+    spans   []*RecordedSpan
+}
+
+// A span kept by a RecordingTracer.
+type RecordedSpan struct {
+    Name        string
+    // nil for a root span
+    Parent      *RecordedSpan
+    Attributes  map[string]interface{}
+    Events      []RecordedEvent
+    Errors      []error
+    Ended       bool
+    tracer      *RecordingTracer
+}
+
+type RecordedEvent struct {
+    Name        string
+    Attributes  map[string]interface{}
+}
+
+type recordedSpanKey struct{}
+
+// The span held in ctx by a RecordingTracer, or nil.
+func RecordedSpanFromContext(ctx context.Context) *RecordedSpan {
+    s, _ := ctx.Value(recordedSpanKey{}).(*RecordedSpan)
+    return s
+}
+
+func (t *RecordingTracer) StartSpan(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+    s := &RecordedSpan{
+        Name: name,
+        Parent: RecordedSpanFromContext(ctx),
+        Attributes: make(map[string]interface{}),
+        tracer: t,
+    }
+    s.SetAttributes(attrs...)
+    t.lock.Lock()
+    t.spans = append(t.spans, s)
+    t.lock.Unlock()
+    return context.WithValue(ctx, recordedSpanKey{}, s), s
+}
+
+// Every span started so far, in order.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+    t.lock.Lock()
+    defer t.lock.Unlock()
+    return append([]*RecordedSpan(nil), t.spans...)
+}
+
+func (s *RecordedSpan) SetAttributes(attrs ...SpanAttribute) {
+    s.tracer.lock.Lock()
+    defer s.tracer.lock.Unlock()
+    for _, a := range attrs {
+        s.Attributes[a.Key] = a.Value
+    }
+}
+
+func (s *RecordedSpan) AddEvent(name string, attrs ...SpanAttribute) {
+    e := RecordedEvent{Name: name, Attributes: make(map[string]interface{})}
+    for _, a := range attrs {
+        e.Attributes[a.Key] = a.Value
+    }
+    s.tracer.lock.Lock()
+    defer s.tracer.lock.Unlock()
+    s.Events = append(s.Events, e)
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+    s.tracer.lock.Lock()
+    defer s.tracer.lock.Unlock()
+    s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) End() {
+    s.tracer.lock.Lock()
+    defer s.tracer.lock.Unlock()
+    s.Ended = true
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2014, fromkeith
+ * All rights reserved.
+ * 
+ * Redistribution and use in source and binary forms, with or without modification,
+ * are permitted provided that the following conditions are met:
+ * 
+ * * Redistributions of source code must retain the above copyright notice, this
+ *   list of conditions and the following disclaimer.
+ * 
+ * * Redistributions in binary form must reproduce the above copyright notice, this
+ *   list of conditions and the following disclaimer in the documentation and/or
+ *   other materials provided with the distribution.
+ * 
+ * * Neither the name of the fromkeith nor the names of its
+ *   contributors may be used to endorse or promote products derived from
+ *   this software without specific prior written permission.
+ * 
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ * ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+ * WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+ * DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ * ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+ * (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+ * LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+ * ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+ * (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+ * SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ */
+
+package awsgo
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+type tableEchoRequest struct {
+    echoRequest
+}
+
+func (r tableEchoRequest) SpanAttributes() []SpanAttribute {
+    return []SpanAttribute{{"aws.dynamodb.table_names", []string{"asd"}}}
+}
+
+func Test_TracerSpanIsChildOfContext(t *testing.T) {
+    calls := 0
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        calls ++
+        if calls == 1 {
+            http.Error(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException"}`, 400)
+            return
+        }
+        w.Header().Set("X-Amzn-Requestid", "req-1")
+        fmt.Fprint(w, "ok")
+    }))
+    defer ts.Close()
+
+    tracer := new(RecordingTracer)
+    ctx, parent := tracer.StartSpan(context.Background(), "parent")
+
+    req := &tableEchoRequest{*newEchoRequest(ts.URL, nil)}
+    req.Headers["X-Amz-Target"] = "DynamoDB_20120810.GetItem"
+    req.RetryPolicy = DefaultRetryPolicy{ThrottleBaseDelay: 1}
+    req.Tracer = tracer
+    request, err := NewAwsRequest(req, map[string]string{})
+    if err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+    request.RequestSigningType = RequestSigningType_AWS4
+    if _, err := request.DoAndDemarshallWithContext(ctx, req); err != nil {
+        t.Fatalf("Error should be nil. Got: %v", err)
+    }
+
+    spans := tracer.Spans()
+    if len(spans) != 2 {
+        t.Fatalf("Expected 2 spans. Got %d", len(spans))
+    }
+    s := spans[1]
+    if s.Name != "dynamodb.GetItem" || s.Parent != parent || !s.Ended || len(s.Errors) != 0 {
+        t.Fatalf("Unexpected span: %+v", s)
+    }
+    if s.Attributes["rpc.method"] != "GetItem" || s.Attributes["cloud.region"] != "us-east-1" {
+        t.Fatalf("Unexpected attributes: %v", s.Attributes)
+    }
+    if s.Attributes["http.response.status_code"] != 200 || s.Attributes["aws.request_id"] != "req-1" || s.Attributes["http.request.resend_count"] != 1 {
+        t.Fatalf("Unexpected response attributes: %v", s.Attributes)
+    }
+    if names, ok := s.Attributes["aws.dynamodb.table_names"].([]string); !ok || len(names) != 1 || names[0] != "asd" {
+        t.Fatalf("Expected the table name. Got: %v", s.Attributes)
+    }
+    if len(s.Events) != 1 || s.Events[0].Name != "retry" || s.Events[0].Attributes["aws.error_code"] != "ProvisionedThroughputExceededException" {
+        t.Fatalf("Expected a retry event. Got: %+v", s.Events)
+    }
+}
+
+func Test_TracerRecordsErrors(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+        http.Error(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException"}`, 400)
+    }))
+    defer ts.Close()
+
+    tracer := new(RecordingTracer)
+    req := newEchoRequest(ts.URL, nil)
+    req.Tracer = tracer
+    if _, err := doEcho(t, req); err == nil {
+        t.Fatalf("Expected an error")
+    }
+    spans := tracer.Spans()
+    if len(spans) != 1 || spans[0].Parent != nil || !spans[0].Ended || len(spans[0].Errors) != 1 {
+        t.Fatalf("Expected one ended span with an error. Got: %+v", spans)
+    }
+    if spans[0].Attributes["http.response.status_code"] != 400 {
+        t.Fatalf("Unexpected attributes: %v", spans[0].Attributes)
+    }
+}